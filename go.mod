@@ -7,6 +7,7 @@ require (
 	github.com/gdamore/tcell/v2 v2.8.1
 	github.com/redis/go-redis/v9 v9.10.0
 	github.com/rivo/tview v0.0.0-20250501113434-0c592cd31026
+	go.etcd.io/bbolt v1.4.3
 )
 
 require (
@@ -0,0 +1,197 @@
+package aof
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"redscout/lib/rdb"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Command is a write command replayed from an append-only file.
+type Command struct {
+	DB   int
+	Args []string
+	// From the most recent #TS annotation, zero when the file has none
+	Time time.Time
+}
+
+// ErrTruncated is returned when the last command of a file is cut short, which Redis itself
+// tolerates with aof-load-truncated.
+var ErrTruncated = errors.New("aof: truncated command at end of file")
+
+// Files resolves an AOF path into the files holding traffic, in replay order. The path may be
+// a single AOF file, a Redis 7 manifest, or the appendonlydir containing one. Base files are
+// skipped as they are a snapshot of the dataset rather than traffic.
+func Files(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		manifests, err := filepath.Glob(filepath.Join(path, "*.manifest"))
+		if err != nil {
+			return nil, err
+		}
+		if len(manifests) != 1 {
+			return nil, fmt.Errorf("aof: expected one manifest in %s, found %d", path, len(manifests))
+		}
+		path = manifests[0]
+	}
+
+	if !strings.HasSuffix(path, ".manifest") {
+		return []string{path}, nil
+	}
+	return manifestFiles(path)
+}
+
+type manifestEntry struct {
+	name string
+	seq  int64
+}
+
+// manifestFiles lists the incremental files of a manifest by sequence number. Each line looks
+// like: file appendonly.aof.2.incr.aof seq 2 type i
+func manifestFiles(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var incr []manifestEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || len(fields)%2 != 0 {
+			continue
+		}
+
+		attrs := make(map[string]string)
+		for i := 0; i < len(fields); i += 2 {
+			attrs[fields[i]] = fields[i+1]
+		}
+		if attrs["type"] != "i" {
+			continue
+		}
+		seq, err := strconv.ParseInt(attrs["seq"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("aof: invalid manifest line %q", line)
+		}
+		incr = append(incr, manifestEntry{name: attrs["file"], seq: seq})
+	}
+
+	sort.Slice(incr, func(i, j int) bool {
+		return incr[i].seq < incr[j].seq
+	})
+
+	dir := filepath.Dir(path)
+	files := make([]string, 0, len(incr))
+	for _, e := range incr {
+		files = append(files, filepath.Join(dir, e.name))
+	}
+	return files, nil
+}
+
+// ReadFile replays the commands of a single AOF file, skipping an RDB preamble if present.
+func ReadFile(path string, fn func(Command) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return Read(f, fn)
+}
+
+// Read replays the commands of an AOF stream, calling fn for every command other than SELECT.
+func Read(r io.Reader, fn func(Command) error) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+
+	if magic, err := br.Peek(5); err == nil && string(magic) == "REDIS" {
+		if err := rdb.NewParser(br).Parse(func(rdb.Entry) error { return nil }); err != nil {
+			return fmt.Errorf("aof: reading rdb preamble: %w", err)
+		}
+	}
+
+	cmd := Command{}
+	for {
+		line, err := br.ReadBytes('\n')
+		if err == io.EOF && len(line) == 0 {
+			return nil
+		}
+		if err != nil {
+			return ErrTruncated
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			continue
+		}
+
+		switch line[0] {
+		case '#':
+			// Annotation, e.g. #TS:1700000000
+			if ts, ok := bytes.CutPrefix(line, []byte("#TS:")); ok {
+				if sec, err := strconv.ParseInt(string(ts), 10, 64); err == nil {
+					cmd.Time = time.Unix(sec, 0)
+				}
+			}
+		case '*':
+			n, err := strconv.Atoi(string(line[1:]))
+			if err != nil {
+				return fmt.Errorf("aof: invalid array header %q", line)
+			}
+			args, err := readArgs(br, n)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				continue
+			}
+
+			if strings.EqualFold(args[0], "select") && len(args) > 1 {
+				if db, err := strconv.Atoi(args[1]); err == nil {
+					cmd.DB = db
+				}
+				continue
+			}
+			cmd.Args = args
+			if err := fn(cmd); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("aof: unexpected line %q", line)
+		}
+	}
+}
+
+func readArgs(br *bufio.Reader, n int) ([]string, error) {
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		header, err := br.ReadBytes('\n')
+		if err != nil {
+			return nil, ErrTruncated
+		}
+		header = bytes.TrimRight(header, "\r\n")
+		if len(header) == 0 || header[0] != '$' {
+			return nil, fmt.Errorf("aof: invalid bulk header %q", header)
+		}
+		size, err := strconv.Atoi(string(header[1:]))
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("aof: invalid bulk header %q", header)
+		}
+
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, ErrTruncated
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
@@ -3,6 +3,8 @@ package lib
 import (
 	"flag"
 	"fmt"
+	"os"
+	"redscout/lib/history"
 	"redscout/models"
 	"regexp"
 	"strings"
@@ -19,6 +21,7 @@ func ParseFlags() models.Config {
 	flag.StringVar(&config.RedisUser, "u", config.RedisUser, "Redis username")
 	flag.StringVar(&config.RedisPassword, "a", config.RedisPassword, "Redis password")
 	flag.IntVar(&config.RedisDB, "n", config.RedisDB, "Redis database number")
+	flag.BoolVar(&config.AllDBs, "all-dbs", config.AllDBs, "Analyze every non-empty Redis database instead of only -n")
 
 	flag.BoolVar(&config.UseTLS, "tls", config.UseTLS, "Use TLS for Redis connection")
 
@@ -28,24 +31,67 @@ func ParseFlags() models.Config {
 	var monitorDuration int
 	flag.IntVar(&monitorDuration, "monitor-duration", int(config.MonitorDuration.Seconds()), "Duration in seconds to monitor Redis operations")
 
+	flag.StringVar(&config.OpsSource, "ops-source", config.OpsSource, "Live ops source: monitor, or keyspace for write-only keyspace notifications (needs --read-only=false)")
+	flag.BoolVar(&config.TrackRemovals, "track-removals", config.TrackRemovals, "Track keys expired or evicted while MONITOR runs, enabling their keyspace notifications meanwhile (needs --read-only=false)")
+
+	flag.BoolVar(&config.Live, "live", config.Live, "Keep streaming ops and show rolling 10s/1m/5m rates per namespace")
+	flag.IntVar(&config.DutyCycle, "duty-cycle", config.DutyCycle, "Percent of every monitor-duration cycle spent streaming in live mode")
+
 	var refreshInterval int
 	flag.IntVar(&refreshInterval, "refresh-interval", int(config.RefreshInterval.Seconds()), "Interval in seconds between Redis info refreshes")
 
+	var slowLogInterval int
+	flag.IntVar(&slowLogInterval, "slowlog-interval", int(config.SlowLogInterval.Seconds()), "Interval in seconds between slow log polls, 0 to fetch it once")
+	flag.IntVar(&config.SlowLogMax, "slowlog-max", config.SlowLogMax, "Slow log entries kept for the session, and in slowlog-file")
+	flag.StringVar(&config.SlowLogFile, "slowlog-file", config.SlowLogFile, "File every new slow log entry is appended to as JSON, empty to keep them in memory only")
+
+	var coldDays int
+	flag.IntVar(&coldDays, "cold-days", int(config.ColdAfter.Hours()/24), "Days without access after which a key's memory counts as cold")
+
 	flag.StringVar(&config.Delimiter, "delimiter", config.Delimiter, "Delimiter for separating redis keys")
 	flag.StringVar(&config.LogsDir, "logs-dir", config.LogsDir, "Directory to store logs")
 
+	flag.StringVar(&config.RDBFile, "rdb", config.RDBFile, "Analyze an RDB dump file offline instead of scanning a live server")
+	flag.StringVar(&config.AOFPath, "aof", config.AOFPath, "Replay an AOF file, manifest or appendonlydir offline for historical write ops")
+	flag.StringVar(&config.MonitorFile, "monitor-file", config.MonitorFile, "Replay captured MONITOR output from a file, or - for stdin, instead of running MONITOR")
+	flag.BoolVar(&config.NoConnect, "offline", config.NoConnect, "Never connect to Redis, analyze only the given files")
+
+	flag.BoolVar(&config.LoadGuard.Enabled, "load-guard", config.LoadGuard.Enabled, "Pause scanning and monitoring while Redis is under stress")
+	var guardRTT, guardEventLatency, guardCPU int
+	flag.IntVar(&guardRTT, "guard-rtt", int(config.LoadGuard.MaxRTT.Milliseconds()), "PING round trip in ms above which the load guard pauses, 0 to ignore")
+	flag.IntVar(&guardEventLatency, "guard-event-latency", int(config.LoadGuard.MaxEventLatency.Milliseconds()), "LATENCY LATEST event latency in ms above which the load guard pauses, 0 to ignore")
+	flag.Int64Var(&config.LoadGuard.MaxOpsPerSec, "guard-ops", config.LoadGuard.MaxOpsPerSec, "instantaneous_ops_per_sec above which the load guard pauses, 0 to ignore")
+	flag.IntVar(&config.LoadGuard.MaxBlocked, "guard-blocked", config.LoadGuard.MaxBlocked, "blocked_clients above which the load guard pauses, 0 to ignore")
+	flag.IntVar(&guardCPU, "guard-cpu", int(config.LoadGuard.MaxCPU*100), "Server CPU in percent of a core above which the load guard pauses, 0 to ignore")
+
+	var latencyThreshold int
+	flag.IntVar(&latencyThreshold, "latency-threshold", int(config.LatencyThreshold.Milliseconds()), "latency-monitor-threshold in ms the Latency tab offers to set while the monitor is disabled, with --read-only=false")
+
+	flag.BoolVar(&config.ReadOnly, "read-only", config.ReadOnly, "Refuse any command that could change data or config at the client, --read-only=false to allow writes")
+	flag.BoolVar(&config.AllowWrites, "allow-writes", config.AllowWrites, "Allow expiring and unlinking keys and namespaces from the UI, after typed confirmation")
+	flag.StringVar(&config.AuditLog, "audit-log", config.AuditLog, "File every key touched by a write action is logged to")
+
+	flag.StringVar(&config.HistoryDB, "history-db", config.HistoryDB, "Database every run's results are kept in for the history command, empty to keep nothing")
+
 	idRegexInput := ""
 	flag.StringVar(&idRegexInput, "id-regex", "", "space seperated list of regex to infer IDs from keys")
 
 	flag.Parse()
 
+	config.LoadGuard.MaxRTT = time.Duration(guardRTT) * time.Millisecond
+	config.LoadGuard.MaxEventLatency = time.Duration(guardEventLatency) * time.Millisecond
+	config.LoadGuard.MaxCPU = float64(guardCPU) / 100
+	config.SlowLogInterval = time.Duration(slowLogInterval) * time.Second
+	config.LatencyThreshold = time.Duration(latencyThreshold) * time.Millisecond
+
 	// Validate flag values
-	if err := validateFlags(&config, monitorDuration, refreshInterval); err != nil {
+	if err := validateFlags(&config, monitorDuration, refreshInterval, coldDays); err != nil {
 		panic(err)
 	}
 
 	config.MonitorDuration = time.Duration(monitorDuration) * time.Second
 	config.RefreshInterval = time.Duration(refreshInterval) * time.Second
+	config.ColdAfter = time.Duration(coldDays) * 24 * time.Hour
 
 	for _, pattern := range strings.Split(idRegexInput, " ") {
 		pattern = strings.TrimSpace(pattern)
@@ -65,7 +111,7 @@ func ParseFlags() models.Config {
 }
 
 // validateFlags validates the parsed flag values
-func validateFlags(config *models.Config, monitorDuration, refreshInterval int) error {
+func validateFlags(config *models.Config, monitorDuration, refreshInterval, coldDays int) error {
 	// Validate scan-size
 	if config.KeysScanSize <= 0 {
 		return fmt.Errorf("scan-size must be positive, got %d", config.KeysScanSize)
@@ -81,6 +127,65 @@ func validateFlags(config *models.Config, monitorDuration, refreshInterval int)
 		return fmt.Errorf("refresh-interval must be positive, got %d seconds", refreshInterval)
 	}
 
+	// Validate slow log polling
+	if config.SlowLogInterval < 0 {
+		return fmt.Errorf("slowlog-interval must be non-negative, got %v", config.SlowLogInterval)
+	}
+	if config.SlowLogMax <= 0 {
+		return fmt.Errorf("slowlog-max must be positive, got %d", config.SlowLogMax)
+	}
+
+	// Validate cold-days
+	if coldDays <= 0 {
+		return fmt.Errorf("cold-days must be positive, got %d", coldDays)
+	}
+
+	// Validate ops source
+	if config.OpsSource != models.OpsSourceMonitor && config.OpsSource != models.OpsSourceKeyspace {
+		return fmt.Errorf("ops-source must be %s or %s, got %q", models.OpsSourceMonitor, models.OpsSourceKeyspace, config.OpsSource)
+	}
+
+	// Keyspace notifications are enabled with CONFIG SET, which read-only mode refuses
+	if config.OpsSource == models.OpsSourceKeyspace && config.ReadOnly {
+		return fmt.Errorf("ops-source keyspace changes notify-keyspace-events, it needs --read-only=false")
+	}
+	if config.TrackRemovals && config.ReadOnly {
+		return fmt.Errorf("track-removals changes notify-keyspace-events, it needs --read-only=false")
+	}
+
+	// Validate live mode
+	if config.DutyCycle < 1 || config.DutyCycle > 100 {
+		return fmt.Errorf("duty-cycle must be between 1 and 100, got %d", config.DutyCycle)
+	}
+	if config.Live && config.Offline() {
+		return fmt.Errorf("live mode needs a Redis connection, it can't be used with offline sources")
+	}
+	if config.Live && config.MonitorFile != "" {
+		return fmt.Errorf("live mode streams ops itself, it can't be used with --monitor-file")
+	}
+
+	// Validate load guard thresholds
+	guard := config.LoadGuard
+	if guard.MaxRTT < 0 || guard.MaxEventLatency < 0 || guard.MaxOpsPerSec < 0 || guard.MaxBlocked < 0 || guard.MaxCPU < 0 {
+		return fmt.Errorf("load guard thresholds must be non-negative")
+	}
+
+	// Validate latency monitor threshold
+	if config.LatencyThreshold <= 0 {
+		return fmt.Errorf("latency-threshold must be positive, got %v", config.LatencyThreshold)
+	}
+
+	// Validate write actions
+	if config.AllowWrites && config.Offline() {
+		return fmt.Errorf("allow-writes needs a Redis connection, it can't be used with offline sources")
+	}
+	if config.AllowWrites && config.ReadOnly {
+		return fmt.Errorf("allow-writes needs --read-only=false")
+	}
+	if config.AllowWrites && config.AuditLog == "" {
+		return fmt.Errorf("allow-writes needs an audit-log")
+	}
+
 	// Validate port number
 	if config.RedisPort < 1 || config.RedisPort > 65535 {
 		return fmt.Errorf("port must be between 1 and 65535, got %d", config.RedisPort)
@@ -91,6 +196,26 @@ func validateFlags(config *models.Config, monitorDuration, refreshInterval int)
 		return fmt.Errorf("database number must be non-negative, got %d", config.RedisDB)
 	}
 
+	// Validate offline sources exist
+	if config.RDBFile != "" {
+		if _, err := os.Stat(config.RDBFile); err != nil {
+			return fmt.Errorf("cannot read rdb file: %w", err)
+		}
+	}
+	if config.AOFPath != "" {
+		if _, err := os.Stat(config.AOFPath); err != nil {
+			return fmt.Errorf("cannot read aof path: %w", err)
+		}
+	}
+	if config.NoConnect && config.RDBFile == "" && config.AOFPath == "" && config.MonitorFile == "" {
+		return fmt.Errorf("offline needs at least one of --rdb, --aof or --monitor-file")
+	}
+	if config.MonitorFile != "" && config.MonitorFile != "-" {
+		if _, err := os.Stat(config.MonitorFile); err != nil {
+			return fmt.Errorf("cannot read monitor file: %w", err)
+		}
+	}
+
 	// Validate delimiter is not empty
 	if config.Delimiter == "" {
 		return fmt.Errorf("delimiter cannot be empty")
@@ -98,3 +223,81 @@ func validateFlags(config *models.Config, monitorDuration, refreshInterval int)
 
 	return nil
 }
+
+// RunHistory runs the history subcommand: the namespaces that grew the most over a date range,
+// the metrics of a namespace in every recorded run, or the anomalies each run found.
+func RunHistory(args []string) error {
+	config := models.DefaultConfig()
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+
+	historyDB := flags.String("history-db", config.HistoryDB, "History database to query")
+	source := flags.String("source", "", "Database of a server (host:port/db) or dump (rdb:/path/dump.rdb/db) to query, needed when several were recorded")
+	namespace := flags.String("namespace", "", "Show the metrics of this top level namespace in each run instead of the top growers")
+	fromInput := flags.String("from", "", "First day to include, as YYYY-MM-DD")
+	toInput := flags.String("to", "", "Last day to include, as YYYY-MM-DD")
+	top := flags.Int("top", 10, "Number of top growers to show")
+	anomalies := flags.Bool("anomalies", false, "Show the namespace metrics each run found far from their baseline")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var from, to time.Time
+	var err error
+	if *fromInput != "" {
+		if from, err = time.ParseInLocation(time.DateOnly, *fromInput, time.Local); err != nil {
+			return fmt.Errorf("invalid from date: %w", err)
+		}
+	}
+	if *toInput != "" {
+		if to, err = time.ParseInLocation(time.DateOnly, *toInput, time.Local); err != nil {
+			return fmt.Errorf("invalid to date: %w", err)
+		}
+		// Include the whole last day
+		to = to.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if *top <= 0 {
+		return fmt.Errorf("top must be positive, got %d", *top)
+	}
+	if *historyDB == "" {
+		return fmt.Errorf("history-db cannot be empty")
+	}
+
+	store, err := history.Open(*historyDB)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if *source == "" {
+		sources, err := store.Sources()
+		if err != nil {
+			return err
+		}
+		switch len(sources) {
+		case 0:
+			return fmt.Errorf("no runs recorded in %s", *historyDB)
+		case 1:
+			*source = sources[0]
+		default:
+			return fmt.Errorf("several sources recorded, pick one with --source: %s", strings.Join(sources, ", "))
+		}
+	}
+
+	runs, err := store.Runs(*source, from, to)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return fmt.Errorf("no runs of %s in the given range", *source)
+	}
+
+	fmt.Printf("%d runs of %s\n\n", len(runs), *source)
+	if *anomalies {
+		return history.PrintAnomalies(os.Stdout, runs)
+	}
+	if *namespace != "" {
+		return history.PrintTrend(os.Stdout, models.NamespaceTrend(runs, *namespace))
+	}
+	return history.PrintGrowers(os.Stdout, models.TopGrowers(runs, *top))
+}
@@ -3,4 +3,7 @@ package lib
 const (
 	ScanBatchSize       = 2000
 	MemoryPipeBatchSize = 250
+
+	// Keys with up to this many times a compact encoding threshold count as just above it
+	NearThresholdFactor = 2
 )
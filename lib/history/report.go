@@ -0,0 +1,78 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"redscout/lib/utils"
+	"redscout/models"
+	"text/tabwriter"
+)
+
+const dateFormat = "2006-01-02 15:04"
+
+// PrintGrowers writes the top growers as a table.
+func PrintGrowers(w io.Writer, growers models.NamespaceGrowthList) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Namespace\tFrom\tTo\t~Keys\t~Memory\tGrowth")
+	for _, g := range growers {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s → %s\t%s → %s\t%s\n",
+			g.Namespace,
+			g.From.Format(dateFormat),
+			g.To.Format(dateFormat),
+			utils.FormatNumber(float64(g.FromKeys)),
+			utils.FormatNumber(float64(g.ToKeys)),
+			utils.FormatBytes(g.FromMemory),
+			utils.FormatBytes(g.ToMemory),
+			utils.FormatSignedBytes(g.MemoryGrowth()),
+		)
+	}
+	return tw.Flush()
+}
+
+// PrintTrend writes a namespace's metrics in each run as a table.
+func PrintTrend(w io.Writer, trend []models.MetricSample) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Time\t~Keys\t~Memory\tOps/s")
+	for _, sample := range trend {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.1f\n",
+			sample.Time.Format(dateFormat),
+			utils.FormatNumber(float64(sample.EstKeys)),
+			utils.FormatBytes(sample.EstMemory),
+			sample.Ops,
+		)
+	}
+	return tw.Flush()
+}
+
+// PrintAnomalies writes the anomalies found in each run as a table.
+func PrintAnomalies(w io.Writer, runs []models.HistoryRun) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Time\tNamespace\tMetric\tValue\tBaseline\tScore")
+	for _, run := range runs {
+		for _, a := range run.Anomalies {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%+.1fσ\n",
+				run.Time.Format(dateFormat),
+				a.Namespace,
+				a.Metric,
+				FormatMetricValue(a.Metric, a.Value),
+				FormatMetricValue(a.Metric, a.Mean),
+				a.Score,
+			)
+		}
+	}
+	return tw.Flush()
+}
+
+// FormatMetricValue formats the value of one of the baseline metrics.
+func FormatMetricValue(metric string, v float64) string {
+	switch metric {
+	case models.MetricMemory:
+		return utils.FormatBytes(int64(v))
+	case models.MetricKeys:
+		return utils.FormatNumber(v)
+	case models.MetricTTLPercent:
+		return fmt.Sprintf("%.1f%%", v)
+	default:
+		return fmt.Sprintf("%.1f", v)
+	}
+}
@@ -0,0 +1,151 @@
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"redscout/models"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Runs are kept in a bucket per source, keyed by their big-endian UnixNano time so that a
+// cursor walks them oldest first. Baselines are kept per source.
+var (
+	runsBucket      = []byte("runs")
+	baselinesBucket = []byte("baselines")
+)
+
+// Store is the on-disk history of runs. The file is locked while open, so it is opened only
+// for as long as a record or query takes.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the history database at path, creating it and its directory if needed.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func runKey(at time.Time) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(at.UnixNano()))
+	return key
+}
+
+// Record adds a run under its source.
+func (s *Store) Record(run models.HistoryRun) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return fmt.Errorf("failed to encode run: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		runs, err := tx.CreateBucketIfNotExists(runsBucket)
+		if err != nil {
+			return err
+		}
+		source, err := runs.CreateBucketIfNotExists([]byte(run.Source))
+		if err != nil {
+			return err
+		}
+		return source.Put(runKey(run.Time), data)
+	})
+}
+
+// Runs returns the runs of a source between from and to inclusive, oldest first. A zero from
+// or to leaves that end open.
+func (s *Store) Runs(source string, from, to time.Time) ([]models.HistoryRun, error) {
+	var runs []models.HistoryRun
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(runsBucket)
+		if bucket != nil {
+			bucket = bucket.Bucket([]byte(source))
+		}
+		if bucket == nil {
+			return nil
+		}
+
+		c := bucket.Cursor()
+		k, v := c.First()
+		if !from.IsZero() {
+			k, v = c.Seek(runKey(from))
+		}
+		for ; k != nil; k, v = c.Next() {
+			var run models.HistoryRun
+			if err := json.Unmarshal(v, &run); err != nil {
+				return fmt.Errorf("failed to decode run: %w", err)
+			}
+			if !to.IsZero() && run.Time.After(to) {
+				break
+			}
+			runs = append(runs, run)
+		}
+		return nil
+	})
+	return runs, err
+}
+
+// Sources lists the sources with recorded runs.
+func (s *Store) Sources() ([]string, error) {
+	var sources []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(runsBucket)
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEachBucket(func(name []byte) error {
+			sources = append(sources, string(name))
+			return nil
+		})
+	})
+	return sources, err
+}
+
+// Baselines returns the namespace baselines of a source, empty when none were saved.
+func (s *Store) Baselines(source string) (models.Baselines, error) {
+	baselines := make(models.Baselines)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(baselinesBucket)
+		if bucket == nil {
+			return nil
+		}
+		data := bucket.Get([]byte(source))
+		if data == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, &baselines); err != nil {
+			return fmt.Errorf("failed to decode baselines: %w", err)
+		}
+		return nil
+	})
+	return baselines, err
+}
+
+// SaveBaselines replaces the namespace baselines of a source.
+func (s *Store) SaveBaselines(source string, baselines models.Baselines) error {
+	data, err := json.Marshal(baselines)
+	if err != nil {
+		return fmt.Errorf("failed to encode baselines: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(baselinesBucket)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(source), data)
+	})
+}
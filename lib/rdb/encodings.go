@@ -0,0 +1,235 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// lzfDecompress expands an LZF compressed string of known decompressed length.
+func lzfDecompress(in []byte, outLen int) ([]byte, error) {
+	out := make([]byte, 0, outLen)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+
+		// Literal run of ctrl+1 bytes
+		if ctrl < 32 {
+			n := ctrl + 1
+			if i+n > len(in) {
+				return nil, fmt.Errorf("lzf: literal run out of bounds")
+			}
+			out = append(out, in[i:i+n]...)
+			i += n
+			continue
+		}
+
+		// Back reference
+		length := ctrl >> 5
+		if length == 7 {
+			if i >= len(in) {
+				return nil, fmt.Errorf("lzf: truncated back reference")
+			}
+			length += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, fmt.Errorf("lzf: truncated back reference")
+		}
+		ref := len(out) - ((ctrl & 0x1f) << 8) - int(in[i]) - 1
+		i++
+		if ref < 0 {
+			return nil, fmt.Errorf("lzf: back reference out of bounds")
+		}
+		for j := 0; j < length+2; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+
+	if len(out) != outLen {
+		return nil, fmt.Errorf("lzf: decompressed %d bytes, expected %d", len(out), outLen)
+	}
+	return out, nil
+}
+
+// listpackLen counts the entries of a listpack, walking it when the header count saturates.
+func listpackLen(lp []byte) (int64, error) {
+	if len(lp) < 7 {
+		return 0, fmt.Errorf("listpack: too short")
+	}
+	if n := binary.LittleEndian.Uint16(lp[4:6]); n != 0xffff {
+		return int64(n), nil
+	}
+
+	var count int64
+	for pos := 6; ; count++ {
+		if pos >= len(lp) {
+			return 0, fmt.Errorf("listpack: missing terminator")
+		}
+		b := lp[pos]
+		if b == 0xff {
+			return count, nil
+		}
+
+		var size int
+		switch {
+		case b&0x80 == 0:
+			size = 1
+		case b&0xc0 == 0x80:
+			size = 1 + int(b&0x3f)
+		case b&0xe0 == 0xc0:
+			size = 2
+		case b&0xf0 == 0xe0:
+			if pos+1 >= len(lp) {
+				return 0, fmt.Errorf("listpack: truncated entry")
+			}
+			size = 2 + (int(b&0x0f)<<8 | int(lp[pos+1]))
+		case b == 0xf0:
+			if pos+5 > len(lp) {
+				return 0, fmt.Errorf("listpack: truncated entry")
+			}
+			size = 5 + int(binary.LittleEndian.Uint32(lp[pos+1:pos+5]))
+		case b == 0xf1:
+			size = 3
+		case b == 0xf2:
+			size = 4
+		case b == 0xf3:
+			size = 5
+		case b == 0xf4:
+			size = 9
+		default:
+			return 0, fmt.Errorf("listpack: invalid encoding 0x%02x", b)
+		}
+		pos += size + listpackBacklenSize(size)
+	}
+}
+
+// listpackBacklenSize is the size of the back length closing an entry of size bytes, with the
+// bounds of lpEncodeBacklen in Redis.
+func listpackBacklenSize(size int) int {
+	switch {
+	case size <= 127:
+		return 1
+	case size < 16383:
+		return 2
+	case size < 2097151:
+		return 3
+	case size < 268435455:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// ziplistLen counts the entries of a ziplist, walking it when the header count saturates.
+func ziplistLen(zl []byte) (int64, error) {
+	if len(zl) < 11 {
+		return 0, fmt.Errorf("ziplist: too short")
+	}
+	if n := binary.LittleEndian.Uint16(zl[8:10]); n != 0xffff {
+		return int64(n), nil
+	}
+
+	var count int64
+	for pos := 10; ; count++ {
+		if pos >= len(zl) {
+			return 0, fmt.Errorf("ziplist: missing terminator")
+		}
+		if zl[pos] == 0xff {
+			return count, nil
+		}
+
+		// Previous entry length
+		if zl[pos] == 0xfe {
+			pos += 5
+		} else {
+			pos++
+		}
+		if pos >= len(zl) {
+			return 0, fmt.Errorf("ziplist: truncated entry")
+		}
+
+		b := zl[pos]
+		switch {
+		case b>>6 == 0:
+			pos += 1 + int(b&0x3f)
+		case b>>6 == 1:
+			if pos+1 >= len(zl) {
+				return 0, fmt.Errorf("ziplist: truncated entry")
+			}
+			pos += 2 + (int(b&0x3f)<<8 | int(zl[pos+1]))
+		case b>>6 == 2:
+			if pos+5 > len(zl) {
+				return 0, fmt.Errorf("ziplist: truncated entry")
+			}
+			pos += 5 + int(binary.BigEndian.Uint32(zl[pos+1:pos+5]))
+		case b == 0xc0:
+			pos += 3
+		case b == 0xd0:
+			pos += 5
+		case b == 0xe0:
+			pos += 9
+		case b == 0xf0:
+			pos += 4
+		case b == 0xfe:
+			pos += 2
+		case b >= 0xf1 && b <= 0xfd:
+			pos++
+		default:
+			return 0, fmt.Errorf("ziplist: invalid encoding 0x%02x", b)
+		}
+	}
+}
+
+// intsetLen reads the element count from an intset header.
+func intsetLen(is []byte) (int64, error) {
+	if len(is) < 8 {
+		return 0, fmt.Errorf("intset: too short")
+	}
+	return int64(binary.LittleEndian.Uint32(is[4:8])), nil
+}
+
+// zipmapLen counts the field-value pairs of a legacy zipmap.
+func zipmapLen(zm []byte) (int64, error) {
+	if len(zm) < 2 {
+		return 0, fmt.Errorf("zipmap: too short")
+	}
+	if zm[0] < 254 {
+		return int64(zm[0]), nil
+	}
+
+	readLen := func(pos int) (int, int, error) {
+		if pos >= len(zm) {
+			return 0, 0, fmt.Errorf("zipmap: truncated entry")
+		}
+		if zm[pos] < 254 {
+			return int(zm[pos]), pos + 1, nil
+		}
+		if pos+5 > len(zm) {
+			return 0, 0, fmt.Errorf("zipmap: truncated entry")
+		}
+		return int(binary.LittleEndian.Uint32(zm[pos+1 : pos+5])), pos + 5, nil
+	}
+
+	var count int64
+	for pos := 1; ; count++ {
+		if pos >= len(zm) {
+			return 0, fmt.Errorf("zipmap: missing terminator")
+		}
+		if zm[pos] == 0xff {
+			return count, nil
+		}
+		keyLen, next, err := readLen(pos)
+		if err != nil {
+			return 0, err
+		}
+		valLen, next, err := readLen(next + keyLen)
+		if err != nil {
+			return 0, err
+		}
+		if next >= len(zm) {
+			return 0, fmt.Errorf("zipmap: truncated entry")
+		}
+		free := int(zm[next])
+		pos = next + 1 + valLen + free
+	}
+}
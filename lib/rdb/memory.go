@@ -0,0 +1,73 @@
+package rdb
+
+import "math/bits"
+
+// Approximate sizes of the Redis structures behind each encoding, on a 64-bit build
+const (
+	robjSize          = 16
+	dictEntrySize     = 24
+	dictSize          = 56
+	listNodeSize      = 24
+	quicklistSize     = 40
+	quicklistNodeSize = 32
+	skiplistNodeSize  = 40
+	streamSize        = 80
+	streamNodeSize    = 48
+	streamGroupSize   = 104
+	streamConsumer    = 64
+	streamNackSize    = 48
+	embstrMaxLen      = 44
+)
+
+// allocSize rounds a request up to its jemalloc size class.
+func allocSize(n int64) int64 {
+	if n <= 8 {
+		return 8
+	}
+	if n <= 128 {
+		return (n + 15) &^ 15
+	}
+	step := int64(1) << (bits.Len64(uint64(n-1)) - 3)
+	return (n + step - 1) / step * step
+}
+
+func sdsSize(n int) int64 {
+	header := 17
+	switch {
+	case n < 1<<8:
+		header = 3
+	case n < 1<<16:
+		header = 5
+	case n < 1<<32:
+		header = 9
+	}
+	return allocSize(int64(header + n + 1))
+}
+
+func dictMemory(entries int64) int64 {
+	buckets := int64(1)
+	for buckets < entries {
+		buckets <<= 1
+	}
+	return dictSize + allocSize(8*buckets) + entries*allocSize(dictEntrySize)
+}
+
+// keyMemory estimates the keyspace overhead of a key on top of its value.
+func keyMemory(key string, hasExpire bool) int64 {
+	mem := allocSize(dictEntrySize) + sdsSize(len(key))
+	if hasExpire {
+		mem += allocSize(dictEntrySize)
+	}
+	return mem
+}
+
+func stringMemory(val []byte, isInt bool) int64 {
+	switch {
+	case isInt:
+		return robjSize
+	case len(val) <= embstrMaxLen:
+		return allocSize(int64(robjSize + 3 + len(val) + 1))
+	default:
+		return robjSize + sdsSize(len(val))
+	}
+}
@@ -0,0 +1,628 @@
+package rdb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	MinVersion = 7
+	MaxVersion = 12
+)
+
+// Opcodes
+const (
+	opSlotInfo      = 244
+	opFunctionPreGA = 245
+	opFunction2     = 246
+	opModuleAux     = 247
+	opIdle          = 248
+	opFreq          = 249
+	opAux           = 250
+	opResizeDB      = 251
+	opExpireTimeMS  = 252
+	opExpireTime    = 253
+	opSelectDB      = 254
+	opEOF           = 255
+)
+
+// Value types
+const (
+	typeString            = 0
+	typeList              = 1
+	typeSet               = 2
+	typeZSet              = 3
+	typeHash              = 4
+	typeZSet2             = 5
+	typeModulePreGA       = 6
+	typeModule2           = 7
+	typeHashZipmap        = 9
+	typeListZiplist       = 10
+	typeSetIntset         = 11
+	typeZSetZiplist       = 12
+	typeHashZiplist       = 13
+	typeListQuicklist     = 14
+	typeStreamListpacks   = 15
+	typeHashListpack      = 16
+	typeZSetListpack      = 17
+	typeListQuicklist2    = 18
+	typeStreamListpacks2  = 19
+	typeSetListpack       = 20
+	typeStreamListpacks3  = 21
+	typeHashMetadataPreGA = 22
+	typeHashListpackExPre = 23
+	typeHashMetadata      = 24
+	typeHashListpackEx    = 25
+)
+
+// Module serialization opcodes
+const (
+	moduleOpEOF    = 0
+	moduleOpSInt   = 1
+	moduleOpUInt   = 2
+	moduleOpFloat  = 3
+	moduleOpDouble = 4
+	moduleOpString = 5
+)
+
+// Quicklist node containers
+const (
+	quicklistPlain  = 1
+	quicklistPacked = 2
+)
+
+// Entry is a key read from an RDB file, described the way TYPE, OBJECT ENCODING and
+// MEMORY USAGE would describe it on a live server.
+type Entry struct {
+	DB       int
+	Key      string
+	Type     string
+	Encoding string
+	Elements int64
+	Memory   int64
+	// Absolute expiry, zero when the key does not expire
+	ExpireAt time.Time
+	// Seconds since last access and LFU counter, -1 when the file carries neither
+	Idle int64
+	Freq int64
+}
+
+type Parser struct {
+	r       reader
+	Version int
+	Aux     map[string]string
+}
+
+// NewParser reads an RDB file from r. A *bufio.Reader is used as is, so the caller can carry on
+// reading whatever follows the RDB payload.
+func NewParser(r io.Reader) *Parser {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReaderSize(r, 64*1024)
+	}
+	return &Parser{
+		r:   reader{r: br},
+		Aux: make(map[string]string),
+	}
+}
+
+// CreatedAt returns the snapshot time from the ctime aux field, or the zero time if absent.
+func (p *Parser) CreatedAt() time.Time {
+	ctime, err := strconv.ParseInt(p.Aux["ctime"], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(ctime, 0)
+}
+
+// Parse reads the whole file, calling fn for every key.
+func (p *Parser) Parse(fn func(Entry) error) error {
+	if err := p.readHeader(); err != nil {
+		return err
+	}
+
+	db := 0
+	entry := Entry{Idle: -1, Freq: -1}
+	for {
+		op, err := p.r.byte()
+		if err != nil {
+			return fmt.Errorf("reading opcode: %w", err)
+		}
+
+		switch op {
+		case opEOF:
+			// CRC64 checksum, consumed so that an AOF preamble can be followed by commands
+			return p.r.skip(8)
+		case opSelectDB:
+			n, err := p.r.plainLength()
+			if err != nil {
+				return err
+			}
+			db = int(n)
+		case opResizeDB:
+			if _, err := p.r.plainLength(); err != nil {
+				return err
+			}
+			if _, err := p.r.plainLength(); err != nil {
+				return err
+			}
+		case opAux:
+			key, _, err := p.r.string()
+			if err != nil {
+				return err
+			}
+			val, _, err := p.r.string()
+			if err != nil {
+				return err
+			}
+			p.Aux[string(key)] = string(val)
+		case opExpireTimeMS:
+			ms, err := p.r.millis()
+			if err != nil {
+				return err
+			}
+			entry.ExpireAt = time.UnixMilli(ms)
+		case opExpireTime:
+			sec, err := p.r.uint32LE()
+			if err != nil {
+				return err
+			}
+			entry.ExpireAt = time.Unix(int64(sec), 0)
+		case opIdle:
+			idle, err := p.r.plainLength()
+			if err != nil {
+				return err
+			}
+			entry.Idle = int64(idle)
+		case opFreq:
+			freq, err := p.r.byte()
+			if err != nil {
+				return err
+			}
+			entry.Freq = int64(freq)
+		case opModuleAux:
+			if err := p.skipModuleAux(); err != nil {
+				return err
+			}
+		case opFunction2:
+			if _, _, err := p.r.string(); err != nil {
+				return err
+			}
+		case opSlotInfo:
+			for i := 0; i < 3; i++ {
+				if _, err := p.r.plainLength(); err != nil {
+					return err
+				}
+			}
+		case opFunctionPreGA:
+			return fmt.Errorf("unsupported pre-release function opcode")
+		default:
+			key, _, err := p.r.string()
+			if err != nil {
+				return fmt.Errorf("reading key: %w", err)
+			}
+			entry.DB = db
+			entry.Key = string(key)
+			if err := p.readValue(op, &entry); err != nil {
+				return fmt.Errorf("reading key %q: %w", entry.Key, err)
+			}
+			entry.Memory += keyMemory(entry.Key, !entry.ExpireAt.IsZero())
+			if err := fn(entry); err != nil {
+				return err
+			}
+			entry = Entry{Idle: -1, Freq: -1}
+		}
+	}
+}
+
+func (p *Parser) readHeader() error {
+	magic, err := p.r.bytes(9)
+	if err != nil {
+		return fmt.Errorf("reading header: %w", err)
+	}
+	if string(magic[:5]) != "REDIS" {
+		return fmt.Errorf("not an RDB file")
+	}
+
+	version, err := strconv.Atoi(string(magic[5:]))
+	if err != nil {
+		return fmt.Errorf("invalid RDB version %q", magic[5:])
+	}
+	if version < MinVersion || version > MaxVersion {
+		return fmt.Errorf("unsupported RDB version %d, must be between %d and %d", version, MinVersion, MaxVersion)
+	}
+	p.Version = version
+	return nil
+}
+
+// readValue reads the value of the given type into e, setting its type, encoding, element
+// count and estimated value memory.
+func (p *Parser) readValue(valueType byte, e *Entry) error {
+	switch valueType {
+	case typeString:
+		val, isInt, err := p.r.string()
+		if err != nil {
+			return err
+		}
+		e.Type, e.Elements, e.Memory = "string", int64(len(val)), stringMemory(val, isInt)
+		switch {
+		case isInt:
+			e.Encoding = "int"
+		case len(val) <= embstrMaxLen:
+			e.Encoding = "embstr"
+		default:
+			e.Encoding = "raw"
+		}
+		return nil
+
+	case typeList:
+		n, mem, err := p.readStrings(1, listNodeSize+robjSize)
+		e.Type, e.Encoding, e.Elements, e.Memory = "list", "quicklist", n, quicklistSize+mem
+		return err
+
+	case typeSet:
+		n, mem, err := p.readStrings(1, 0)
+		e.Type, e.Encoding, e.Elements, e.Memory = "set", "hashtable", n, dictMemory(n)+mem
+		return err
+
+	case typeHash:
+		n, mem, err := p.readStrings(2, 0)
+		e.Type, e.Encoding, e.Elements, e.Memory = "hash", "hashtable", n, dictMemory(n)+mem
+		return err
+
+	case typeZSet, typeZSet2:
+		n, err := p.r.plainLength()
+		if err != nil {
+			return err
+		}
+		var mem int64
+		for i := uint64(0); i < n; i++ {
+			member, _, err := p.r.string()
+			if err != nil {
+				return err
+			}
+			mem += sdsSize(len(member)) + skiplistNodeSize
+			if valueType == typeZSet {
+				err = p.r.doubleString()
+			} else {
+				err = p.r.skip(8)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		e.Type, e.Encoding, e.Elements, e.Memory = "zset", "skiplist", int64(n), dictMemory(int64(n))+mem
+		return nil
+
+	case typeHashZipmap:
+		return p.readBlob(e, "hash", "zipmap", zipmapLen, 1)
+	case typeListZiplist:
+		return p.readBlob(e, "list", "ziplist", ziplistLen, 1)
+	case typeSetIntset:
+		return p.readBlob(e, "set", "intset", intsetLen, 1)
+	case typeZSetZiplist:
+		return p.readBlob(e, "zset", "ziplist", ziplistLen, 2)
+	case typeHashZiplist:
+		return p.readBlob(e, "hash", "ziplist", ziplistLen, 2)
+	case typeHashListpack:
+		return p.readBlob(e, "hash", "listpack", listpackLen, 2)
+	case typeZSetListpack:
+		return p.readBlob(e, "zset", "listpack", listpackLen, 2)
+	case typeSetListpack:
+		return p.readBlob(e, "set", "listpack", listpackLen, 1)
+
+	case typeHashListpackEx, typeHashListpackExPre:
+		if valueType == typeHashListpackEx {
+			// Minimum field expiry
+			if _, err := p.r.millis(); err != nil {
+				return err
+			}
+		}
+		return p.readBlob(e, "hash", "listpackex", listpackLen, 3)
+
+	case typeHashMetadata, typeHashMetadataPreGA:
+		if valueType == typeHashMetadata {
+			if _, err := p.r.millis(); err != nil {
+				return err
+			}
+		}
+		n, err := p.r.plainLength()
+		if err != nil {
+			return err
+		}
+		var mem int64
+		for i := uint64(0); i < n; i++ {
+			// Field expiry, then field and value
+			if _, err := p.r.plainLength(); err != nil {
+				return err
+			}
+			for j := 0; j < 2; j++ {
+				val, _, err := p.r.string()
+				if err != nil {
+					return err
+				}
+				mem += sdsSize(len(val))
+			}
+		}
+		e.Type, e.Encoding, e.Elements, e.Memory = "hash", "hashtable", int64(n), dictMemory(int64(n))+mem
+		return nil
+
+	case typeListQuicklist:
+		return p.readQuicklist(e, false)
+	case typeListQuicklist2:
+		return p.readQuicklist(e, true)
+
+	case typeStreamListpacks, typeStreamListpacks2, typeStreamListpacks3:
+		return p.readStream(e, valueType)
+
+	case typeModule2:
+		id, err := p.r.plainLength()
+		if err != nil {
+			return err
+		}
+		size, err := p.skipModuleValue()
+		e.Type, e.Encoding, e.Memory = moduleName(id), "raw", size
+		return err
+
+	case typeModulePreGA:
+		return fmt.Errorf("unsupported pre-release module value")
+	default:
+		return fmt.Errorf("unknown value type %d", valueType)
+	}
+}
+
+// readStrings reads a length-prefixed run of groups of per strings, returning the group count
+// and the memory of the strings plus overhead per string.
+func (p *Parser) readStrings(per int, overhead int64) (int64, int64, error) {
+	n, err := p.r.plainLength()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var mem int64
+	for i := uint64(0); i < n*uint64(per); i++ {
+		val, _, err := p.r.string()
+		if err != nil {
+			return 0, 0, err
+		}
+		mem += sdsSize(len(val)) + overhead
+	}
+	return int64(n), mem, nil
+}
+
+// readBlob reads a compact encoding stored as a single string, counting its entries with count
+// and dividing by per to get elements (e.g. field-value pairs).
+func (p *Parser) readBlob(
+	e *Entry,
+	keyType, encoding string,
+	count func([]byte) (int64, error),
+	per int64,
+) error {
+	blob, _, err := p.r.string()
+	if err != nil {
+		return err
+	}
+	n, err := count(blob)
+	if err != nil {
+		return err
+	}
+	e.Type, e.Encoding, e.Elements = keyType, encoding, n/per
+	e.Memory = robjSize + allocSize(int64(len(blob)))
+	return nil
+}
+
+func (p *Parser) readQuicklist(e *Entry, v2 bool) error {
+	nodes, err := p.r.plainLength()
+	if err != nil {
+		return err
+	}
+
+	var elements int64
+	mem := int64(robjSize + quicklistSize)
+	packed := 0
+	for i := uint64(0); i < nodes; i++ {
+		container := uint64(quicklistPacked)
+		if v2 {
+			if container, err = p.r.plainLength(); err != nil {
+				return err
+			}
+		}
+
+		blob, _, err := p.r.string()
+		if err != nil {
+			return err
+		}
+		mem += quicklistNodeSize + allocSize(int64(len(blob)))
+
+		var n int64
+		switch {
+		case container == quicklistPlain:
+			n = 1
+		case v2:
+			packed++
+			n, err = listpackLen(blob)
+		default:
+			n, err = ziplistLen(blob)
+		}
+		if err != nil {
+			return err
+		}
+		elements += n
+	}
+
+	// Since 7.2 a list small enough for one listpack node is loaded as a plain listpack
+	e.Type, e.Encoding, e.Elements, e.Memory = "list", "quicklist", elements, mem
+	if v2 && nodes == 1 && packed == 1 {
+		e.Encoding = "listpack"
+	}
+	return nil
+}
+
+func (p *Parser) readStream(e *Entry, valueType byte) error {
+	mem := int64(robjSize + streamSize)
+
+	nodes, err := p.r.plainLength()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < nodes; i++ {
+		// Master entry ID, then the listpack of entries
+		for j := 0; j < 2; j++ {
+			blob, _, err := p.r.string()
+			if err != nil {
+				return err
+			}
+			mem += allocSize(int64(len(blob)))
+		}
+		mem += streamNodeSize
+	}
+
+	length, err := p.r.plainLength()
+	if err != nil {
+		return err
+	}
+
+	// Last ID, then first ID, max deleted ID and entries added from v2
+	ids := 2
+	if valueType >= typeStreamListpacks2 {
+		ids += 5
+	}
+	for i := 0; i < ids; i++ {
+		if _, err := p.r.plainLength(); err != nil {
+			return err
+		}
+	}
+
+	groups, err := p.r.plainLength()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < groups; i++ {
+		if err := p.readStreamGroup(valueType, &mem); err != nil {
+			return err
+		}
+	}
+
+	e.Type, e.Encoding, e.Elements, e.Memory = "stream", "stream", int64(length), mem
+	return nil
+}
+
+func (p *Parser) readStreamGroup(valueType byte, mem *int64) error {
+	if _, _, err := p.r.string(); err != nil {
+		return err
+	}
+	// Last delivered ID, then entries read from v2
+	fields := 2
+	if valueType >= typeStreamListpacks2 {
+		fields++
+	}
+	for i := 0; i < fields; i++ {
+		if _, err := p.r.plainLength(); err != nil {
+			return err
+		}
+	}
+	*mem += streamGroupSize
+
+	// Pending entries: raw ID, delivery time and delivery count
+	pending, err := p.r.plainLength()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < pending; i++ {
+		if err := p.r.skip(16 + 8); err != nil {
+			return err
+		}
+		if _, err := p.r.plainLength(); err != nil {
+			return err
+		}
+		*mem += streamNackSize
+	}
+
+	consumers, err := p.r.plainLength()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < consumers; i++ {
+		if _, _, err := p.r.string(); err != nil {
+			return err
+		}
+		// Seen time, then active time from v3
+		times := 1
+		if valueType >= typeStreamListpacks3 {
+			times++
+		}
+		if err := p.r.skip(8 * times); err != nil {
+			return err
+		}
+
+		owned, err := p.r.plainLength()
+		if err != nil {
+			return err
+		}
+		if err := p.r.skip(16 * int(owned)); err != nil {
+			return err
+		}
+		*mem += streamConsumer
+	}
+	return nil
+}
+
+func (p *Parser) skipModuleAux() error {
+	// Module ID, when opcode and when
+	for i := 0; i < 3; i++ {
+		if _, err := p.r.plainLength(); err != nil {
+			return err
+		}
+	}
+	_, err := p.skipModuleValue()
+	return err
+}
+
+// skipModuleValue skips a module serialized value, returning its serialized size as a rough
+// memory estimate.
+func (p *Parser) skipModuleValue() (int64, error) {
+	var size int64
+	for {
+		op, err := p.r.plainLength()
+		if err != nil {
+			return 0, err
+		}
+
+		switch op {
+		case moduleOpEOF:
+			return size, nil
+		case moduleOpSInt, moduleOpUInt:
+			_, err = p.r.plainLength()
+			size += 8
+		case moduleOpFloat:
+			err = p.r.skip(4)
+			size += 4
+		case moduleOpDouble:
+			err = p.r.skip(8)
+			size += 8
+		case moduleOpString:
+			var val []byte
+			val, _, err = p.r.string()
+			size += int64(len(val))
+		default:
+			return 0, fmt.Errorf("unknown module opcode %d", op)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+const moduleCharset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+// moduleName decodes the 9 character type name packed into the top 54 bits of a module ID.
+func moduleName(id uint64) string {
+	name := make([]byte, 9)
+	for i := range name {
+		name[i] = moduleCharset[(id>>(64-6*(i+1)))&63]
+	}
+	return string(name)
+}
@@ -0,0 +1,176 @@
+package rdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Special string encodings flagged by the two top bits of a length byte
+const (
+	encInt8  = 0
+	encInt16 = 1
+	encInt32 = 2
+	encLZF   = 3
+)
+
+type reader struct {
+	r *bufio.Reader
+}
+
+func (r *reader) byte() (byte, error) {
+	return r.r.ReadByte()
+}
+
+func (r *reader) bytes(n uint64) ([]byte, error) {
+	if n > math.MaxInt32 {
+		return nil, fmt.Errorf("length %d too large", n)
+	}
+	buf := make([]byte, n)
+	_, err := io.ReadFull(r.r, buf)
+	return buf, err
+}
+
+func (r *reader) skip(n int) error {
+	_, err := r.r.Discard(n)
+	return err
+}
+
+func (r *reader) uint32LE() (uint32, error) {
+	buf, err := r.bytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf), nil
+}
+
+func (r *reader) uint64LE() (uint64, error) {
+	buf, err := r.bytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf), nil
+}
+
+// millis reads an 8 byte little endian millisecond timestamp.
+func (r *reader) millis() (int64, error) {
+	v, err := r.uint64LE()
+	return int64(v), err
+}
+
+// length reads a length prefix. When encoded is true the value is one of the special string
+// encodings instead of a length.
+func (r *reader) length() (value uint64, encoded bool, err error) {
+	b, err := r.byte()
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch b >> 6 {
+	case 0:
+		return uint64(b & 0x3f), false, nil
+	case 1:
+		next, err := r.byte()
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(b&0x3f)<<8 | uint64(next), false, nil
+	case 2:
+		switch b {
+		case 0x80:
+			buf, err := r.bytes(4)
+			if err != nil {
+				return 0, false, err
+			}
+			return uint64(binary.BigEndian.Uint32(buf)), false, nil
+		case 0x81:
+			buf, err := r.bytes(8)
+			if err != nil {
+				return 0, false, err
+			}
+			return binary.BigEndian.Uint64(buf), false, nil
+		default:
+			return 0, false, fmt.Errorf("invalid length prefix 0x%02x", b)
+		}
+	default:
+		return uint64(b & 0x3f), true, nil
+	}
+}
+
+// plainLength reads a length that must not use a special encoding.
+func (r *reader) plainLength() (uint64, error) {
+	n, encoded, err := r.length()
+	if err != nil {
+		return 0, err
+	}
+	if encoded {
+		return 0, fmt.Errorf("unexpected encoded length")
+	}
+	return n, nil
+}
+
+// string reads a length-prefixed string, reporting whether it was stored as an integer.
+func (r *reader) string() (val []byte, isInt bool, err error) {
+	n, encoded, err := r.length()
+	if err != nil {
+		return nil, false, err
+	}
+	if !encoded {
+		val, err = r.bytes(n)
+		return val, false, err
+	}
+
+	switch n {
+	case encInt8:
+		b, err := r.byte()
+		if err != nil {
+			return nil, false, err
+		}
+		return []byte(strconv.Itoa(int(int8(b)))), true, nil
+	case encInt16:
+		buf, err := r.bytes(2)
+		if err != nil {
+			return nil, false, err
+		}
+		return []byte(strconv.Itoa(int(int16(binary.LittleEndian.Uint16(buf))))), true, nil
+	case encInt32:
+		buf, err := r.bytes(4)
+		if err != nil {
+			return nil, false, err
+		}
+		return []byte(strconv.Itoa(int(int32(binary.LittleEndian.Uint32(buf))))), true, nil
+	case encLZF:
+		compressedLen, err := r.plainLength()
+		if err != nil {
+			return nil, false, err
+		}
+		rawLen, err := r.plainLength()
+		if err != nil {
+			return nil, false, err
+		}
+		compressed, err := r.bytes(compressedLen)
+		if err != nil {
+			return nil, false, err
+		}
+		val, err := lzfDecompress(compressed, int(rawLen))
+		return val, false, err
+	default:
+		return nil, false, fmt.Errorf("unknown string encoding %d", n)
+	}
+}
+
+// doubleString reads a score of the original ZSET type, stored as a length-prefixed decimal.
+func (r *reader) doubleString() error {
+	n, err := r.byte()
+	if err != nil {
+		return err
+	}
+	// 253, 254 and 255 stand for NaN, +inf and -inf with no payload
+	if n >= 253 {
+		return nil
+	}
+	return r.skip(int(n))
+}
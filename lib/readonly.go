@@ -0,0 +1,129 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrReadOnly is returned for commands a read-only client refuses to send.
+var ErrReadOnly = errors.New("command refused in read-only mode")
+
+// readOnlyCommands are the commands a read-only client sends, with the subcommand after a | for
+// container commands. Connection setup (HELLO, AUTH, SELECT, CLIENT SETNAME/SETINFO/NO-TOUCH)
+// goes through the same check.
+var readOnlyCommands = map[string]bool{
+	"hello":           true,
+	"auth":            true,
+	"select":          true,
+	"ping":            true,
+	"client|setname":  true,
+	"client|setinfo":  true,
+	"client|info":     true,
+	"client|no-touch": true,
+	"info":            true,
+	"dbsize":          true,
+	"scan":            true,
+	"type":            true,
+	"ttl":             true,
+	"pttl":            true,
+	"exists":          true,
+	"object|encoding": true,
+	"object|freq":     true,
+	"object|idletime": true,
+	"memory|usage":    true,
+	"slowlog|get":     true,
+	"slowlog|len":     true,
+	"latency|latest":  true,
+	"latency|history": true,
+	"latency|doctor":  true,
+	"acl|whoami":      true,
+	"acl|getuser":     true,
+	"config|get":      true,
+	"monitor":         true,
+	"psubscribe":      true,
+	"punsubscribe":    true,
+	"strlen":          true,
+	"getrange":        true,
+	"llen":            true,
+	"lrange":          true,
+	"hlen":            true,
+	"hscan":           true,
+	"scard":           true,
+	"sscan":           true,
+	"zcard":           true,
+	"zscan":           true,
+	"xlen":            true,
+	"xrange":          true,
+}
+
+// Commands whose first argument is a subcommand
+var containerCommands = map[string]bool{
+	"acl":     true,
+	"client":  true,
+	"config":  true,
+	"latency": true,
+	"memory":  true,
+	"object":  true,
+	"slowlog": true,
+}
+
+// commandName returns the name of a command, with its subcommand after a | for container commands.
+func commandName(args []interface{}) string {
+	if len(args) == 0 {
+		return ""
+	}
+	name := strings.ToLower(fmt.Sprint(args[0]))
+	if containerCommands[name] && len(args) > 1 {
+		name += "|" + strings.ToLower(fmt.Sprint(args[1]))
+	}
+	return name
+}
+
+// CommandAllowed reports whether a read-only client sends a command, given its arguments. CONFIG
+// SET is never allowed, so a read-only client leaves the server config alone.
+func CommandAllowed(args []interface{}) bool {
+	return readOnlyCommands[commandName(args)]
+}
+
+// ReadOnlyHook is a go-redis hook refusing every command outside the allow-list before it
+// reaches the server.
+type ReadOnlyHook struct{}
+
+func (ReadOnlyHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (ReadOnlyHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := checkReadOnly(cmd); err != nil {
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+// ProcessPipelineHook refuses a whole pipeline when any of its commands is refused.
+func (ReadOnlyHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if err := checkReadOnly(cmd); err != nil {
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+func checkReadOnly(cmd redis.Cmder) error {
+	if CommandAllowed(cmd.Args()) {
+		return nil
+	}
+	name := strings.ToUpper(strings.ReplaceAll(commandName(cmd.Args()), "|", " "))
+	err := fmt.Errorf("%w: %s", ErrReadOnly, name)
+	cmd.SetErr(err)
+	return err
+}
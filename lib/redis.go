@@ -5,10 +5,39 @@ import (
 	"crypto/tls"
 	"fmt"
 	"github.com/redis/go-redis/v9"
+	"net"
 	"redscout/models"
+	"strings"
+	"sync"
+	"time"
 )
 
+// ownClients are the addresses RedScout's own connections have, so that its commands can be
+// left out of what MONITOR shows.
+var ownClients = struct {
+	sync.RWMutex
+	addrs map[string]bool
+}{addrs: make(map[string]bool)}
+
+// IsOwnClient reports whether a client address, as MONITOR shows it, is a connection of RedScout.
+func IsOwnClient(addr string) bool {
+	ownClients.RLock()
+	defer ownClients.RUnlock()
+	return ownClients.addrs[addr]
+}
+
+func addOwnClient(addr string) {
+	ownClients.Lock()
+	defer ownClients.Unlock()
+	ownClients.addrs[addr] = true
+}
+
 func RedisClientFromConfig(config *models.Config) (*redis.Client, error) {
+	return RedisClientForDB(config, config.RedisDB)
+}
+
+// RedisClientForDB connects to the configured server, selecting the given logical database.
+func RedisClientForDB(config *models.Config, db int) (*redis.Client, error) {
 	tlsConf := &tls.Config{}
 	if !config.UseTLS {
 		tlsConf = nil
@@ -20,10 +49,63 @@ func RedisClientFromConfig(config *models.Config) (*redis.Client, error) {
 		ClientName: "redscout",
 		Username:   config.RedisUser,
 		Password:   config.RedisPassword,
-		DB:         config.RedisDB,
+		DB:         db,
 		TLSConfig:  tlsConf,
+		Dialer:     ownDialer(tlsConf),
+		OnConnect:  onConnect,
 	})
+	if config.ReadOnly {
+		client.AddHook(ReadOnlyHook{})
+	}
 
 	err := client.Ping(context.Background()).Err()
 	return client, err
 }
+
+// ownDialer dials like go-redis does by default, remembering the local address of every
+// connection as one of RedScout's own.
+func ownDialer(tlsConf *tls.Config) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, KeepAlive: 5 * time.Minute}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var conn net.Conn
+		var err error
+		if tlsConf != nil {
+			conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConf}).DialContext(ctx, network, addr)
+		} else {
+			conn, err = dialer.DialContext(ctx, network, addr)
+		}
+		if err == nil {
+			addOwnClient(conn.LocalAddr().String())
+		}
+		return conn, err
+	}
+}
+
+// onConnect sets up every new connection of RedScout's clients.
+func onConnect(ctx context.Context, cn *redis.Conn) error {
+	registerOwnClient(ctx, cn)
+	noTouch(ctx, cn)
+	return nil
+}
+
+// noTouch keeps the reads of a connection, such as the length commands of the scan and the key
+// inspector, from updating the LRU idle time or LFU counter of keys. CLIENT NO-TOUCH needs Redis
+// 7.2, before it these reads touch keys like any client's.
+func noTouch(ctx context.Context, cn *redis.Conn) {
+	_ = cn.Do(ctx, "client", "no-touch", "on").Err()
+}
+
+// registerOwnClient remembers the address the server sees a new connection from, which differs
+// from the local one behind NAT or a proxy. CLIENT INFO needs Redis 6.2, before it the local
+// address has to do.
+func registerOwnClient(ctx context.Context, cn *redis.Conn) {
+	info, err := cn.Do(ctx, "client", "info").Text()
+	if err != nil {
+		return
+	}
+	for _, field := range strings.Fields(info) {
+		if addr, ok := strings.CutPrefix(field, "addr="); ok {
+			addOwnClient(addr)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package scanner
+
+import (
+	"fmt"
+	"redscout/models"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// FetchACL checks whether the ACL user RedScout is connected as can write data, with ACL WHOAMI
+// and ACL GETUSER. Servers before Redis 6 have no ACLs, anyone connected can write.
+func (s *Scanner) FetchACL() models.ACLStatus {
+	s.muRedis.Lock()
+	defer s.muRedis.Unlock()
+
+	if ver, err := semver.NewVersion(s.State.RedisInfo.Server.RedisVersion); err == nil && ver.Major() < 6 {
+		return models.ACLStatus{User: "default", Checked: true, CanWrite: true}
+	}
+
+	user, err := s.redis.Do(s.ctx, "acl", "whoami").Text()
+	if err != nil {
+		return models.ACLStatus{Err: fmt.Errorf("ACL WHOAMI failed: %w", err)}
+	}
+	reply, err := s.redis.Do(s.ctx, "acl", "getuser", user).Result()
+	if err != nil {
+		return models.ACLStatus{User: user, Err: fmt.Errorf("ACL GETUSER failed: %w", err)}
+	}
+	rules, ok := aclField(reply, "commands")
+	if !ok {
+		return models.ACLStatus{User: user, Err: fmt.Errorf("ACL GETUSER %s has no commands", user)}
+	}
+	return models.ACLStatus{User: user, Checked: true, CanWrite: models.ACLRulesCanWrite(rules)}
+}
+
+// aclField reads a string field of an ACL GETUSER reply, a map over RESP3 and a flat list of
+// field names and values over RESP2.
+func aclField(reply interface{}, field string) (string, bool) {
+	switch reply := reply.(type) {
+	case map[interface{}]interface{}:
+		value, ok := reply[field].(string)
+		return value, ok
+	case []interface{}:
+		for i := 0; i+1 < len(reply); i += 2 {
+			if name, _ := reply[i].(string); name == field {
+				value, ok := reply[i+1].(string)
+				return value, ok
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,229 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"redscout/models"
+	"time"
+)
+
+var errWritesDisabled = errors.New("writes are disabled, start RedScout with --read-only=false --allow-writes")
+
+// Bulk actions SCAN and write this many keys at a time, pausing in between to spare the server
+const (
+	bulkBatchSize  = 500
+	bulkBatchPause = 100 * time.Millisecond
+)
+
+func (s *Scanner) checkWrites() error {
+	if s.redis == nil {
+		return fmt.Errorf("writing is %w", errOffline)
+	}
+	if !s.Config.AllowWrites {
+		return errWritesDisabled
+	}
+	return nil
+}
+
+// ExpireKey sets the TTL of a key.
+func (s *Scanner) ExpireKey(name string, db int, ttl time.Duration) error {
+	return s.writeKey(models.ActionExpire, name, db, ttl)
+}
+
+// UnlinkKey removes a key, freeing its memory in the background.
+func (s *Scanner) UnlinkKey(name string, db int) error {
+	return s.writeKey(models.ActionUnlink, name, db, 0)
+}
+
+func (s *Scanner) writeKey(kind, name string, db int, ttl time.Duration) error {
+	if err := s.checkWrites(); err != nil {
+		return err
+	}
+	audit, err := s.openAuditLog()
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+
+	s.muRedis.Lock()
+	defer s.muRedis.Unlock()
+	client, err := s.clientForDB(db)
+	if err != nil {
+		return err
+	}
+
+	if kind == models.ActionExpire {
+		err = client.Expire(s.ctx, name, ttl).Err()
+	} else {
+		err = client.Unlink(s.ctx, name).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s %s: %w", kind, name, err)
+	}
+	writeAudit(audit, kind, db, ttl, name)
+	return nil
+}
+
+// openAuditLog opens the audit log for appending, creating it and its directory if needed.
+func (s *Scanner) openAuditLog() (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(s.Config.AuditLog), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(s.Config.AuditLog, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return f, nil
+}
+
+// writeAudit logs a key written to. The key goes last so that keys containing spaces stay
+// readable.
+func writeAudit(audit *os.File, kind string, db int, ttl time.Duration, name string) {
+	detail := "-"
+	if kind == models.ActionExpire {
+		detail = ttl.String()
+	}
+	_, _ = fmt.Fprintf(audit, "%s %s %s %s %s\n", time.Now().Format(time.RFC3339), kind, models.DBName(db), detail, name)
+}
+
+// NamespaceAction returns a bulk action on every key of a namespace below the current prefix.
+func (s *Scanner) NamespaceAction(namespace, kind string, ttl time.Duration, dryRun bool) (models.BulkAction, error) {
+	prefix, err := s.kp.Append(s.State.CurrentPrefix, namespace, true)
+	if err != nil {
+		return models.BulkAction{}, err
+	}
+
+	db := s.Config.RedisDB
+	parts := prefix
+	if s.State.DBLevel {
+		if _, err := fmt.Sscanf(parts[0], "db%d", &db); err != nil {
+			return models.BulkAction{}, fmt.Errorf("invalid database %s", parts[0])
+		}
+		parts = parts[1:]
+	}
+
+	return models.BulkAction{
+		Kind:    kind,
+		TTL:     ttl,
+		DryRun:  dryRun,
+		DB:      db,
+		Pattern: models.NamespacePattern(parts, s.Config.Delimiter),
+		Prefix:  prefix,
+	}, nil
+}
+
+// RunBulkAction scans the database of the action for its keys and expires or unlinks them in
+// throttled batches, reporting progress in State.Action. Every key written to is logged to the
+// audit log.
+func (s *Scanner) RunBulkAction(action models.BulkAction) error {
+	s.muAction.Lock()
+	if s.State.Action != nil && !s.State.Action.Finished {
+		s.muAction.Unlock()
+		return errors.New("a bulk action is already running")
+	}
+	progress := &models.ActionProgress{Action: action, Total: s.State.RedisInfo.DBKeyspace(action.DB).Keys}
+	s.State.Action = progress
+	s.muAction.Unlock()
+
+	err := s.runBulkAction(action, progress)
+	s.muAction.Lock()
+	progress.Err = err
+	progress.Finished = true
+	s.muAction.Unlock()
+	s.State.Updates <- s.State
+	return err
+}
+
+func (s *Scanner) runBulkAction(action models.BulkAction, progress *models.ActionProgress) error {
+	if err := s.checkWrites(); err != nil {
+		return err
+	}
+	audit, err := s.openAuditLog()
+	if err != nil {
+		return err
+	}
+	defer audit.Close()
+
+	log.Printf("Bulk %s of %s in db %d started, dry run: %v", action.Kind, action.Pattern, action.DB, action.DryRun)
+	if !action.DryRun {
+		_, _ = fmt.Fprintf(audit, "# %s bulk %s %s %s\n", time.Now().Format(time.RFC3339), action.Kind, models.DBName(action.DB), action.Pattern)
+	}
+
+	var cursor uint64
+	for {
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+		done, err := s.bulkBatch(action, progress, audit, &cursor)
+		if err != nil {
+			return err
+		}
+		s.State.Updates <- s.State
+		if done {
+			break
+		}
+		time.Sleep(bulkBatchPause)
+	}
+
+	log.Printf("Bulk %s of %s finished: %d scanned, %d matched, %d written", action.Kind, action.Pattern, progress.Scanned, progress.Matched, progress.Done)
+	return nil
+}
+
+// bulkBatch handles one SCAN page, once the load guard lets it, holding muRedis only for its
+// duration.
+func (s *Scanner) bulkBatch(action models.BulkAction, progress *models.ActionProgress, audit *os.File, cursor *uint64) (bool, error) {
+	s.muRedis.Lock()
+	defer s.muRedis.Unlock()
+
+	client, err := s.clientForDB(action.DB)
+	if err != nil {
+		return false, err
+	}
+	if err := s.waitForLoad(s.ctx, client); err != nil {
+		return false, err
+	}
+	keys, next, err := client.Scan(s.ctx, *cursor, action.Pattern, bulkBatchSize).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to scan %s: %w", action.Pattern, err)
+	}
+	*cursor = next
+	// SCAN MATCH only returns the keys matching, each call goes through about COUNT keys
+	progress.Scanned += bulkBatchSize
+	if next == 0 || progress.Scanned > progress.Total {
+		progress.Scanned = progress.Total
+	}
+
+	// The glob of an ID part also matches delimiters, so keys are checked against the prefix
+	var matched []string
+	for _, key := range keys {
+		if action.Covers(s.kp, s.recordKey(key, action.DB)) {
+			matched = append(matched, key)
+		}
+	}
+	progress.Matched += int64(len(matched))
+
+	if !action.DryRun && len(matched) > 0 {
+		pipe := client.Pipeline()
+		for _, key := range matched {
+			if action.Kind == models.ActionExpire {
+				pipe.Expire(s.ctx, key, action.TTL)
+			} else {
+				pipe.Unlink(s.ctx, key)
+			}
+		}
+		cmds, err := pipe.Exec(s.ctx)
+		for i, cmd := range cmds {
+			if cmd.Err() == nil {
+				progress.Done++
+				writeAudit(audit, action.Kind, action.DB, action.TTL, matched[i])
+			}
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to %s keys: %w", action.Kind, err)
+		}
+	}
+	return next == 0, nil
+}
@@ -5,24 +5,40 @@ import (
 	"container/heap"
 	"io"
 	"log"
+	"redscout/lib"
 	"redscout/models"
-	"strconv"
 	"strings"
+	"time"
 )
 
 func (s *Scanner) ComputeNamespaceStats() error {
+	metrics, err := s.namespaceMetrics(s.State.CurrentPrefix)
+	if err != nil {
+		return err
+	}
+	s.State.NamespaceStats = metrics
+	s.State.NamespaceStats.Sort("Keys")
+	s.State.History.Record(s.State.CurrentPrefix, metrics, time.Now())
+	s.State.Updates <- s.State
+
+	return nil
+}
+
+// namespaceMetrics aggregates the scan and monitor logs into the metrics of the namespaces
+// right below prefix.
+func (s *Scanner) namespaceMetrics(prefix models.Key) (models.NamespaceMetricList, error) {
 	log.Printf(
 		"Generating namespace stats for prefix: %s",
-		strings.Join(s.State.CurrentPrefix, s.Config.Delimiter),
+		strings.Join(prefix, s.Config.Delimiter),
 	)
 	snapshots := make(map[string]*models.NamespaceSnapshot)
 
-	if err := s.computeScanOpsLogs(snapshots); err != nil {
-		return err
+	if err := s.computeScanOpsLogs(snapshots, prefix); err != nil {
+		return nil, err
 	}
 
-	if err := s.computeNamespaceMonitorLog(snapshots); err != nil {
-		return err
+	if err := s.computeNamespaceMonitorLog(snapshots, prefix); err != nil {
+		return nil, err
 	}
 
 	var metrics models.NamespaceMetricList
@@ -33,24 +49,24 @@ func (s *Scanner) ComputeNamespaceStats() error {
 	if len(metrics) == 0 {
 		log.Printf(
 			"No namespace metrics found for prefix: %s",
-			strings.Join(s.State.CurrentPrefix, s.Config.Delimiter),
+			strings.Join(prefix, s.Config.Delimiter),
 		)
 	}
 
-	s.State.NamespaceStats = metrics
-	s.State.NamespaceStats.Sort("Keys")
-	s.State.Updates <- s.State
-
-	return nil
+	// Per-namespace evictions are only known from keyspace notifications
+	if s.State.RemovalsTracked {
+		metrics.FlagEvictionPressure()
+	}
+	return metrics, nil
 }
 
-func (s *Scanner) computeScanOpsLogs(snapshots map[string]*models.NamespaceSnapshot) error {
+func (s *Scanner) computeScanOpsLogs(snapshots map[string]*models.NamespaceSnapshot, prefix models.Key) error {
 	s.muScan.Lock()
 	defer s.muScan.Unlock()
 
 	log.Printf(
 		"Processing scan log for prefix: %s\n",
-		strings.Join(s.State.CurrentPrefix, s.Config.Delimiter),
+		strings.Join(prefix, s.Config.Delimiter),
 	)
 
 	_, err := s.scanFile.Seek(0, io.SeekStart)
@@ -58,99 +74,115 @@ func (s *Scanner) computeScanOpsLogs(snapshots map[string]*models.NamespaceSnaps
 		return err
 	}
 	scanner := bufio.NewScanner(s.scanFile)
+	footprint := models.NewCompactFootprint()
 
 	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Fields(line)
-		if len(parts) != 4 {
+		record, err := models.ParseScanRecord(scanner.Text())
+		if err != nil {
 			continue
 		}
+		footprint.Add(record.Type, record.Encoding, record.Memory, record.Elements)
 
-		key := s.kp.NewKey(parts[0], false)
-		namespace, err := s.kp.Namespace(key, s.State.CurrentPrefix, true)
+		key := s.recordKey(record.Key, record.DB)
+		namespace, err := s.kp.Namespace(key, prefix, true)
 		if err != nil {
 			continue
 		}
 
-		memory, _ := strconv.ParseInt(parts[1], 10, 64)
-		ttl, _ := strconv.ParseInt(parts[2], 10, 64)
-		keyType := parts[3]
+		keyType := record.Type
 
-		snapshot, exists := snapshots[namespace]
-		if !exists {
-			snapshot = &models.NamespaceSnapshot{
-				Namespace:    namespace,
-				OpsFrequency: make(map[string]int64),
-				Types:        make([]string, 0),
-			}
-			snapshots[namespace] = snapshot
+		snapshot := s.snapshotFor(snapshots, namespace, record.DB)
+		snapshot.AddKey(record)
+		// Keys right at the namespace have no level below it
+		if child, err := s.kp.Namespace(key, key[:len(prefix)+1], true); err == nil {
+			snapshot.ChildMemory[child] += record.Memory
 		}
 
-		snapshot.Keys++
-		snapshot.TotalMemory += memory
-		if ttl > 0 {
-			snapshot.KeysWithTTL++
-			snapshot.TotalTTL += ttl
-		}
-
-		typeExists := false
-		for _, t := range snapshot.Types {
-			if t == keyType {
-				typeExists = true
-				break
+		snapshot.Encodings[record.Encoding]++
+		if s.State.EncodingThresholds.NearThreshold(keyType, record.Encoding, record.Elements, record.Memory, lib.NearThresholdFactor) {
+			name, _ := s.State.EncodingThresholds.Threshold(keyType, record.Elements)
+			near, ok := snapshot.NearThreshold[name]
+			if !ok {
+				near = &models.NearThresholdStats{}
+				snapshot.NearThreshold[name] = near
 			}
+			near.Keys++
+			near.Memory += record.Memory
+			near.Elements += record.Elements
+			near.MaxElements = max(near.MaxElements, record.Elements)
 		}
-		if !typeExists {
-			snapshot.Types = append(snapshot.Types, keyType)
-		}
+
+		snapshot.AddAccessSample(record, s.Config.ColdAfter)
 	}
 
+	s.State.CompactFootprint = footprint
 	return scanner.Err()
 }
 
 func (s *Scanner) computeNamespaceMonitorLog(
 	snapshots map[string]*models.NamespaceSnapshot,
+	prefix models.Key,
 ) error {
 	s.muMonitor.Lock()
 	defer s.muMonitor.Unlock()
 
-	log.Printf("Processing monitor log for prefix: %s\n", strings.Join(s.State.CurrentPrefix, s.Config.Delimiter))
+	log.Printf("Processing monitor log for prefix: %s\n", strings.Join(prefix, s.Config.Delimiter))
 	_, err := s.monitorFile.Seek(0, io.SeekStart)
 	if err != nil {
 		return err
 	}
 	scanner := bufio.NewScanner(s.monitorFile)
 	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Fields(line)
-		if len(parts) != 2 {
+		record, err := models.ParseMonitorRecord(scanner.Text())
+		if err != nil {
 			continue
 		}
 
-		key := s.kp.NewKey(parts[0], false)
-		namespace, err := s.kp.Namespace(key, s.State.CurrentPrefix, true)
+		key := s.recordKey(record.Key, record.DB)
+		namespace, err := s.kp.Namespace(key, prefix, true)
 		if err != nil {
 			continue
 		}
 
-		command := parts[1]
+		snapshot := s.snapshotFor(snapshots, namespace, record.DB)
+		snapshot.OpsFrequency[record.Command]++
 
-		snapshot, exists := snapshots[namespace]
-		if !exists {
-			snapshot = &models.NamespaceSnapshot{
-				Namespace:    namespace,
-				OpsFrequency: make(map[string]int64),
-				Types:        make([]string, 0),
+		if timeline := s.State.OpsTimeline; timeline != nil && record.Time > 0 {
+			if snapshot.OpsTimeline == nil {
+				snapshot.OpsTimeline = make([]int64, timeline.Buckets)
 			}
-			snapshots[namespace] = snapshot
+			snapshot.OpsTimeline[timeline.Index(time.UnixMilli(record.Time))]++
 		}
-
-		snapshot.OpsFrequency[command]++
 	}
 
 	return scanner.Err()
 }
 
+// recordKey splits a logged key into its parts, placing the database name at the top of the
+// hierarchy when several databases are analyzed.
+func (s *Scanner) recordKey(key string, db int) models.Key {
+	k := s.kp.NewKey(key, false)
+	if !s.State.DBLevel {
+		return k
+	}
+	return append(models.Key{models.DBName(db)}, k...)
+}
+
+// snapshotFor returns the snapshot of the given namespace, creating it if missing. Every key in
+// a namespace below the database level comes from the same database, so the first one wins.
+func (s *Scanner) snapshotFor(
+	snapshots map[string]*models.NamespaceSnapshot,
+	namespace string,
+	db int,
+) *models.NamespaceSnapshot {
+	snapshot, exists := snapshots[namespace]
+	if !exists {
+		snapshot = models.NewNamespaceSnapshot(namespace, db)
+		snapshots[namespace] = snapshot
+	}
+	return snapshot
+}
+
 // ComputeBigKeysFromScanLog returns the top n keys by memory usage from the scan log using a min-heap.
 func (s *Scanner) ComputeBigKeysFromScanLog() error {
 	s.muScan.Lock()
@@ -165,19 +197,14 @@ func (s *Scanner) ComputeBigKeysFromScanLog() error {
 	h := &models.BigKeyMinHeap{}
 	heap.Init(h)
 	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Fields(line)
-		if len(parts) != 4 {
-			continue
-		}
-		keyStr := parts[0]
-		key := s.kp.NewKey(keyStr, false)
-
-		memory, err := strconv.ParseInt(parts[1], 10, 64)
+		record, err := models.ParseScanRecord(scanner.Text())
 		if err != nil {
 			continue
 		}
-		bk := models.BigKey{Key: key, Size: memory}
+		key := s.recordKey(record.Key, record.DB)
+
+		memory := record.Memory
+		bk := models.BigKey{Key: key, Name: record.Key, DB: record.DB, Size: memory, Type: record.Type, Elements: record.Elements}
 		if int64(h.Len()) < s.Config.TopK {
 			heap.Push(h, bk)
 		} else if h.Len() > 0 && (*h)[0].Size < memory {
@@ -198,6 +225,46 @@ func (s *Scanner) ComputeBigKeysFromScanLog() error {
 	return nil
 }
 
+// Most keys listed for a namespace, the largest by memory are kept
+const sampledKeysLimit = 5000
+
+// SampledKeys returns the keys of the scan log in a namespace below the current prefix,
+// largest first, each with its latest sample.
+func (s *Scanner) SampledKeys(namespace string) (models.SampledKeyList, error) {
+	s.muScan.Lock()
+	defer s.muScan.Unlock()
+
+	if _, err := s.scanFile.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	keys := models.NewSampledKeyTop(sampledKeysLimit)
+	scanner := bufio.NewScanner(s.scanFile)
+	for scanner.Scan() {
+		record, err := models.ParseScanRecord(scanner.Text())
+		if err != nil {
+			continue
+		}
+		ns, err := s.kp.Namespace(s.recordKey(record.Key, record.DB), s.State.CurrentPrefix, true)
+		if err != nil || ns != namespace {
+			continue
+		}
+		keys.Add(models.SampledKey{
+			Name:     record.Key,
+			DB:       record.DB,
+			Memory:   record.Memory,
+			TTL:      record.TTL,
+			Type:     record.Type,
+			Encoding: record.Encoding,
+			Elements: record.Elements,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keys.List(), nil
+}
+
 func (s *Scanner) ComputeHotKeysFromMonitorLog() error {
 	s.muMonitor.Lock()
 	defer s.muMonitor.Unlock()
@@ -208,15 +275,17 @@ func (s *Scanner) ComputeHotKeysFromMonitorLog() error {
 	}
 	scanner := bufio.NewScanner(s.monitorFile)
 
-	keyOps := make(map[string]int64)
+	type dbKey struct {
+		db  int
+		key string
+	}
+	keyOps := make(map[dbKey]int64)
 	for scanner.Scan() {
-		line := scanner.Text()
-		parts := strings.Fields(line)
-		if len(parts) != 2 {
+		record, err := models.ParseMonitorRecord(scanner.Text())
+		if err != nil {
 			continue
 		}
-		keyStr := parts[0]
-		keyOps[keyStr]++
+		keyOps[dbKey{record.DB, record.Key}]++
 	}
 	if err := scanner.Err(); err != nil {
 		return err
@@ -231,7 +300,7 @@ func (s *Scanner) ComputeHotKeysFromMonitorLog() error {
 	heap.Init(h)
 	for k, ops := range keyOps {
 		opsPerSec := float64(ops) / duration
-		hk := models.HotKey{Key: s.kp.NewKey(k, false), Ops: opsPerSec}
+		hk := models.HotKey{Key: s.recordKey(k.key, k.db), Name: k.key, DB: k.db, Ops: opsPerSec, Sources: []string{s.opsSource()}}
 		if int64(h.Len()) < s.Config.TopK {
 			heap.Push(h, hk)
 		} else if h.Len() > 0 && (*h)[0].Ops < opsPerSec {
@@ -244,7 +313,82 @@ func (s *Scanner) ComputeHotKeysFromMonitorLog() error {
 	for i := len(result) - 1; i >= 0; i-- {
 		result[i] = heap.Pop(h).(models.HotKey)
 	}
-	s.State.HotKeys = result
+	s.State.OpsHotKeys = result
+	s.State.HotKeys = models.MergeHotKeys(s.Config.TopK, s.State.OpsHotKeys, s.State.LFUHotKeys)
+	s.State.Updates <- s.State
+	return nil
+}
+
+// opsSource names where the ops in the monitor log came from.
+func (s *Scanner) opsSource() string {
+	switch {
+	case s.Config.AOFPath != "":
+		return models.HotKeySourceAOF
+	case s.State.WriteOpsOnly:
+		return models.HotKeySourceKeyspace
+	default:
+		return models.HotKeySourceMonitor
+	}
+}
+
+// ComputeLFUHotKeysFromScanLog ranks sampled keys by LFU counter, the server's own view of
+// which keys are hot, independent of any monitored window. Counters are decayed by
+// lfu-decay-time to the newest sample, so keys sampled by an earlier scan don't outrank
+// fresher ones. Only LFU eviction policies keep the counters.
+func (s *Scanner) ComputeLFUHotKeysFromScanLog() error {
+	if !s.State.RedisInfo.Memory.IsLFU() {
+		return nil
+	}
+
+	s.muScan.Lock()
+	defer s.muScan.Unlock()
+
+	_, err := s.scanFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(s.scanFile)
+
+	type dbKey struct {
+		db  int
+		key string
+	}
+	// A key scanned again replaces its older sample
+	latest := make(map[dbKey]models.ScanRecord)
+	var newest int64
+	for scanner.Scan() {
+		record, err := models.ParseScanRecord(scanner.Text())
+		if err != nil || record.Freq < 0 {
+			continue
+		}
+		latest[dbKey{record.DB, record.Key}] = record
+		newest = max(newest, record.Time)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	var result models.HotKeyList
+	for k, record := range latest {
+		freq := models.DecayLFU(record.Freq, newest-record.Time, s.State.LFUDecayTime)
+		if freq == 0 {
+			continue
+		}
+		result = append(result, models.HotKey{
+			Key:     s.recordKey(k.key, k.db),
+			Name:    k.key,
+			DB:      k.db,
+			Freq:    freq,
+			Sources: []string{models.HotKeySourceLFU},
+		})
+	}
+	result.Sort()
+	if int64(len(result)) > s.Config.TopK {
+		result = result[:s.Config.TopK]
+	}
+
+	s.State.LFUHotKeys = result
+	s.State.HotKeys = models.MergeHotKeys(s.Config.TopK, s.State.OpsHotKeys, s.State.LFUHotKeys)
 	s.State.Updates <- s.State
 	return nil
 }
@@ -0,0 +1,101 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"redscout/models"
+	"time"
+)
+
+// Lines read between progress updates while replaying a MONITOR capture
+const captureProgressInterval = 100000
+
+// LoadMonitorCapture replays MONITOR output captured with `redis-cli monitor` from a file, or
+// stdin for "-", into the monitor log in place of a live MONITOR session. The span between the
+// first and last captured command counts as monitored, unless a replayed AOF already covers it.
+func (s *Scanner) LoadMonitorCapture() error {
+	s.updateStatus("Replaying MONITOR capture")
+	log.Printf("Replaying MONITOR capture from %s", s.Config.MonitorFile)
+
+	var in io.Reader = os.Stdin
+	var size int64
+	if s.Config.MonitorFile != "-" {
+		f, err := os.Open(s.Config.MonitorFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		stat, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		in, size = f, stat.Size()
+	}
+	counter := &countingReader{r: in}
+
+	s.muMonitor.Lock()
+	defer s.muMonitor.Unlock()
+
+	if _, err := s.monitorFile.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to seek monitor file: %w", err)
+	}
+	s.State.MonitorProgress = 0
+
+	seen := make(map[int]bool)
+	var first, last time.Time
+	var lines int64
+
+	scanner := bufio.NewScanner(counter)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		lines++
+		if size > 0 && lines%captureProgressInterval == 0 {
+			s.State.MonitorProgress = min(float64(counter.read)/float64(size)*100, 100)
+			s.State.Updates <- s.State
+		}
+
+		// Skips the OK redis-cli prints first, among other non-command lines
+		event, err := models.ParseMonitorLine(scanner.Text())
+		if err != nil || !s.replays(event.DB) {
+			continue
+		}
+		seen[event.DB] = true
+
+		if first.IsZero() {
+			first = event.Time
+		}
+		last = event.Time
+
+		if err := s.writeMonitorRecord(event.Args, event.DB, event.Time); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if s.Config.Offline() {
+		s.addReplayedDBs(seen, "offline (MONITOR capture)")
+	}
+	if last.After(first) {
+		s.addReplayedSpan(first, last)
+	}
+	s.State.MonitorProgress = 100
+
+	log.Printf("MONITOR capture replayed; %d lines spanning %v", lines, last.Sub(first))
+	s.updateStatus("MONITOR capture replayed")
+	return nil
+}
+
+// replays reports whether replayed ops of the given database are analyzed. Offline the
+// databases are not known up front, so the flags decide.
+func (s *Scanner) replays(db int) bool {
+	if s.Config.Offline() {
+		return s.Config.AllDBs || db == s.Config.RedisDB
+	}
+	return s.State.Analyzes(db)
+}
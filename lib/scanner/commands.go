@@ -2,28 +2,74 @@ package scanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"redscout/lib"
 	"redscout/models"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// Slow log polls read the newest slowLogPage entries, and read further back, up to
+// slowLogMaxPage, only while all of them are new.
+const (
+	slowLogPage    = 16
+	slowLogMaxPage = 1024
+)
+
+// FetchSlowLog adds the slow log entries not seen before to the session history and the slow
+// log file. Entries older than slowLogMaxPage new ones count as dropped. It doesn't take
+// muRedis, so that polls go on while a scan or monitor holds it.
 func (s *Scanner) FetchSlowLog() error {
-	s.muRedis.Lock()
-	defer s.muRedis.Unlock()
+	s.muSlowLog.Lock()
+	defer s.muSlowLog.Unlock()
 
-	slowLog, err := s.redis.SlowLogGet(s.ctx, s.Config.TopK).Result()
+	length, err := s.redis.Do(s.ctx, "slowlog", "len").Int64()
+	if err != nil {
+		return err
+	}
+	var slowLog []redis.SlowLog
+	for count := int64(slowLogPage); length > 0; count *= 4 {
+		if slowLog, err = s.redis.SlowLogGet(s.ctx, count).Result(); err != nil {
+			return err
+		}
+		if int64(len(slowLog)) < count || count >= slowLogMaxPage || slowLog[len(slowLog)-1].ID <= s.State.SlowLog.LastID() {
+			break
+		}
+	}
 
-	models.SlowLogList(slowLog).Sort("Timestamp")
-	s.State.SlowLogs = slowLog
+	added := s.State.SlowLog.Merge(models.NewSlowLogList(slowLog, s.kp), length, time.Now(), s.Config.SlowLogMax)
+	if err := s.appendSlowLogFile(added); err != nil {
+		log.Printf("Error writing slow log file: %v", err)
+	}
 	s.State.Updates <- s.State
+	return nil
+}
 
-	return err
+// SlowLogUpdates polls the slow log every SlowLogInterval, so that entries are kept before
+// they rotate out of it.
+func (s *Scanner) SlowLogUpdates() {
+	if s.Config.SlowLogInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.Config.SlowLogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.FetchSlowLog(); err != nil {
+				log.Printf("Error fetching slow log: %v", err)
+			}
+		}
+	}
 }
 
 func (s *Scanner) FetchRedisInfo() error {
@@ -50,6 +96,11 @@ func (s *Scanner) FetchRedisInfo() error {
 		currCPUTime := parsed.CPU.SystemTime + parsed.CPU.UserTime
 		prevCPUTime := s.State.RedisInfo.CPU.UserTime + s.State.RedisInfo.CPU.SystemTime
 		parsed.Computed.CPUUsage = (currCPUTime - prevCPUTime) * 1000 / float64(time.Now().UnixMilli()-s.State.LastInfoCheck.UnixMilli())
+
+		elapsed := time.Since(s.State.LastInfoCheck).Seconds()
+		// Counters restart from zero with the server
+		parsed.Computed.ExpiredPerSec = float64(max(parsed.Stats.ExpiredKeys-s.State.RedisInfo.Stats.ExpiredKeys, 0)) / elapsed
+		parsed.Computed.EvictedPerSec = float64(max(parsed.Stats.EvictedKeys-s.State.RedisInfo.Stats.EvictedKeys, 0)) / elapsed
 	}
 
 	s.State.LastInfoCheck = time.Now()
@@ -60,7 +111,41 @@ func (s *Scanner) FetchRedisInfo() error {
 	return nil
 }
 
-func (s *Scanner) scanKeys() ([]string, error) {
+// FetchEncodingThresholds reads the compact encoding thresholds, keeping the defaults when
+// CONFIG is unavailable (e.g. renamed on managed services).
+func (s *Scanner) FetchEncodingThresholds() error {
+	s.muRedis.Lock()
+	defer s.muRedis.Unlock()
+
+	config, err := s.redis.ConfigGet(s.ctx, "*-max-*").Result()
+	if err != nil {
+		return err
+	}
+
+	s.State.EncodingThresholds = models.ParseEncodingThresholds(config)
+	return nil
+}
+
+// FetchLFUDecayTime reads lfu-decay-time, the minutes it takes an idle key's LFU counter to
+// drop by one, keeping the default when CONFIG is unavailable.
+func (s *Scanner) FetchLFUDecayTime() error {
+	s.muRedis.Lock()
+	defer s.muRedis.Unlock()
+
+	config, err := s.redis.ConfigGet(s.ctx, "lfu-decay-time").Result()
+	if err != nil {
+		return err
+	}
+
+	decay, err := strconv.ParseInt(config["lfu-decay-time"], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid lfu-decay-time %q: %w", config["lfu-decay-time"], err)
+	}
+	s.State.LFUDecayTime = decay
+	return nil
+}
+
+func (s *Scanner) scanKeys(client *redis.Client, db int) ([]string, error) {
 	//Batch size for scanning keys
 	scanSize := int64(lib.ScanBatchSize)
 
@@ -69,8 +154,12 @@ func (s *Scanner) scanKeys() ([]string, error) {
 		scanned   int64
 	)
 
+	s.State.ScanDB = db
 	for {
-		res, next, err := s.redis.Scan(s.ctx, s.State.Cursor, "*", scanSize).Result()
+		if err := s.waitForLoad(s.ctx, client); err != nil {
+			return nil, err
+		}
+		res, next, err := client.Scan(s.ctx, s.State.Cursors[db], "*", scanSize).Result()
 		if err != nil {
 			log.Printf("scan error: %v", err)
 			return nil, err
@@ -78,24 +167,64 @@ func (s *Scanner) scanKeys() ([]string, error) {
 
 		collected = append(collected, res...)
 		scanned += int64(len(res))
+		s.State.Cursors[db] = next
 		if next == 0 || scanned >= s.Config.KeysScanSize {
 			break
 		}
-		s.State.Cursor = next
 	}
 
 	s.State.ScannedKeys += scanned
+	s.State.ScannedKeysByDB[db] += scanned
 	return collected, nil
 }
 
 type trip struct {
-	key     string
-	mem     *redis.IntCmd
-	ttl     *redis.DurationCmd
-	typeCmd *redis.StatusCmd
+	key      string
+	mem      *redis.IntCmd
+	ttl      *redis.DurationCmd
+	typeCmd  *redis.StatusCmd
+	encoding *redis.StringCmd
+	idle     *redis.DurationCmd
+	freq     *redis.IntCmd
+	elements *redis.IntCmd
+}
+
+// pipelineErr drops per-command reply errors from a pipeline result, which are checked on
+// each command, keeping only failures of the pipeline itself.
+func pipelineErr(err error) error {
+	var redisErr redis.Error
+	if err == nil || errors.As(err, &redisErr) {
+		return nil
+	}
+	return err
+}
+
+// elementCountCmd queues the length command matching the key's type, or returns nil for
+// types without a cheap element count.
+func (s *Scanner) elementCountCmd(pipe redis.Pipeliner, key, keyType string) *redis.IntCmd {
+	switch keyType {
+	case "string":
+		return pipe.StrLen(s.ctx, key)
+	case "list":
+		return pipe.LLen(s.ctx, key)
+	case "set":
+		return pipe.SCard(s.ctx, key)
+	case "zset":
+		return pipe.ZCard(s.ctx, key)
+	case "hash":
+		return pipe.HLen(s.ctx, key)
+	case "stream":
+		return pipe.XLen(s.ctx, key)
+	default:
+		return nil
+	}
 }
 
 func (s *Scanner) ScanMemory() error {
+	if s.redis == nil {
+		s.updateStatus("Scanning memory is " + errOffline.Error())
+		return errOffline
+	}
 	s.updateStatus("Scanning memory")
 
 	s.muRedis.Lock()
@@ -103,20 +232,43 @@ func (s *Scanner) ScanMemory() error {
 
 	log.Printf("Memory scan started")
 
-	s.State.TotalKeysToScan = s.Config.KeysScanSize
+	s.State.TotalKeysToScan = s.Config.KeysScanSize * int64(len(s.State.DBs))
+	scannedBefore := s.State.ScannedKeys
+
+	if _, err := s.scanFile.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to seek scan file: %w", err)
+	}
+
+	for _, db := range s.State.DBs {
+		if err := s.scanMemoryDB(db, scannedBefore); err != nil {
+			return err
+		}
+	}
+
+	s.State.ScanProgress = 100
+	log.Printf("Memory scan completed; scanned %d keys", s.State.ScannedKeys-scannedBefore)
+	s.updateStatus("Memory scan completed")
+	return nil
+}
 
-	keys, err := s.scanKeys()
+func (s *Scanner) scanMemoryDB(db int, scannedBefore int64) error {
+	client, err := s.clientForDB(db)
 	if err != nil {
 		return err
 	}
 
-	if _, err := s.scanFile.Seek(0, io.SeekEnd); err != nil {
-		return fmt.Errorf("failed to seek scan file: %w", err)
+	keys, err := s.scanKeys(client, db)
+	if err != nil {
+		return err
 	}
 
-	processedKeys := int64(0)
+	lfu := s.State.RedisInfo.Memory.IsLFU()
+
 	for i := 0; i < len(keys); i += lib.MemoryPipeBatchSize {
-		pipe := s.redis.Pipeline()
+		if err := s.waitForLoad(s.ctx, client); err != nil {
+			return err
+		}
+		pipe := client.Pipeline()
 
 		keyBatch := keys[i:min(i+lib.MemoryPipeBatchSize, len(keys))]
 
@@ -126,13 +278,35 @@ func (s *Scanner) ScanMemory() error {
 			tr.mem = pipe.MemoryUsage(s.ctx, key)
 			tr.ttl = pipe.TTL(s.ctx, key)
 			tr.typeCmd = pipe.Type(s.ctx, key)
+			tr.encoding = pipe.ObjectEncoding(s.ctx, key)
+			// OBJECT IDLETIME only works under LRU policies and OBJECT FREQ only under LFU ones
+			if lfu {
+				tr.freq = pipe.ObjectFreq(s.ctx, key)
+			} else {
+				tr.idle = pipe.ObjectIdleTime(s.ctx, key)
+			}
 			trips = append(trips, tr)
 		}
 
-		if _, err := pipe.Exec(s.ctx); err != nil {
+		if _, err := pipe.Exec(s.ctx); pipelineErr(err) != nil {
 			return err
 		}
 
+		// Element counts need the key type, so they go in a second round trip. Before Redis 7.2
+		// has CLIENT NO-TOUCH, the length commands touch the key, which is why access history is
+		// sampled in the first one.
+		countPipe := client.Pipeline()
+		for i := range trips {
+			if xType, err := trips[i].typeCmd.Result(); err == nil {
+				trips[i].elements = s.elementCountCmd(countPipe, trips[i].key, xType)
+			}
+		}
+		if countPipe.Len() > 0 {
+			if _, err := countPipe.Exec(s.ctx); pipelineErr(err) != nil {
+				return err
+			}
+		}
+
 		for _, tr := range trips {
 			xMem, e1 := tr.mem.Result()
 			xTtl, e2 := tr.ttl.Result()
@@ -140,21 +314,54 @@ func (s *Scanner) ScanMemory() error {
 			if e1 != nil || e2 != nil || e3 != nil {
 				continue
 			}
-			_, _ = s.scanFile.WriteString(fmt.Sprintf("%s %d %d %s\n", tr.key, xMem, int64(xTtl.Seconds()), xType))
+			var elements int64
+			if tr.elements != nil {
+				elements, _ = tr.elements.Result()
+			}
+			xEncoding, err := tr.encoding.Result()
+			if err != nil {
+				xEncoding = "unknown"
+			}
+			idle, freq := int64(-1), int64(-1)
+			if tr.idle != nil {
+				if xIdle, err := tr.idle.Result(); err == nil {
+					idle = int64(xIdle.Seconds())
+				}
+			}
+			if tr.freq != nil {
+				if xFreq, err := tr.freq.Result(); err == nil {
+					freq = xFreq
+				}
+			}
+			record := models.ScanRecord{
+				Key:      tr.key,
+				Memory:   xMem,
+				TTL:      int64(xTtl.Seconds()),
+				Type:     xType,
+				Encoding: xEncoding,
+				Elements: elements,
+				Idle:     idle,
+				Freq:     freq,
+				DB:       db,
+				Time:     time.Now().Unix(),
+			}
+			_, _ = s.scanFile.WriteString(record.String() + "\n")
 		}
 
-		processedKeys += int64(len(keyBatch))
-		s.State.ScanProgress = min(float64(s.State.ScannedKeys)/float64(s.Config.KeysScanSize)*100, 100)
+		scanned := s.State.ScannedKeys - scannedBefore
+		s.State.ScanProgress = min(float64(scanned)/float64(s.State.TotalKeysToScan)*100, 100)
 		s.State.Updates <- s.State
 	}
 
-	s.State.ScanProgress = 100
-	log.Printf("Memory scan completed; scanned %d keys", len(keys))
-	s.updateStatus("Memory scan completed")
+	log.Printf("Memory scan of %s completed; scanned %d keys", models.DBName(db), len(keys))
 	return nil
 }
 
 func (s *Scanner) MonitorOps() error {
+	if s.redis == nil {
+		s.updateStatus("Monitoring is " + errOffline.Error())
+		return errOffline
+	}
 	s.muRedis.Lock()
 	defer s.muRedis.Unlock()
 	s.updateStatus("Monitoring operations")
@@ -169,19 +376,40 @@ func (s *Scanner) MonitorOps() error {
 	ch := make(chan string, 10000)
 	defer close(ch)
 
-	client, err := lib.RedisClientFromConfig(s.Config)
+	ctxTimeout, cancel := context.WithTimeout(s.ctx, s.Config.MonitorDuration)
+	defer cancel()
+
+	// MONITOR doesn't show keys the server expires or evicts, notifications do, but enabling
+	// them changes the server config so it is opt-in. Without them only the server-wide rates
+	// from INFO are known. Subscribed first so that MONITOR doesn't log our own CONFIG SET.
+	var removed <-chan *redis.Message
+	if s.Config.TrackRemovals {
+		pubsub, err := s.subscribeKeyevents(ctxTimeout, notifyEvictExpireFlags, "expired", "evicted")
+		if err != nil {
+			log.Printf("Not tracking expired and evicted keys: %v", err)
+		} else {
+			defer pubsub.Close()
+			defer s.restoreNotifications()
+			removed = pubsub.Channel()
+			s.State.RemovalsTracked = true
+		}
+	}
+
+	client, monitor, err := s.startMonitor(ch)
 	if err != nil {
 		log.Printf("Error creating Redis redis for monitoring: %v", err)
 		return err
 	}
-	defer client.Close()
-
-	ctxTimeout, cancel := context.WithTimeout(s.ctx, s.Config.MonitorDuration)
-	defer cancel()
-
-	monitor := client.Monitor(ctxTimeout, ch)
-	monitor.Start()
-	defer monitor.Stop()
+	// Stops the last MONITOR started, unless the load guard stopped it and it didn't resume
+	monitoring := true
+	stopMonitor := func() {
+		if monitoring {
+			monitor.Stop()
+			_ = client.Close()
+			monitoring = false
+		}
+	}
+	defer stopMonitor()
 
 	if _, err := s.monitorFile.Seek(0, io.SeekEnd); err != nil {
 		return fmt.Errorf("failed to seek monitor file: %w", err)
@@ -189,41 +417,99 @@ func (s *Scanner) MonitorOps() error {
 
 	progressTicker := time.NewTicker(100 * time.Millisecond)
 	defer progressTicker.Stop()
+	guardTicker := time.NewTicker(guardInterval)
+	defer guardTicker.Stop()
+	var paused time.Duration
 
 	for {
 		select {
+		case <-guardTicker.C:
+			if !s.checkLoad(s.redis, false) {
+				continue
+			}
+			// MONITOR adds to the load, so it stops until the server recovers
+			stopMonitor()
+			pausedAt := time.Now()
+			s.updateStatus("Monitoring paused by the load guard")
+			if err := s.waitForLoad(ctxTimeout, s.redis); err != nil {
+				paused += time.Since(pausedAt)
+				continue
+			}
+			paused += time.Since(pausedAt)
+			resumedClient, resumedMonitor, err := s.startMonitor(ch)
+			if err != nil {
+				return err
+			}
+			client, monitor, monitoring = resumedClient, resumedMonitor, true
+			s.updateStatus("Monitoring operations")
 		case line, ok := <-ch:
 			if !ok {
 				continue
 			}
-			parts := strings.Split(line, "\"")
-			if len(parts) < 2 {
+			event, err := models.ParseMonitorLine(line)
+			// RedScout's own commands, like the load guard's, aren't the application's ops
+			if err != nil || !s.State.Analyzes(event.DB) || lib.IsOwnClient(event.Addr) {
 				continue
 			}
-			cmd := strings.ToLower(parts[1])
-			var key string
-			if len(parts) >= 4 {
-				key = parts[3]
+			_ = s.writeMonitorRecord(event.Args, event.DB, event.Time)
+		case msg, ok := <-removed:
+			if !ok {
+				removed = nil
+				continue
 			}
-			if cmd == "eval" {
+			db, event, err := parseKeyevent(msg.Channel)
+			if err != nil || !s.State.Analyzes(db) {
 				continue
 			}
-			_, _ = s.monitorFile.WriteString(fmt.Sprintf("%s %s\n", key, cmd))
+			_ = s.writeMonitorRecord([]string{event, msg.Payload}, db, time.Now())
 		case <-progressTicker.C:
 			elapsed := time.Since(s.State.MonitorStartTime)
 			s.State.MonitorProgress = min(float64(elapsed)/float64(s.Config.MonitorDuration)*100, 100)
 			s.State.Updates <- s.State
 		case <-ctxTimeout.Done():
 			s.State.MonitorProgress = 100
-			s.State.TotalMonitorDuration += s.Config.MonitorDuration
+			// Ops are rated over the time MONITOR actually ran
+			s.State.TotalMonitorDuration += s.Config.MonitorDuration - paused
 			s.State.Updates <- s.State
 			s.updateStatus("Monitoring completed")
-			log.Printf("Monitoring completed")
+			log.Printf("Monitoring completed, paused for %v", paused.Round(time.Second))
 			return nil
 		}
 	}
 }
 
+// startMonitor runs MONITOR on a client of its own, sending the lines it reads to ch. It stops
+// when the returned client is closed.
+func (s *Scanner) startMonitor(ch chan string) (*redis.Client, *redis.MonitorCmd, error) {
+	client, err := lib.RedisClientFromConfig(s.Config)
+	if err != nil {
+		_ = client.Close()
+		return nil, nil, err
+	}
+	monitor := client.Monitor(s.ctx, ch)
+	monitor.Start()
+	return client, monitor, nil
+}
+
+// writeMonitorRecord logs a command against the key it names, its first argument. Commands
+// without a key and scripts are skipped.
+func (s *Scanner) writeMonitorRecord(args []string, db int, at time.Time) error {
+	if len(args) < 2 {
+		return nil
+	}
+	cmd := strings.ToLower(args[0])
+	if cmd == "eval" {
+		return nil
+	}
+
+	record := models.MonitorRecord{Key: args[1], Command: cmd, DB: db}
+	if !at.IsZero() {
+		record.Time = at.UnixMilli()
+	}
+	_, err := s.monitorFile.WriteString(record.String() + "\n")
+	return err
+}
+
 func (s *Scanner) InfoUpdates() {
 	ticker := time.NewTicker(s.Config.RefreshInterval)
 	defer ticker.Stop()
@@ -238,6 +524,10 @@ func (s *Scanner) InfoUpdates() {
 				log.Printf("Error fetching Redis info: %v", err)
 				continue
 			}
+			// Between scans, follow keyspace growth with the last sampled shares
+			if stats := s.State.NamespaceStats; len(stats) > 0 {
+				s.State.History.Record(s.State.CurrentPrefix, stats.Rescaled(s.State.RedisInfo), time.Now())
+			}
 		}
 	}
 }
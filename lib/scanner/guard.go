@@ -0,0 +1,128 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"redscout/models"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The load guard samples at most once per guardInterval, and while paused re-samples with a
+// backoff doubling from guardMinBackoff up to guardMaxBackoff.
+const (
+	guardInterval   = time.Second
+	guardMinBackoff = time.Second
+	guardMaxBackoff = 30 * time.Second
+)
+
+// loadGuard is what the load guard keeps between samples.
+type loadGuard struct {
+	checked time.Time
+	// CPU seconds used by the server at the last sample, for the CPU share in between
+	cpuTime float64
+	// The latency monitor only reports the latest event of each kind, so events already seen,
+	// or from before the first sample, are skipped
+	latencySeen time.Time
+}
+
+// sampleLoad measures PING round trip and reads LATENCY LATEST and INFO. Callers must hold muRedis.
+func (s *Scanner) sampleLoad(client *redis.Client) (models.LoadSample, error) {
+	sample := models.LoadSample{At: time.Now()}
+	if err := client.Ping(s.ctx).Err(); err != nil {
+		return sample, fmt.Errorf("failed to ping: %w", err)
+	}
+	sample.RTT = time.Since(sample.At)
+
+	infoStr, err := client.Info(s.ctx).Result()
+	if err != nil {
+		return sample, fmt.Errorf("failed to read info: %w", err)
+	}
+	info := models.ParseInfo(infoStr)
+	sample.OpsPerSec = info.Stats.OpsPerSec
+	sample.BlockedClients = info.Clients.BlockedClients
+	cpuTime := info.CPU.UserTime + info.CPU.SystemTime
+	if prev := s.State.LoadGuard.Sample.At; !prev.IsZero() {
+		sample.CPU = (cpuTime - s.guard.cpuTime) / sample.At.Sub(prev).Seconds()
+	}
+	s.guard.cpuTime = cpuTime
+
+	// LATENCY may be disabled or renamed, which only leaves that check out
+	if reply, err := client.Do(s.ctx, "latency", "latest").Slice(); err == nil {
+		for _, event := range models.ParseLatencyLatest(reply) {
+			if !s.guard.latencySeen.IsZero() && event.Time.After(s.guard.latencySeen) {
+				sample.EventLatency = max(sample.EventLatency, event.Latency)
+			}
+		}
+		s.guard.latencySeen = sample.At.Truncate(time.Second)
+	}
+	return sample, nil
+}
+
+// checkLoad samples the server load, at most once per guardInterval unless forced, and reports
+// whether it is over the thresholds, updating State.LoadGuard. A failed sample counts as not
+// over, so that the guard never stops an operation by itself. Callers must hold muRedis.
+func (s *Scanner) checkLoad(client *redis.Client, force bool) bool {
+	if !s.Config.LoadGuard.Enabled || (!force && time.Since(s.guard.checked) < guardInterval) {
+		return s.State.LoadGuard.Paused
+	}
+	s.guard.checked = time.Now()
+
+	sample, err := s.sampleLoad(client)
+	if err != nil {
+		log.Printf("Load guard sample failed: %v", err)
+		s.resumeLoad()
+		return false
+	}
+	s.State.LoadGuard.Sample = sample
+
+	reasons := s.Config.LoadGuard.Exceeded(sample)
+	if len(reasons) == 0 {
+		s.resumeLoad()
+		return false
+	}
+
+	guard := &s.State.LoadGuard
+	if !guard.Paused {
+		guard.Paused = true
+		guard.Since = time.Now()
+		guard.Pauses++
+	}
+	guard.Reason = strings.Join(reasons, ", ")
+	log.Printf("Load guard paused: %s", guard.Reason)
+	s.State.Updates <- s.State
+	return true
+}
+
+// waitForLoad returns once the server load is below the thresholds, re-sampling with a growing
+// backoff while it is over them. Callers must hold muRedis.
+func (s *Scanner) waitForLoad(ctx context.Context, client *redis.Client) error {
+	backoff := guardMinBackoff
+	for over := s.checkLoad(client, false); over; over = s.checkLoad(client, true) {
+		select {
+		case <-ctx.Done():
+			s.resumeLoad()
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, guardMaxBackoff)
+	}
+	return nil
+}
+
+// resumeLoad ends a pause of the load guard.
+func (s *Scanner) resumeLoad() {
+	guard := &s.State.LoadGuard
+	if !guard.Paused {
+		return
+	}
+	paused := time.Since(guard.Since)
+	guard.Paused = false
+	guard.Reason = ""
+	guard.PausedTotal += paused
+	log.Printf("Load guard resumed after %v", paused.Round(time.Second))
+	s.State.Updates <- s.State
+}
@@ -0,0 +1,104 @@
+package scanner
+
+import (
+	"log"
+	"math"
+	"redscout/lib/history"
+	"redscout/models"
+	"sort"
+	"time"
+)
+
+// Namespaces shown in the history tab
+const historyTopGrowers = 20
+
+// recordHistory records every analyzed database as a run of its own source, so its namespaces
+// are compared across runs whether it was analyzed alone or with --all-dbs, and loads the
+// anomalies and top growers of them all.
+func (s *Scanner) recordHistory() {
+	if s.Config.HistoryDB == "" {
+		return
+	}
+	store, err := history.Open(s.Config.HistoryDB)
+	if err != nil {
+		log.Printf("History disabled: %v", err)
+		return
+	}
+	defer store.Close()
+
+	dbs := []int{s.Config.RedisDB}
+	if s.State.DBLevel {
+		dbs = s.State.DBs
+	}
+
+	var anomalies models.AnomalyList
+	var growers models.NamespaceGrowthList
+	for _, db := range dbs {
+		metrics := s.State.NamespaceStats
+		if s.State.DBLevel {
+			// The top level are the databases, their namespaces are one below
+			if metrics, err = s.namespaceMetrics(models.Key{models.DBName(db)}); err != nil {
+				log.Printf("Failed to compute namespaces of %s for history: %v", models.DBName(db), err)
+				continue
+			}
+		}
+		dbAnomalies, dbGrowers := s.recordDBHistory(store, db, metrics)
+		// Namespaces read as in the Namespace tab, below their database
+		if s.State.DBLevel {
+			for i := range dbAnomalies {
+				dbAnomalies[i].Namespace = models.Key{models.DBName(db), dbAnomalies[i].Namespace}.String()
+			}
+			for i := range dbGrowers {
+				dbGrowers[i].Namespace = models.Key{models.DBName(db), dbGrowers[i].Namespace}.String()
+			}
+		}
+		anomalies = append(anomalies, dbAnomalies...)
+		growers = append(growers, dbGrowers...)
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		return math.Abs(anomalies[i].Score) > math.Abs(anomalies[j].Score)
+	})
+	sort.Slice(growers, func(i, j int) bool {
+		return growers[i].MemoryGrowth() > growers[j].MemoryGrowth()
+	})
+	s.State.Anomalies = anomalies
+	s.State.HistoryGrowers = growers[:min(len(growers), historyTopGrowers)]
+}
+
+// recordDBHistory scores the namespaces of a database against the baselines of its source, then
+// adds them to the history database and the baselines. It returns the anomalies found and the
+// top growers across every recorded run of the source.
+func (s *Scanner) recordDBHistory(store *history.Store, db int, metrics models.NamespaceMetricList) (models.AnomalyList, models.NamespaceGrowthList) {
+	source := s.Config.HistorySource(db)
+	baselines, err := store.Baselines(source)
+	if err != nil {
+		log.Printf("Failed to read baselines: %v", err)
+		return nil, nil
+	}
+	withOps := s.State.TotalMonitorDuration > 0
+	anomalies := baselines.Score(metrics, withOps)
+	baselines.Update(metrics, withOps)
+	if err := store.SaveBaselines(source, baselines); err != nil {
+		log.Printf("Failed to save baselines: %v", err)
+	}
+
+	// A dump is recorded as of when it was taken, so that replaying old ones keeps their order
+	at := time.Now()
+	if !s.State.SnapshotTime.IsZero() {
+		at = s.State.SnapshotTime
+	}
+	run := models.NewHistoryRun(s.State, db, metrics, source, at)
+	run.Anomalies = anomalies
+	if err := store.Record(run); err != nil {
+		log.Printf("Failed to record run in history: %v", err)
+		return anomalies, nil
+	}
+	runs, err := store.Runs(source, time.Time{}, time.Time{})
+	if err != nil {
+		log.Printf("Failed to read history: %v", err)
+		return anomalies, nil
+	}
+	log.Printf("Recorded run %d of %s in %s", len(runs), source, s.Config.HistoryDB)
+	return anomalies, models.TopGrowers(runs, historyTopGrowers)
+}
@@ -0,0 +1,196 @@
+package scanner
+
+import (
+	"fmt"
+	"redscout/models"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Elements per inspector page, and bytes per page of a string
+const (
+	inspectPageSize   = 50
+	inspectStringPage = 4096
+)
+
+// InspectKey reads the metadata of a key and the page of its value starting at page, which is
+// empty for the first page and KeyInspection.NextPage for the following ones. On Redis 7.2 and
+// up the connection's CLIENT NO-TOUCH keeps the range reads from touching the key.
+func (s *Scanner) InspectKey(name string, db int, page string) (*models.KeyInspection, error) {
+	if s.redis == nil {
+		return nil, fmt.Errorf("inspecting keys is %w", errOffline)
+	}
+	s.muRedis.Lock()
+	defer s.muRedis.Unlock()
+
+	client, err := s.clientForDB(db)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := client.Pipeline()
+	typeCmd := pipe.Type(s.ctx, name)
+	ttlCmd := pipe.PTTL(s.ctx, name)
+	encodingCmd := pipe.ObjectEncoding(s.ctx, name)
+	memCmd := pipe.MemoryUsage(s.ctx, name)
+	// Errors are per command, a missing key shows as type none
+	_, _ = pipe.Exec(s.ctx)
+
+	inspection := &models.KeyInspection{
+		Name:      name,
+		DB:        db,
+		Type:      typeCmd.Val(),
+		Encoding:  encodingCmd.Val(),
+		TTL:       ttlCmd.Val(),
+		Memory:    memCmd.Val(),
+		PageStart: page,
+	}
+	if err := typeCmd.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read type of %s: %w", name, err)
+	}
+	if inspection.Type == "none" {
+		return nil, fmt.Errorf("key %s no longer exists", name)
+	}
+
+	switch inspection.Type {
+	case "string":
+		err = s.inspectString(client, inspection, page)
+	case "list":
+		err = s.inspectList(client, inspection, page)
+	case "hash", "set", "zset":
+		err = s.inspectScan(client, inspection, page)
+	case "stream":
+		err = s.inspectStream(client, inspection, page)
+	default:
+		inspection.Page = []string{"No preview for type " + inspection.Type}
+	}
+	return inspection, err
+}
+
+func pageOffset(page string) (int64, error) {
+	if page == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(page, 10, 64)
+}
+
+func (s *Scanner) inspectString(client *redis.Client, k *models.KeyInspection, page string) error {
+	offset, err := pageOffset(page)
+	if err != nil {
+		return err
+	}
+	if k.Elements, err = client.StrLen(s.ctx, k.Name).Result(); err != nil {
+		return err
+	}
+	value, err := client.GetRange(s.ctx, k.Name, offset, offset+inspectStringPage-1).Result()
+	if err != nil {
+		return err
+	}
+	k.Page = models.FormatValue(value)
+	if next := offset + inspectStringPage; next < k.Elements {
+		k.NextPage = strconv.FormatInt(next, 10)
+	}
+	return nil
+}
+
+func (s *Scanner) inspectList(client *redis.Client, k *models.KeyInspection, page string) error {
+	offset, err := pageOffset(page)
+	if err != nil {
+		return err
+	}
+	if k.Elements, err = client.LLen(s.ctx, k.Name).Result(); err != nil {
+		return err
+	}
+	values, err := client.LRange(s.ctx, k.Name, offset, offset+inspectPageSize-1).Result()
+	if err != nil {
+		return err
+	}
+	for i, value := range values {
+		k.Page = append(k.Page, fmt.Sprintf("%d) %s", offset+int64(i), models.FormatElement(value)))
+	}
+	if next := offset + inspectPageSize; next < k.Elements {
+		k.NextPage = strconv.FormatInt(next, 10)
+	}
+	return nil
+}
+
+// inspectScan pages through hashes, sets and sorted sets with their SCAN cursor.
+func (s *Scanner) inspectScan(client *redis.Client, k *models.KeyInspection, page string) error {
+	cursor, err := strconv.ParseUint(page, 10, 64)
+	if page == "" {
+		cursor, err = 0, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var values []string
+	switch k.Type {
+	case "hash":
+		k.Elements, err = client.HLen(s.ctx, k.Name).Result()
+		if err == nil {
+			values, cursor, err = client.HScan(s.ctx, k.Name, cursor, "", inspectPageSize).Result()
+		}
+		for i := 0; i+1 < len(values); i += 2 {
+			k.Page = append(k.Page, models.FormatElement(values[i])+" => "+models.FormatElement(values[i+1]))
+		}
+	case "zset":
+		k.Elements, err = client.ZCard(s.ctx, k.Name).Result()
+		if err == nil {
+			values, cursor, err = client.ZScan(s.ctx, k.Name, cursor, "", inspectPageSize).Result()
+		}
+		for i := 0; i+1 < len(values); i += 2 {
+			k.Page = append(k.Page, fmt.Sprintf("%s (%s)", models.FormatElement(values[i]), values[i+1]))
+		}
+	default:
+		k.Elements, err = client.SCard(s.ctx, k.Name).Result()
+		if err == nil {
+			values, cursor, err = client.SScan(s.ctx, k.Name, cursor, "", inspectPageSize).Result()
+		}
+		for _, value := range values {
+			k.Page = append(k.Page, models.FormatElement(value))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if cursor != 0 {
+		k.NextPage = strconv.FormatUint(cursor, 10)
+	}
+	return nil
+}
+
+func (s *Scanner) inspectStream(client *redis.Client, k *models.KeyInspection, page string) error {
+	start := "-"
+	if page != "" {
+		// The entry right after the last one shown
+		next, err := models.NextStreamID(page)
+		if err != nil {
+			return err
+		}
+		start = next
+	}
+	var err error
+	if k.Elements, err = client.XLen(s.ctx, k.Name).Result(); err != nil {
+		return err
+	}
+	messages, err := client.XRangeN(s.ctx, k.Name, start, "+", inspectPageSize).Result()
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		fields := make([]string, 0, len(msg.Values))
+		for field, value := range msg.Values {
+			fields = append(fields, models.FormatElement(field)+"="+models.FormatElement(fmt.Sprint(value)))
+		}
+		sort.Strings(fields)
+		k.Page = append(k.Page, msg.ID+" "+strings.Join(fields, " "))
+	}
+	if len(messages) == inspectPageSize {
+		k.NextPage = messages[len(messages)-1].ID
+	}
+	return nil
+}
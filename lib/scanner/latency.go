@@ -0,0 +1,192 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"redscout/models"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// latencyConfig remembers whether latency-monitor-threshold was set from the Latency tab, which
+// only offers it while the monitor is disabled, so it is disabled again on exit, and whether
+// the tab is shown.
+type latencyConfig struct {
+	mu      sync.Mutex
+	changed bool
+	watched bool
+}
+
+// WatchLatency starts or stops refreshing the latency monitor events, which only the Latency
+// Spikes tab shows. Starting reads them at once.
+func (s *Scanner) WatchLatency(on bool) {
+	s.latency.mu.Lock()
+	started := on && !s.latency.watched
+	s.latency.watched = on
+	s.latency.mu.Unlock()
+
+	if started {
+		go func() {
+			if err := s.FetchLatency(); err != nil {
+				log.Printf("Error fetching latency monitor events: %v", err)
+			}
+		}()
+	}
+}
+
+// LatencyUpdates refreshes the latency monitor events every RefreshInterval while they are
+// watched. A disabled monitor logs no new events, so it isn't read again until watched anew or
+// enabled. Errors are shown in the tab rather than logged on every tick.
+func (s *Scanner) LatencyUpdates() {
+	ticker := time.NewTicker(s.Config.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			s.latency.mu.Lock()
+			watched := s.latency.watched
+			s.latency.mu.Unlock()
+			if watched && s.State.Latency.Threshold != 0 {
+				_ = s.FetchLatency()
+			}
+		}
+	}
+}
+
+// FetchLatency reads latency-monitor-threshold, LATENCY LATEST and the LATENCY HISTORY of every
+// event into State.Latency, keeping the last LATENCY DOCTOR output.
+func (s *Scanner) FetchLatency() error {
+	if s.redis == nil {
+		return errOffline
+	}
+	s.muRedis.Lock()
+	defer s.muRedis.Unlock()
+
+	report := models.LatencyReport{At: time.Now(), Doctor: s.State.Latency.Doctor}
+	s.latency.mu.Lock()
+	report.ThresholdSet = s.latency.changed
+	s.latency.mu.Unlock()
+
+	err := s.fetchLatency(&report)
+	report.Err = err
+	s.State.Latency = report
+	s.State.Updates <- s.State
+	return err
+}
+
+// fetchLatency fills a latency report. Callers must hold muRedis.
+func (s *Scanner) fetchLatency(report *models.LatencyReport) error {
+	// Managed servers may refuse CONFIG GET, which leaves the threshold unknown
+	report.Threshold = -1
+	if threshold, err := s.latencyThreshold(); err == nil {
+		report.Threshold = time.Duration(threshold) * time.Millisecond
+	} else {
+		log.Printf("Error reading latency-monitor-threshold: %v", err)
+	}
+
+	reply, err := s.redis.Do(s.ctx, "latency", "latest").Slice()
+	if err != nil {
+		return fmt.Errorf("LATENCY LATEST failed: %w", err)
+	}
+	report.Events = models.ParseLatencyLatest(reply)
+	models.SortLatencyEvents(report.Events)
+
+	report.History = make(map[string][]models.LatencySample, len(report.Events))
+	for _, event := range report.Events {
+		reply, err := s.redis.Do(s.ctx, "latency", "history", event.Name).Slice()
+		if err != nil {
+			return fmt.Errorf("LATENCY HISTORY %s failed: %w", event.Name, err)
+		}
+		report.History[event.Name] = models.ParseLatencyHistory(reply)
+	}
+	return nil
+}
+
+// latencyThreshold reads latency-monitor-threshold in ms. Callers must hold muRedis.
+func (s *Scanner) latencyThreshold() (int64, error) {
+	config, err := s.redis.ConfigGet(s.ctx, "latency-monitor-threshold").Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read latency-monitor-threshold: %w", err)
+	}
+	threshold, err := strconv.ParseInt(config["latency-monitor-threshold"], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid latency-monitor-threshold %q: %w", config["latency-monitor-threshold"], err)
+	}
+	return threshold, nil
+}
+
+// FetchLatencyDoctor reads the LATENCY DOCTOR report into State.Latency.
+func (s *Scanner) FetchLatencyDoctor() (string, error) {
+	if s.redis == nil {
+		return "", errOffline
+	}
+	s.muRedis.Lock()
+	defer s.muRedis.Unlock()
+
+	doctor, err := s.redis.Do(s.ctx, "latency", "doctor").Text()
+	if err != nil {
+		return "", fmt.Errorf("LATENCY DOCTOR failed: %w", err)
+	}
+	s.State.Latency.Doctor = doctor
+	return doctor, nil
+}
+
+// EnableLatencyMonitor sets latency-monitor-threshold while the latency monitor is disabled,
+// until RedScout exits.
+func (s *Scanner) EnableLatencyMonitor(threshold time.Duration) error {
+	if s.redis == nil {
+		return errOffline
+	}
+	s.muRedis.Lock()
+	current, err := s.latencyThreshold()
+	if err == nil && current > 0 {
+		err = fmt.Errorf("latency-monitor-threshold is already %d ms", current)
+	}
+	if err == nil {
+		err = s.setLatencyThreshold(threshold)
+	}
+	s.muRedis.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.FetchLatency()
+}
+
+// setLatencyThreshold sets latency-monitor-threshold, to be disabled again on exit. Callers must
+// hold muRedis.
+func (s *Scanner) setLatencyThreshold(threshold time.Duration) error {
+	s.latency.mu.Lock()
+	defer s.latency.mu.Unlock()
+
+	value := strconv.FormatInt(threshold.Milliseconds(), 10)
+	if err := s.redis.ConfigSet(s.ctx, "latency-monitor-threshold", value).Err(); err != nil {
+		return fmt.Errorf("failed to set latency-monitor-threshold: %w", err)
+	}
+	s.latency.changed = true
+	log.Printf("Set latency-monitor-threshold to %s ms until exit", value)
+	return nil
+}
+
+// restoreLatencyMonitor disables the latency monitor again if it was enabled from the Latency tab.
+func (s *Scanner) restoreLatencyMonitor() {
+	s.latency.mu.Lock()
+	defer s.latency.mu.Unlock()
+	if !s.latency.changed {
+		return
+	}
+
+	// The scanner context may already be cancelled on exit
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.redis.ConfigSet(ctx, "latency-monitor-threshold", "0").Err(); err != nil {
+		log.Printf("Failed to disable latency-monitor-threshold again: %v", err)
+		return
+	}
+	s.latency.changed = false
+	log.Printf("Disabled latency-monitor-threshold again")
+}
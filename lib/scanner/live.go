@@ -0,0 +1,214 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"redscout/lib"
+	"redscout/models"
+	"time"
+)
+
+var errLive = errors.New("ops are already streaming in live mode")
+
+// liveEvent is an op seen while streaming, before it is written to the monitor log.
+type liveEvent struct {
+	args []string
+	db   int
+	at   time.Time
+}
+
+// LiveOps streams ops from the configured source until the scanner is closed. Every
+// MonitorDuration cycle it streams for DutyCycle percent of the time and pauses for the rest,
+// then refreshes the namespace stats and hot keys. Ops also feed the rolling per-second
+// counters shown as live rates.
+func (s *Scanner) LiveOps() {
+	on := s.Config.MonitorDuration * time.Duration(s.Config.DutyCycle) / 100
+	var off time.Duration
+	log.Printf("Live mode started, streaming %v of every %v", on, s.Config.MonitorDuration)
+
+	for s.ctx.Err() == nil {
+		if err := s.liveCycle(on); err != nil {
+			log.Printf("Live streaming error: %v", err)
+			s.updateStatus(fmt.Sprintf("Live streaming error: %v", err))
+			// Back off a whole cycle rather than retrying a failing connection in a loop
+			off = s.Config.MonitorDuration
+		} else {
+			off = s.Config.MonitorDuration - on
+		}
+
+		if err := s.ComputeNamespaceStats(); err != nil {
+			log.Printf("Error generating namespace stats: %v", err)
+		}
+		if err := s.ComputeHotKeysFromMonitorLog(); err != nil {
+			log.Printf("Error computing hot keys from monitor log: %v", err)
+		}
+
+		if off > 0 {
+			if s.State.Status == "Live: streaming" {
+				s.updateStatus("Live: paused")
+			}
+			select {
+			case <-s.ctx.Done():
+			case <-time.After(off):
+			}
+		}
+	}
+}
+
+// liveCycle streams ops for the given duration, or until the load guard pauses it. A cycle only
+// starts once the server load is below the guard thresholds.
+func (s *Scanner) liveCycle(duration time.Duration) error {
+	s.muRedis.Lock()
+	err := s.waitForLoad(s.ctx, s.redis)
+	s.muRedis.Unlock()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	ctxTimeout, cancel := context.WithTimeout(s.ctx, duration)
+	defer cancel()
+
+	events, err := s.liveEvents(ctxTimeout)
+	if err != nil {
+		return err
+	}
+	s.updateStatus("Live: streaming")
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	guardTicker := time.NewTicker(guardInterval)
+	defer guardTicker.Stop()
+
+	var pending []liveEvent
+	for {
+		select {
+		case <-guardTicker.C:
+			s.muRedis.Lock()
+			over := s.checkLoad(s.redis, false)
+			s.muRedis.Unlock()
+			if over {
+				s.State.TotalMonitorDuration += time.Since(start)
+				s.updateStatus("Live: paused by the load guard")
+				return s.flushLiveEvents(pending)
+			}
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			pending = append(pending, event)
+
+			namespace, err := s.kp.Namespace(s.recordKey(event.args[1], event.db), s.State.CurrentPrefix, true)
+			if err == nil {
+				s.State.Live.Add(namespace, time.Now())
+			}
+		case now := <-ticker.C:
+			s.State.Live.MarkActive(now)
+			if err := s.flushLiveEvents(pending); err != nil {
+				return err
+			}
+			pending = pending[:0]
+			s.State.Updates <- s.State
+		case <-ctxTimeout.Done():
+			s.State.TotalMonitorDuration += duration
+			return s.flushLiveEvents(pending)
+		}
+	}
+}
+
+// liveEvents starts streaming ops with at least a key from the configured source until ctx is done.
+func (s *Scanner) liveEvents(ctx context.Context) (<-chan liveEvent, error) {
+	events := make(chan liveEvent, 10000)
+	// The reader stops with ctx, so sends must too
+	send := func(event liveEvent) {
+		select {
+		case events <- event:
+		case <-ctx.Done():
+		}
+	}
+
+	if s.Config.OpsSource == models.OpsSourceKeyspace {
+		s.muRedis.Lock()
+		pubsub, err := s.subscribeKeyevents(ctx, notifyWriteFlags, "*")
+		s.muRedis.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		s.State.WriteOpsOnly = true
+		s.State.RemovalsTracked = true
+
+		go func() {
+			defer close(events)
+			defer s.restoreNotifications()
+			defer pubsub.Close()
+			ch := pubsub.Channel()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg, ok := <-ch:
+					if !ok {
+						return
+					}
+					db, event, err := parseKeyevent(msg.Channel)
+					if err != nil || !s.State.Analyzes(db) {
+						continue
+					}
+					send(liveEvent{args: []string{event, msg.Payload}, db: db, at: time.Now()})
+				}
+			}
+		}()
+		return events, nil
+	}
+
+	client, err := lib.RedisClientFromConfig(s.Config)
+	if err != nil {
+		return nil, err
+	}
+	lines := make(chan string, 10000)
+	monitor := client.Monitor(ctx, lines)
+	monitor.Start()
+
+	go func() {
+		defer close(events)
+		defer client.Close()
+		defer monitor.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case line := <-lines:
+				event, err := models.ParseMonitorLine(line)
+				if err != nil || len(event.Args) < 2 || !s.State.Analyzes(event.DB) || lib.IsOwnClient(event.Addr) {
+					continue
+				}
+				send(liveEvent{args: event.Args, db: event.DB, at: event.Time})
+			}
+		}
+	}()
+	return events, nil
+}
+
+func (s *Scanner) flushLiveEvents(events []liveEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	s.muMonitor.Lock()
+	defer s.muMonitor.Unlock()
+
+	// Stats computed in between leave the offset wherever reading stopped
+	if _, err := s.monitorFile.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to seek monitor file: %w", err)
+	}
+	for _, event := range events {
+		if err := s.writeMonitorRecord(event.args, event.db, event.at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"redscout/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Keyspace event classes enabled while tracking. E publishes on __keyevent@<db>__ channels; A
+// covers every write class, while x and e are only expired and evicted keys, which MONITOR
+// doesn't see.
+const (
+	notifyWriteFlags       = "EA"
+	notifyEvictExpireFlags = "Exe"
+)
+
+// notifyConfig remembers the notify-keyspace-events value found on the server while it is
+// temporarily widened, so it can be put back.
+type notifyConfig struct {
+	mu       sync.Mutex
+	original string
+	changed  bool
+}
+
+// TrackOps collects ops for the monitored duration from the configured source.
+func (s *Scanner) TrackOps() error {
+	if s.State.Live != nil {
+		return errLive
+	}
+	if s.Config.OpsSource == models.OpsSourceKeyspace {
+		return s.NotifyOps()
+	}
+	return s.MonitorOps()
+}
+
+// NotifyOps attributes writes to keys from keyspace event notifications, a cheaper alternative
+// to MONITOR that never sees reads. Notifications are enabled for the duration when the server
+// allows CONFIG SET, and restored afterwards.
+func (s *Scanner) NotifyOps() error {
+	if s.redis == nil {
+		s.updateStatus("Keyspace notifications are " + errOffline.Error())
+		return errOffline
+	}
+	s.muRedis.Lock()
+	defer s.muRedis.Unlock()
+	s.updateStatus("Tracking keyspace notifications")
+
+	pubsub, err := s.subscribeKeyevents(s.ctx, notifyWriteFlags, "*")
+	if err != nil {
+		return err
+	}
+	defer pubsub.Close()
+	defer s.restoreNotifications()
+
+	log.Printf("Keyspace notification tracking started for %v", s.Config.MonitorDuration)
+
+	s.State.WriteOpsOnly = true
+	s.State.RemovalsTracked = true
+	s.State.MonitorStartTime = time.Now()
+	s.State.MonitorDurationTotal = s.Config.MonitorDuration
+	s.State.MonitorProgress = 0
+
+	ctxTimeout, cancel := context.WithTimeout(s.ctx, s.Config.MonitorDuration)
+	defer cancel()
+
+	if _, err := s.monitorFile.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to seek monitor file: %w", err)
+	}
+
+	progressTicker := time.NewTicker(100 * time.Millisecond)
+	defer progressTicker.Stop()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				continue
+			}
+			db, event, err := parseKeyevent(msg.Channel)
+			if err != nil || !s.State.Analyzes(db) {
+				continue
+			}
+			_ = s.writeMonitorRecord([]string{event, msg.Payload}, db, time.Now())
+		case <-progressTicker.C:
+			elapsed := time.Since(s.State.MonitorStartTime)
+			s.State.MonitorProgress = min(float64(elapsed)/float64(s.Config.MonitorDuration)*100, 100)
+			s.State.Updates <- s.State
+		case <-ctxTimeout.Done():
+			s.State.MonitorProgress = 100
+			s.State.TotalMonitorDuration += s.Config.MonitorDuration
+			s.State.Updates <- s.State
+			s.updateStatus("Keyspace notification tracking completed")
+			log.Printf("Keyspace notification tracking completed")
+			return nil
+		}
+	}
+}
+
+// subscribeKeyevents enables the given event classes and subscribes to the given events, or
+// patterns of them, of every database under analysis. Callers must hold muRedis and restore
+// the notification config when done.
+func (s *Scanner) subscribeKeyevents(ctx context.Context, flags string, events ...string) (*redis.PubSub, error) {
+	if err := s.enableNotifications(flags); err != nil {
+		return nil, err
+	}
+
+	channels := make([]string, 0, len(s.State.DBs)*len(events))
+	for _, db := range s.State.DBs {
+		for _, event := range events {
+			channels = append(channels, fmt.Sprintf("__keyevent@%d__:%s", db, event))
+		}
+	}
+
+	pubsub := s.redis.PSubscribe(ctx, channels...)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to keyspace events: %w", err)
+	}
+	return pubsub, nil
+}
+
+// parseKeyevent splits a keyevent channel such as __keyevent@0__:set into its database and event.
+func parseKeyevent(channel string) (int, string, error) {
+	rest, ok := strings.CutPrefix(channel, "__keyevent@")
+	if !ok {
+		return 0, "", fmt.Errorf("not a keyevent channel: %s", channel)
+	}
+	dbStr, event, ok := strings.Cut(rest, "__:")
+	if !ok {
+		return 0, "", fmt.Errorf("not a keyevent channel: %s", channel)
+	}
+	db, err := strconv.Atoi(dbStr)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid db in channel %s: %w", channel, err)
+	}
+	return db, event, nil
+}
+
+// enableNotifications widens notify-keyspace-events to include the given flags. When CONFIG SET
+// is not allowed, whatever is already enabled is used. Callers must hold muRedis. notify.mu is
+// held throughout, so that a restore on exit waits for a change in flight.
+func (s *Scanner) enableNotifications(flags string) error {
+	s.notify.mu.Lock()
+	defer s.notify.mu.Unlock()
+
+	current, err := s.redis.ConfigGet(s.ctx, "notify-keyspace-events").Result()
+	if err != nil {
+		return fmt.Errorf("failed to read notify-keyspace-events: %w", err)
+	}
+	original := current["notify-keyspace-events"]
+
+	wanted := original
+	for _, flag := range flags {
+		if !strings.ContainsRune(wanted, flag) {
+			wanted += string(flag)
+		}
+	}
+	if wanted == original {
+		return nil
+	}
+
+	if err := s.redis.ConfigSet(s.ctx, "notify-keyspace-events", wanted).Err(); err != nil {
+		if strings.ContainsRune(original, 'E') {
+			log.Printf("Cannot set notify-keyspace-events (%v), using the enabled %q", err, original)
+			return nil
+		}
+		return fmt.Errorf("keyspace notifications are disabled and cannot be enabled: %w", err)
+	}
+
+	// Keep the value from before the first change, a later one would restore our own
+	if !s.notify.changed {
+		s.notify.original = original
+		s.notify.changed = true
+	}
+	log.Printf("Set notify-keyspace-events to %q, was %q", wanted, original)
+	return nil
+}
+
+// restoreNotifications puts back the notify-keyspace-events value found before tracking started.
+// It is safe to call more than once, and on exit while tracking is still running.
+func (s *Scanner) restoreNotifications() {
+	s.notify.mu.Lock()
+	defer s.notify.mu.Unlock()
+	if !s.notify.changed {
+		return
+	}
+
+	// The scanner context may already be cancelled on exit
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.redis.ConfigSet(ctx, "notify-keyspace-events", s.notify.original).Err(); err != nil {
+		log.Printf("Failed to restore notify-keyspace-events to %q: %v", s.notify.original, err)
+		return
+	}
+	s.notify.changed = false
+	log.Printf("Restored notify-keyspace-events to %q", s.notify.original)
+}
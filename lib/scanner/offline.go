@@ -0,0 +1,323 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"redscout/lib/aof"
+	"redscout/lib/rdb"
+	"redscout/lib/utils"
+	"redscout/models"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var errOffline = errors.New("not available in offline mode")
+
+// Keys read between progress updates while loading an RDB file, and commands while replaying an AOF
+const (
+	rdbProgressInterval = 10000
+	aofProgressInterval = 100000
+)
+
+// countingReader tracks how far into a file a reader has got, for progress reporting.
+type countingReader struct {
+	r    io.Reader
+	read int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += int64(n)
+	return n, err
+}
+
+// startOffline runs the analysis from local files only, without a Redis connection.
+func (s *Scanner) startOffline() {
+	if s.Config.RDBFile != "" {
+		if err := s.LoadRDB(); err != nil {
+			s.updateStatus(fmt.Sprintf("Error reading RDB file: %v", err))
+			return
+		}
+	}
+	if s.Config.AOFPath != "" {
+		if err := s.LoadAOF(); err != nil {
+			s.updateStatus(fmt.Sprintf("Error reading AOF: %v", err))
+			return
+		}
+	}
+	if s.Config.MonitorFile != "" {
+		if err := s.LoadMonitorCapture(); err != nil {
+			s.updateStatus(fmt.Sprintf("Error reading MONITOR capture: %v", err))
+			return
+		}
+	}
+
+	s.updateStatus("Computing statistics")
+
+	if err := s.ComputeNamespaceStats(); err != nil {
+		s.updateStatus(fmt.Sprintf("Error generating namespace stats: %v", err))
+	}
+	if err := s.ComputeBigKeysFromScanLog(); err != nil {
+		s.updateStatus(fmt.Sprintf("Error computing big keys from scan log: %v", err))
+		return
+	}
+	if err := s.ComputeHotKeysFromMonitorLog(); err != nil {
+		s.updateStatus(fmt.Sprintf("Error computing hot keys from monitor log: %v", err))
+		return
+	}
+	if err := s.ComputeLFUHotKeysFromScanLog(); err != nil {
+		s.updateStatus(fmt.Sprintf("Error computing LFU hot keys from scan log: %v", err))
+		return
+	}
+
+	s.recordHistory()
+
+	s.State.ScanComplete = true
+	s.updateStatus("Initial data load complete")
+}
+
+// LoadRDB reads every key of the RDB file into the scan log, as ScanMemory would for sampled
+// keys, and derives the server info the estimates rely on from the file itself.
+func (s *Scanner) LoadRDB() error {
+	s.updateStatus("Reading RDB file")
+	log.Printf("Loading RDB file %s", s.Config.RDBFile)
+
+	f, err := os.Open(s.Config.RDBFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	s.muScan.Lock()
+	defer s.muScan.Unlock()
+
+	if _, err := s.scanFile.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to seek scan file: %w", err)
+	}
+
+	counter := &countingReader{r: f}
+	parser := rdb.NewParser(counter)
+	keyspace := make(map[int]*models.KeyspaceInfo)
+	ttlSums := make(map[int]int64)
+	var createdAt time.Time
+	var sawIdle, sawFreq bool
+
+	err = parser.Parse(func(e rdb.Entry) error {
+		if !s.Config.AllDBs && e.DB != s.Config.RedisDB {
+			return nil
+		}
+
+		// Aux fields precede the keys, so the snapshot time is known by now
+		if createdAt.IsZero() {
+			createdAt = parser.CreatedAt()
+			if createdAt.IsZero() {
+				createdAt = stat.ModTime()
+			}
+		}
+
+		var ttl int64
+		if !e.ExpireAt.IsZero() {
+			ttl = int64(e.ExpireAt.Sub(createdAt).Seconds())
+			// Already expired when the snapshot was taken, Redis skips these on load
+			if ttl <= 0 {
+				return nil
+			}
+		}
+
+		sawIdle = sawIdle || e.Idle >= 0
+		sawFreq = sawFreq || e.Freq >= 0
+
+		record := models.ScanRecord{
+			Key:      e.Key,
+			Memory:   e.Memory,
+			TTL:      ttl,
+			Type:     e.Type,
+			Encoding: e.Encoding,
+			Elements: e.Elements,
+			Idle:     e.Idle,
+			Freq:     e.Freq,
+			DB:       e.DB,
+			Time:     createdAt.Unix(),
+		}
+		if _, err := s.scanFile.WriteString(record.String() + "\n"); err != nil {
+			return err
+		}
+
+		ks, ok := keyspace[e.DB]
+		if !ok {
+			ks = &models.KeyspaceInfo{}
+			keyspace[e.DB] = ks
+		}
+		ks.Keys++
+		if ttl > 0 {
+			ks.Expires++
+			ttlSums[e.DB] += ttl
+		}
+
+		s.State.ScannedKeys++
+		s.State.ScannedKeysByDB[e.DB]++
+		if s.State.ScannedKeys%rdbProgressInterval == 0 {
+			s.State.ScanProgress = min(float64(counter.read)/float64(stat.Size())*100, 100)
+			s.State.Updates <- s.State
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// A dump without keys has its aux fields read by now too
+	if createdAt.IsZero() {
+		createdAt = parser.CreatedAt()
+		if createdAt.IsZero() {
+			createdAt = stat.ModTime()
+		}
+	}
+	s.State.SnapshotTime = createdAt
+
+	s.State.RedisInfo = s.rdbInfo(parser, keyspace, ttlSums)
+	// The file only tells which kind of access history the server kept, not the exact policy
+	switch {
+	case sawFreq:
+		s.State.RedisInfo.Memory.MemoryPolicy = "lfu (from RDB)"
+	case sawIdle:
+		s.State.RedisInfo.Memory.MemoryPolicy = "lru (from RDB)"
+	}
+	s.State.DBs = s.databases()
+	s.State.DBLevel = s.Config.AllDBs
+	s.State.TotalKeysToScan = s.State.ScannedKeys
+	s.State.ScanProgress = 100
+
+	log.Printf("RDB file loaded; read %d keys from %v", s.State.ScannedKeys, s.State.DBs)
+	s.updateStatus("RDB file loaded")
+	return nil
+}
+
+// LoadAOF replays the write commands of the append-only file into the monitor log, as
+// MonitorOps would for live traffic. With timestamp annotations the replayed span stands in
+// for the monitored duration, so ops are reported per second and bucketed over time.
+func (s *Scanner) LoadAOF() error {
+	s.updateStatus("Replaying AOF")
+	log.Printf("Replaying AOF %s", s.Config.AOFPath)
+
+	files, err := aof.Files(s.Config.AOFPath)
+	if err != nil {
+		return err
+	}
+
+	s.muMonitor.Lock()
+	defer s.muMonitor.Unlock()
+
+	if _, err := s.monitorFile.Seek(0, io.SeekEnd); err != nil {
+		return fmt.Errorf("failed to seek monitor file: %w", err)
+	}
+
+	seen := make(map[int]bool)
+	var first, last time.Time
+	var commands int64
+
+	for i, file := range files {
+		err := aof.ReadFile(file, func(cmd aof.Command) error {
+			if !s.replays(cmd.DB) {
+				return nil
+			}
+			seen[cmd.DB] = true
+
+			if !cmd.Time.IsZero() {
+				if first.IsZero() {
+					first = cmd.Time
+				}
+				last = cmd.Time
+			}
+
+			commands++
+			if commands%aofProgressInterval == 0 {
+				s.State.MonitorProgress = float64(i) / float64(len(files)) * 100
+				s.State.Updates <- s.State
+			}
+			return s.writeMonitorRecord(cmd.Args, cmd.DB, cmd.Time)
+		})
+		// Redis accepts a truncated tail too, it is the last write before a crash
+		if errors.Is(err, aof.ErrTruncated) {
+			log.Printf("AOF file %s ends with a truncated command, ignoring it", file)
+		} else if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	s.addReplayedDBs(seen, "offline (AOF)")
+	if last.After(first) {
+		s.addReplayedSpan(first, last)
+		s.State.OpsTimeline = models.NewTimeline(first, last)
+	} else if commands > 0 {
+		s.State.UntimedOps = true
+	}
+	s.State.MonitorProgress = 100
+
+	log.Printf("AOF replayed; %d commands from %d files spanning %v", commands, len(files), last.Sub(first))
+	s.updateStatus("AOF replayed")
+	return nil
+}
+
+// addReplayedDBs adds the databases seen in replayed ops to those under analysis. Without a
+// server or RDB file there is no other server info, so a placeholder naming the source is used.
+func (s *Scanner) addReplayedDBs(seen map[int]bool, source string) {
+	if s.Config.Offline() && s.Config.RDBFile == "" && s.State.RedisInfo.Keyspace == nil {
+		info := models.NewRedisInfo()
+		info.Server.OS = source
+		s.State.RedisInfo = &info
+		s.State.DBLevel = s.Config.AllDBs
+	}
+
+	for db := range seen {
+		if !s.State.Analyzes(db) {
+			s.State.DBs = append(s.State.DBs, db)
+		}
+	}
+	if len(s.State.DBs) == 0 {
+		s.State.DBs = []int{s.Config.RedisDB}
+	}
+	sort.Ints(s.State.DBs)
+}
+
+// addReplayedSpan counts the time range of a replayed ops source as monitored. An AOF and a
+// MONITOR capture of the same period count it once.
+func (s *Scanner) addReplayedSpan(first, last time.Time) {
+	before := s.State.ReplayedSpans.Duration()
+	s.State.ReplayedSpans = s.State.ReplayedSpans.Add(first, last)
+	s.State.TotalMonitorDuration += s.State.ReplayedSpans.Duration() - before
+}
+
+// rdbInfo builds the server info shown in the header from the RDB aux fields and key counts.
+func (s *Scanner) rdbInfo(
+	parser *rdb.Parser,
+	keyspace map[int]*models.KeyspaceInfo,
+	ttlSums map[int]int64,
+) *models.RedisInfo {
+	info := models.NewRedisInfo()
+	info.Server.RedisVersion = parser.Aux["redis-ver"]
+	info.Server.OS = fmt.Sprintf("offline (RDB v%d)", parser.Version)
+	info.Server.ArchBits, _ = strconv.Atoi(parser.Aux["redis-bits"])
+
+	usedMem, _ := strconv.ParseInt(parser.Aux["used-mem"], 10, 64)
+	info.Memory.UsedMemory = usedMem
+	info.Memory.UsedMemoryHuman = utils.FormatBytes(usedMem)
+
+	for db, counts := range keyspace {
+		ks := *counts
+		if ks.Expires > 0 {
+			ks.AvgTTL = ttlSums[db] / ks.Expires
+		}
+		info.Keyspace[models.DBName(db)] = ks
+	}
+	return &info
+}
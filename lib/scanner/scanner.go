@@ -22,38 +22,59 @@ type Scanner struct {
 
 	State *models.State
 
-	redis   *redis.Client
-	muRedis sync.Mutex
+	redis     *redis.Client
+	dbClients map[int]*redis.Client
+	muRedis   sync.Mutex
+	notify    notifyConfig
+	latency   latencyConfig
+	guard     loadGuard
+
+	// Slow log polls don't take muRedis and are serialized by muSlowLog instead
+	slowLogFile slowLogFile
+	muSlowLog   sync.Mutex
 
 	monitorFile *os.File
 	muMonitor   sync.Mutex
 
 	scanFile *os.File
 	muScan   sync.Mutex
+
+	// Guards starting a bulk action while another one runs
+	muAction sync.Mutex
 }
 
 func NewScanner(cfg *models.Config) (*Scanner, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	client, err := lib.RedisClientFromConfig(cfg)
-	if err != nil {
-		return nil, err
+	var client *redis.Client
+	dbClients := make(map[int]*redis.Client)
+	if !cfg.Offline() {
+		var err error
+		client, err = lib.RedisClientFromConfig(cfg)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		dbClients[cfg.RedisDB] = client
 	}
 
 	logFile, err := os.CreateTemp(cfg.LogsDir, "redscout_log_")
 	if err != nil {
+		cancel()
 		return nil, fmt.Errorf("failed to create logFile file: %w", err)
 	}
 	log.SetOutput(logFile)
 
 	monitorFile, err := os.CreateTemp(cfg.LogsDir, "redscout_monitor_")
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
 	scanFile, err := os.CreateTemp(cfg.LogsDir, "redscout_scan_")
 
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 
@@ -65,8 +86,9 @@ func NewScanner(cfg *models.Config) (*Scanner, error) {
 		cancel:  cancel,
 		logFile: logFile,
 
-		redis:   client,
-		muRedis: sync.Mutex{},
+		redis:     client,
+		dbClients: dbClients,
+		muRedis:   sync.Mutex{},
 
 		State: models.NewState(),
 
@@ -77,23 +99,68 @@ func NewScanner(cfg *models.Config) (*Scanner, error) {
 		muScan:   sync.Mutex{},
 	}
 
+	// Offline analysis never connects, so it can't write either
+	s.State.ReadOnly = cfg.ReadOnly || cfg.Offline()
+	s.State.LoadGuard.Enabled = cfg.LoadGuard.Enabled && !cfg.Offline()
+
 	return s, nil
 }
 
+// Close puts back the server settings changed during the session and disconnects. The context
+// is cancelled first, so that running tracking can't change them again meanwhile.
 func (s *Scanner) Close() {
+	s.cancel()
 	if s.redis != nil {
-		_ = s.redis.Close()
+		s.restoreNotifications()
+		s.restoreLatencyMonitor()
+	}
+	for _, client := range s.dbClients {
+		_ = client.Close()
 	}
-	s.cancel()
 	log.Printf("Scanner closed")
 }
 
+// clientForDB returns a client bound to the given logical database, connecting on first use.
+// Callers must hold muRedis.
+func (s *Scanner) clientForDB(db int) (*redis.Client, error) {
+	if client, ok := s.dbClients[db]; ok {
+		return client, nil
+	}
+
+	client, err := lib.RedisClientForDB(s.Config, db)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	s.dbClients[db] = client
+	return client, nil
+}
+
+// databases returns the logical databases to analyze: every non-empty one with --all-dbs,
+// otherwise only the configured database.
+func (s *Scanner) databases() []int {
+	if !s.Config.AllDBs {
+		return []int{s.Config.RedisDB}
+	}
+
+	dbs := s.State.RedisInfo.NonEmptyDBs()
+	if len(dbs) == 0 {
+		return []int{s.Config.RedisDB}
+	}
+	return dbs
+}
+
 func (s *Scanner) updateStatus(status string) {
 	s.State.Status = status
 	s.State.Updates <- s.State
 }
 
 func (s *Scanner) Start() {
+	if s.Config.Offline() {
+		s.startOffline()
+		return
+	}
+
 	s.updateStatus("Fetching redis info")
 	err := s.FetchRedisInfo()
 	if err != nil {
@@ -105,8 +172,30 @@ func (s *Scanner) Start() {
 		log.Fatalf("unsupported Redis version: %s, must be at least v4.0.0", s.State.RedisInfo.Server.RedisVersion)
 	}
 
+	s.State.ACL = s.FetchACL()
+	log.Printf("Read-only client: %v, ACL: %s", s.State.ReadOnly, s.State.ACL)
+	if s.State.ACL.Err != nil {
+		log.Printf("Error checking ACL write permissions: %v", s.State.ACL.Err)
+	}
+
+	s.State.DBs = s.databases()
+	s.State.DBLevel = s.Config.AllDBs
+	log.Printf("Analyzing databases: %v", s.State.DBs)
+
+	if err := s.FetchEncodingThresholds(); err != nil {
+		log.Printf("Error fetching encoding thresholds, using defaults: %v", err)
+	}
+	if s.State.RedisInfo.Memory.IsLFU() {
+		if err := s.FetchLFUDecayTime(); err != nil {
+			log.Printf("Error fetching lfu-decay-time, using the default: %v", err)
+		}
+	}
+
 	//Redis stats info
 	go s.InfoUpdates()
+	go s.LatencyUpdates()
+	// Keep slow log entries before they rotate out, also while scanning
+	go s.SlowLogUpdates()
 
 	//Scan to analyse memory usage & keys
 	err = s.ScanMemory()
@@ -115,8 +204,16 @@ func (s *Scanner) Start() {
 		return
 	}
 
-	// Start Monitor to analyze operations
-	err = s.MonitorOps()
+	// Start Monitor to analyze operations, unless a captured stream stands in for it. Live
+	// mode streams ops once the initial load is complete instead.
+	switch {
+	case s.Config.Live:
+		s.State.Live = models.NewLiveCounters()
+	case s.Config.MonitorFile != "":
+		err = s.LoadMonitorCapture()
+	default:
+		err = s.TrackOps()
+	}
 	if err != nil {
 		s.updateStatus(fmt.Sprintf("Error monitoring operations: %v", err))
 		return
@@ -144,9 +241,20 @@ func (s *Scanner) Start() {
 		s.updateStatus(fmt.Sprintf("Error computing keys from monitor log: %v", err))
 		return
 	}
+	err = s.ComputeLFUHotKeysFromScanLog()
+	if err != nil {
+		s.updateStatus(fmt.Sprintf("Error computing LFU hot keys from scan log: %v", err))
+		return
+	}
+
+	s.recordHistory()
 
 	s.State.ScanComplete = true
 	s.updateStatus("Initial data load complete")
+
+	if s.Config.Live {
+		s.LiveOps()
+	}
 }
 
 func (s *Scanner) DrillDownNamespace(namespace string) {
@@ -160,6 +268,9 @@ func (s *Scanner) DrillDownNamespace(namespace string) {
 	log.Printf("Drilling down into namespace: %s with new prefix: %s\n", namespace, newPrefix)
 
 	s.State.CurrentPrefix = newPrefix
+	if s.State.Live != nil {
+		s.State.Live.Reset()
+	}
 	_ = s.ComputeNamespaceStats()
 }
 
@@ -177,5 +288,8 @@ func (s *Scanner) LevelUpNamespace() {
 	log.Printf("Going one level up from prefix: %s to new prefix: %s\n", currentPrefix, newPrefix)
 
 	s.State.CurrentPrefix = newPrefix
+	if s.State.Live != nil {
+		s.State.Live.Reset()
+	}
 	_ = s.ComputeNamespaceStats()
 }
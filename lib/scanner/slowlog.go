@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"redscout/models"
+)
+
+// slowLogFile is what the scanner knows of the slow log file between polls.
+type slowLogFile struct {
+	counted bool
+	// Entries in the file, counting those of earlier runs
+	lines int
+}
+
+// appendSlowLogFile appends new slow log entries, given newest first, to the slow log file as
+// JSON lines, oldest first. The file is trimmed back to the newest SlowLogMax entries once it
+// holds a tenth more. Callers must hold muRedis.
+func (s *Scanner) appendSlowLogFile(added models.SlowLogList) error {
+	path := s.Config.SlowLogFile
+	if path == "" || len(added) == 0 {
+		return nil
+	}
+	if !s.slowLogFile.counted {
+		lines, err := readLines(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		s.slowLogFile = slowLogFile{counted: true, lines: len(lines)}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create slow log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open slow log file: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for i := len(added) - 1; i >= 0; i-- {
+		if err := enc.Encode(added[i]); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	s.slowLogFile.lines += len(added)
+
+	if limit := s.Config.SlowLogMax; s.slowLogFile.lines > limit+limit/10 {
+		return s.trimSlowLogFile(limit)
+	}
+	return nil
+}
+
+// trimSlowLogFile keeps the last limit lines of the slow log file, replacing it at once.
+func (s *Scanner) trimSlowLogFile(limit int) error {
+	path := s.Config.SlowLogFile
+	lines, err := readLines(path)
+	if err != nil {
+		return err
+	}
+	lines = lines[max(0, len(lines)-limit):]
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	for _, line := range lines {
+		_, _ = w.WriteString(line)
+		_ = w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	s.slowLogFile.lines = len(lines)
+	return nil
+}
+
+// readLines reads the non-empty lines of a file.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -2,10 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"log"
+	"os"
+	"os/signal"
 	"redscout/lib/scanner"
 	"redscout/lib/ui/views"
 	"redscout/lib/ui/views/components"
 	"redscout/models"
+	"syscall"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
@@ -36,7 +40,7 @@ func NewAppUI(cfg models.Config) *AppUI {
 	ui := &AppUI{
 		config:            &cfg,
 		app:               app,
-		body:              views.NewBodyView(app),
+		body:              views.NewBodyView(app, &cfg),
 		headers:           views.NewHeaderView(),
 		initialisedLayout: false,
 	}
@@ -50,9 +54,7 @@ func (ui *AppUI) createDisclaimerScreen() {
 	disclaimer := tview.NewTextView().
 		SetDynamicColors(true).
 		SetTextAlign(tview.AlignCenter).
-		SetText("[red]DISCLAIMER[-]\n\n" +
-			"[yellow]RedScout will run the 'MONITOR' command on your Redis instance.[-]\n" +
-			"[yellow]This can impact Redis performance. Use with caution on production environments.[-]\n\n" +
+		SetText("[red]DISCLAIMER[-]\n\n" + opsWarning(ui.config) +
 			"[white]Do you want to continue?[white]\n\n" +
 			"[green]Y[-]es / [red]N[-]o")
 	disclaimer.SetBorder(true)
@@ -75,6 +77,19 @@ func (ui *AppUI) createDisclaimerScreen() {
 	})
 }
 
+func opsWarning(cfg *models.Config) string {
+	if cfg.OpsSource == models.OpsSourceKeyspace {
+		return "[yellow]RedScout will enable keyspace event notifications on your Redis instance while tracking ops,[-]\n" +
+			"[yellow]and restore 'notify-keyspace-events' afterwards. Every write publishes an event meanwhile.[-]\n\n"
+	}
+	if cfg.TrackRemovals {
+		return "[yellow]RedScout will run the 'MONITOR' command on your Redis instance, and enable expired and evicted[-]\n" +
+			"[yellow]keyspace events while it runs. This can impact Redis performance. Use with caution on production environments.[-]\n\n"
+	}
+	return "[yellow]RedScout will run the 'MONITOR' command on your Redis instance.[-]\n" +
+		"[yellow]This can impact Redis performance. Use with caution on production environments.[-]\n\n"
+}
+
 func (ui *AppUI) createErrorScreen(errorMsg string) {
 	flex := tview.NewFlex().SetDirection(tview.FlexRow)
 
@@ -153,14 +168,24 @@ func (ui *AppUI) createLoadingScreen() {
 					} else {
 						var progressInfo string
 
-						if ui.scanner.State.ScanProgress < 100 {
+						if ui.config.RDBFile != "" && ui.scanner.State.ScanProgress < 100 {
+							fileBar := components.CreateProgressBar(ui.scanner.State.ScanProgress, 100, 40)
+							progressInfo = fmt.Sprintf("\n\n[cyan]RDB Progress:[white]\n%s\n[white]%d keys read[-]", fileBar, ui.scanner.State.ScannedKeys)
+						} else if !ui.config.Offline() && ui.scanner.State.ScanProgress < 100 {
 							scannedKeys := int64(float64(ui.scanner.State.TotalKeysToScan) * ui.scanner.State.ScanProgress / 100)
 							scanBar := components.CreateProgressBar(ui.scanner.State.ScanProgress, 100, 40)
 							progressInfo = fmt.Sprintf("\n\n[cyan]Scan Progress:[white]\n%s\n[white]%d / %d keys[-]", scanBar, scannedKeys, ui.scanner.State.TotalKeysToScan)
+						} else if ui.config.AOFPath != "" || ui.config.MonitorFile != "" {
+							replayBar := components.CreateProgressBar(ui.scanner.State.MonitorProgress, 100, 40)
+							progressInfo = fmt.Sprintf("\n\n[cyan]Replay Progress:[white]\n%s", replayBar)
 						} else if ui.scanner.State.MonitorProgress < 100 {
 							elapsed := time.Duration(float64(ui.scanner.State.MonitorDurationTotal) * ui.scanner.State.MonitorProgress / 100)
 							monitorBar := components.CreateProgressBar(ui.scanner.State.MonitorProgress, 100, 40)
-							progressInfo = fmt.Sprintf("\n\n[cyan]Monitor Progress:[white]\n%s\n[white]%v / %v[-]", monitorBar, elapsed.Round(time.Second), ui.scanner.State.MonitorDurationTotal)
+							label := "Monitor"
+							if ui.config.OpsSource == models.OpsSourceKeyspace {
+								label = "Keyspace Events"
+							}
+							progressInfo = fmt.Sprintf("\n\n[cyan]%s Progress:[white]\n%s\n[white]%v / %v[-]", label, monitorBar, elapsed.Round(time.Second), ui.scanner.State.MonitorDurationTotal)
 						}
 
 						text = fmt.Sprintf("[yellow]Analysing Redis %c\n\n[white][-]%s", spinner[i%len(spinner)], progressInfo)
@@ -177,16 +202,21 @@ func (ui *AppUI) createMainScreen() {
 	flex := tview.NewFlex().SetDirection(tview.FlexRow)
 	flex.Clear()
 
-	flex.AddItem(ui.headers.HeaderFlex, 6, 0, false)
+	flex.AddItem(ui.headers.HeaderFlex, 7, 0, false)
 
 	ui.body.TabBar.SetBorder(true).SetBorderPadding(0, 0, 1, 0)
 	flex.AddItem(ui.body.TabBar, 3, 0, false)
 
 	flex.AddItem(ui.body.ContentFlex, 0, 1, true)
+	flex.AddItem(ui.body.Search, 1, 0, false)
 
 	ui.body.Shortcuts.SetBorder(true).SetBorderPadding(0, 0, 1, 0)
 	flex.AddItem(ui.body.Shortcuts, 3, 0, false)
 
+	ui.body.SetTreemapHandlers(ui.scanner.DrillDownNamespace, ui.scanner.LevelUpNamespace)
+	ui.body.SetActionHandlers(ui.runAction)
+	ui.body.SetLatencyHandlers(ui.fetchLatencyDoctor, ui.scanner.WatchLatency)
+
 	ui.app.SetInputCapture(ui.handleInput)
 	ui.app.SetRoot(flex, true)
 }
@@ -208,8 +238,28 @@ func (ui *AppUI) stateUpdateListener() {
 }
 
 func (ui *AppUI) Run() error {
-	ui.createDisclaimerScreen()
-	return ui.app.Run()
+	// Offline analysis never touches a server, so there is nothing to warn about
+	if ui.config.Offline() {
+		ui.start()
+	} else {
+		ui.createDisclaimerScreen()
+	}
+
+	// tview stops the app on Ctrl-C itself, other signals stop it here, so that the settings
+	// changed on the server are restored whichever way RedScout exits
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	go func() {
+		<-signals
+		ui.app.Stop()
+	}()
+
+	err := ui.app.Run()
+	if ui.scanner != nil {
+		ui.scanner.Close()
+	}
+	return err
 }
 
 func (ui *AppUI) update(ctx *models.State) {
@@ -220,19 +270,96 @@ func (ui *AppUI) update(ctx *models.State) {
 func (ui *AppUI) handleInput(e *tcell.EventKey) *tcell.EventKey {
 	changed := true
 
+	// Keys typed into a filter, search or the action form are not shortcuts
+	if ui.body.Filtering() || ui.body.Searching() || ui.body.Acting() {
+		return e
+	}
+
+	if ui.body.ConfirmingLatencyMonitor() {
+		if e.Rune() == 'y' || e.Rune() == 'Y' {
+			ui.enableLatencyMonitor()
+		} else {
+			ui.body.LatencyMessage("")
+		}
+		return nil
+	}
+
+	if e.Rune() == 'w' || e.Rune() == 'W' {
+		if ui.body.ActiveView() == views.TabLatency {
+			ui.body.ConfirmLatencyMonitor(ui.config.LatencyThreshold)
+			return nil
+		}
+		ui.openAction()
+		return nil
+	}
+
+	if ui.body.Listing() {
+		switch {
+		case e.Key() == tcell.KeyEscape, e.Key() == tcell.KeyBackspace, e.Key() == tcell.KeyBackspace2, e.Key() == tcell.KeyLeft:
+			ui.body.CloseKeyList()
+			return nil
+		case e.Key() == tcell.KeyEnter:
+			if name, db, ok := ui.body.SelectedKey(ui.scanner.State); ok {
+				ui.inspect(name, db, "")
+			}
+			return nil
+		case e.Rune() == '/':
+			ui.body.FocusKeyFilter()
+			return nil
+		}
+	}
+
+	if ui.body.Inspecting() {
+		k := ui.body.Inspection()
+		switch e.Key() {
+		case tcell.KeyEscape, tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyLeft:
+			ui.body.CloseInspector()
+			return nil
+		case tcell.KeyPgDn:
+			if k != nil && k.NextPage != "" {
+				ui.inspect(k.Name, k.DB, k.NextPage)
+			}
+			return nil
+		case tcell.KeyHome:
+			if k != nil {
+				ui.inspect(k.Name, k.DB, "")
+			}
+			return nil
+		}
+	}
+
+	if !ui.body.Listing() && !ui.body.Inspecting() {
+		switch {
+		case e.Rune() == '/':
+			if ui.body.StartSearch() {
+				return nil
+			}
+		// n and N open the Namespace tab, unless they jump between the matches of a search
+		case (e.Rune() == 'n' || e.Rune() == 'N') && ui.body.JumpingMatches():
+			ui.body.JumpMatch(e.Rune() == 'n')
+			return nil
+		case e.Key() == tcell.KeyEscape && ui.body.Searched():
+			ui.body.ClearSearch()
+			return nil
+		}
+	}
+
 	switch e.Key() {
 	case tcell.KeyEnter, tcell.KeyRight:
 		if ui.body.ActiveView() == "namespace" {
-			row, _ := ui.body.NamespaceTable().GetSelection()
-			if row <= 0 || row > len(ui.scanner.State.NamespaceStats) {
+			namespace, ok := ui.body.SelectedNamespace()
+			if !ok {
 				return nil
 			}
-			namespace := ui.scanner.State.NamespaceStats[row-1].Namespace
 
 			ui.scanner.DrillDownNamespace(namespace)
 
 			return nil
 		}
+		if name, db, ok := ui.body.SelectedKey(ui.scanner.State); ok && e.Key() == tcell.KeyEnter {
+			ui.inspect(name, db, "")
+			return nil
+		}
 	case tcell.KeyBackspace, tcell.KeyBackspace2, tcell.KeyLeft:
 		if ui.body.ActiveView() == "namespace" {
 			ui.scanner.LevelUpNamespace()
@@ -241,11 +368,14 @@ func (ui *AppUI) handleInput(e *tcell.EventKey) *tcell.EventKey {
 	}
 
 	switch e.Rune() {
-	case '1', '2', '3', '4', '5', '6', '7', '8', 't', 'T', 'n', 'N', 'l', 'L', 'b', 'B', 'h', 'H':
+	case '1', '2', '3', '4', '5', '6', '7', '8', '9', 't', 'T', 'n', 'N', 'l', 'L', 'b', 'B', 'h', 'H', 'e', 'E', 'a', 'A', 'y', 'Y', 'o', 'O', 'r', 'R', 'c', 'C', 'd', 'D', 'g', 'G', 'p', 'P':
 		ui.body.HandleInput(e.Rune(), ui.scanner.State)
+	case 'k', 'K':
+		if namespace, ok := ui.body.SelectedNamespace(); ok && ui.body.ActiveView() == "namespace" {
+			ui.listKeys(namespace)
+		}
 	case 'q', 'Q':
 		ui.app.Stop()
-		ui.scanner.Close()
 		return nil
 	case 's', 'S':
 		go func() {
@@ -256,7 +386,7 @@ func (ui *AppUI) handleInput(e *tcell.EventKey) *tcell.EventKey {
 		}()
 	case 'm', 'M':
 		go func() {
-			err := ui.scanner.MonitorOps()
+			err := ui.scanner.TrackOps()
 			if err == nil {
 				_ = ui.scanner.ComputeNamespaceStats()
 			}
@@ -269,3 +399,111 @@ func (ui *AppUI) handleInput(e *tcell.EventKey) *tcell.EventKey {
 	}
 	return e
 }
+
+// inspect loads a page of a key in the background and shows it in the key inspector.
+func (ui *AppUI) inspect(name string, db int, page string) {
+	go func() {
+		k, err := ui.scanner.InspectKey(name, db, page)
+		ui.app.QueueUpdateDraw(func() {
+			ui.body.ShowInspection(k, err)
+		})
+	}()
+}
+
+// listKeys reads the sampled keys of a namespace in the background and shows them in the key list.
+func (ui *AppUI) listKeys(namespace string) {
+	go func() {
+		keys, err := ui.scanner.SampledKeys(namespace)
+		if err != nil {
+			log.Printf("Error listing keys of %s: %v", namespace, err)
+			return
+		}
+		ui.app.QueueUpdateDraw(func() {
+			ui.body.ShowKeyList(namespace, keys)
+		})
+	}()
+}
+
+// openAction opens the expire or unlink form for the selected key or namespace.
+func (ui *AppUI) openAction() {
+	target, ok := ui.body.ActionTarget(ui.scanner.State)
+	if !ok {
+		return
+	}
+	var disabled, pattern string
+	switch {
+	case ui.config.Offline():
+		disabled = "Offline analysis can't write to Redis"
+	case !ui.config.AllowWrites:
+		disabled = "Writes are disabled, start RedScout with --read-only=false --allow-writes to expire or unlink keys"
+	case target.Namespace:
+		action, err := ui.scanner.NamespaceAction(target.Name, models.ActionExpire, 0, true)
+		if err != nil {
+			disabled = err.Error()
+		}
+		target.DB, pattern = action.DB, action.Pattern
+	}
+	ui.body.OpenAction(target, pattern, disabled)
+}
+
+// runAction writes to a key, or starts a bulk action on a namespace, in the background.
+func (ui *AppUI) runAction(req components.ActionRequest) {
+	if req.Target.Namespace {
+		action, err := ui.scanner.NamespaceAction(req.Target.Name, req.Kind, req.TTL, req.DryRun)
+		if err != nil {
+			ui.body.ActionMessage(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+			return
+		}
+		go func() {
+			if err := ui.scanner.RunBulkAction(action); err != nil {
+				log.Printf("Error running bulk %s of %s: %v", action.Kind, action.Pattern, err)
+				ui.app.QueueUpdateDraw(func() {
+					ui.body.ActionMessage(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+				})
+			}
+		}()
+		return
+	}
+
+	ui.body.ActionMessage("Running...")
+	go func() {
+		var err error
+		if req.Kind == models.ActionExpire {
+			err = ui.scanner.ExpireKey(req.Target.Name, req.Target.DB, req.TTL)
+		} else {
+			err = ui.scanner.UnlinkKey(req.Target.Name, req.Target.DB)
+		}
+		text := fmt.Sprintf("[green]Done, %s of the key is in the audit log[-]", req.Kind)
+		if err != nil {
+			text = fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error()))
+		}
+		ui.app.QueueUpdateDraw(func() {
+			ui.body.ActionMessage(text)
+		})
+	}()
+}
+
+// fetchLatencyDoctor asks LATENCY DOCTOR in the background and shows its report.
+func (ui *AppUI) fetchLatencyDoctor() {
+	go func() {
+		text, err := ui.scanner.FetchLatencyDoctor()
+		ui.app.QueueUpdateDraw(func() {
+			ui.body.LatencyDoctor(text, err)
+		})
+	}()
+}
+
+// enableLatencyMonitor sets latency-monitor-threshold in the background, once confirmed.
+func (ui *AppUI) enableLatencyMonitor() {
+	ui.body.LatencyMessage("[yellow]Setting latency-monitor-threshold...[-]")
+	go func() {
+		err := ui.scanner.EnableLatencyMonitor(ui.config.LatencyThreshold)
+		text := ""
+		if err != nil {
+			text = fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error()))
+		}
+		ui.app.QueueUpdateDraw(func() {
+			ui.body.LatencyMessage(text)
+		})
+	}()
+}
@@ -1,6 +1,10 @@
 package views
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"redscout/lib/ui/views/components"
 	"redscout/models"
@@ -13,6 +17,12 @@ const (
 	TabSlowLog   Tab = "slowlog"
 	TabBigKeys   Tab = "bigkeys"
 	TabHotKeys   Tab = "hotkeys"
+	TabEncoding  Tab = "encoding"
+	TabAccess    Tab = "access"
+	TabHistory   Tab = "history"
+	TabAnomalies Tab = "anomalies"
+	TabTreemap   Tab = "treemap"
+	TabLatency   Tab = "latency"
 )
 
 type BodyView struct {
@@ -25,11 +35,48 @@ type BodyView struct {
 	app         *tview.Application
 	bigKeyTable *tview.Table
 	hotKeyTable *tview.Table
+
+	encodingTable *tview.Table
+	accessTable   *tview.Table
+	historyTable  *tview.Table
+	anomalyTable  *tview.Table
+	treemap       *components.Treemap
+	latency       *components.LatencyView
+	fetchDoctor   func()
+	watchLatency  func(on bool)
+
+	// Search of each table tab, applied on every update until cleared, and the rows it kept
+	Search     *tview.InputField
+	searches   map[Tab]string
+	state      *models.State
+	namespaces models.NamespaceMetricList
+	bigKeys    models.BigKeyList
+	hotKeys    models.HotKeyList
+
+	// Slow log order picked with 1-4, and the newest slow log entry shown in its tab
+	slowLogSort   string
+	slowLogSeenID int64
+
+	// Sampled keys of a namespace, shown over the namespace tab
+	keyList *components.KeyList
+	listing bool
+
+	// Key inspector, shown over the active tab or key list
+	inspector  *tview.TextView
+	inspection *models.KeyInspection
+	inspecting bool
+
+	// Expire or unlink form, shown over whatever it was opened from
+	actionForm *components.ActionForm
+	acting     bool
+
+	config *models.Config
 }
 
-func NewBodyView(app *tview.Application) *BodyView {
+func NewBodyView(app *tview.Application, cfg *models.Config) *BodyView {
 	view := &BodyView{
 		app:         app,
+		config:      cfg,
 		Shortcuts:   newShortcuts(),
 		ContentFlex: newContentFlex(),
 		namespace:   components.NewNamespace(),
@@ -38,6 +85,19 @@ func NewBodyView(app *tview.Application) *BodyView {
 		TabBar:      newTabBar(),
 		bigKeyTable: components.NewBigKeyTable(),
 		hotKeyTable: components.NewHotKeyTable(),
+
+		encodingTable: components.NewEncodingTable(),
+		accessTable:   components.NewAccessTable(),
+		historyTable:  components.NewHistoryTable(),
+		anomalyTable:  components.NewAnomaliesTable(),
+		treemap:       components.NewTreemap(),
+		latency:       components.NewLatencyView(cfg.ReadOnly),
+		Search:        newSearch(),
+		searches:      make(map[Tab]string),
+		keyList:       components.NewKeyList(),
+		inspector:     components.NewKeyInspector(),
+		actionForm:    components.NewActionForm(),
+		slowLogSeenID: -1,
 	}
 	view.SetActiveView(TabNamespace)
 	return view
@@ -52,6 +112,7 @@ var namespaceSortKeyMap = map[rune]string{
 	'6': "Set",
 	'7': "Del",
 	'8': "Total Ops",
+	'9': "Max Elems",
 }
 
 var slowLogSortKeyMap = map[rune]string{
@@ -71,6 +132,12 @@ func newContentFlex() *tview.Flex {
 	return tview.NewFlex().SetDirection(tview.FlexColumn)
 }
 
+func newSearch() *tview.InputField {
+	return tview.NewInputField().
+		SetLabel(" / ").
+		SetFieldBackgroundColor(tcell.ColorBlack)
+}
+
 func newTabBar() *tview.TextView {
 	tabBar := tview.NewTextView().
 		SetDynamicColors(true).
@@ -78,87 +145,179 @@ func newTabBar() *tview.TextView {
 	return tabBar
 }
 
+// tabOrder lists the tabs in tab bar and toggle order, with their labels
+var tabOrder = []Tab{TabNamespace, TabSlowLog, TabLatency, TabBigKeys, TabHotKeys, TabEncoding, TabAccess, TabTreemap, TabHistory, TabAnomalies}
+
+var tabLabels = map[Tab]string{
+	TabNamespace: "[[yellow]N[-]]amespace",
+	TabSlowLog:   "Slow [[yellow]L[-]]og",
+	TabBigKeys:   "[[yellow]B[-]]ig Keys",
+	TabHotKeys:   "[[yellow]H[-]]ot Keys",
+	TabEncoding:  "[[yellow]E[-]]ncoding",
+	TabAccess:    "[[yellow]A[-]]ccess",
+	TabTreemap:   "T[[yellow]R[-]]eemap",
+	TabLatency:   "Latency S[[yellow]P[-]]ikes",
+	TabHistory:   "Histor[[yellow]Y[-]]",
+	TabAnomalies: "An[[yellow]O[-]]malies",
+}
+
+// renderTabBar highlights the active tab, and counts slow log entries not seen yet on its tab.
+func renderTabBar(active Tab, newSlowLogs int) string {
+	text := ""
+	for i, tab := range tabOrder {
+		if i > 0 {
+			text += `[white:black][-:-]`
+		}
+		label := tabLabels[tab]
+		if tab == TabSlowLog && newSlowLogs > 0 {
+			label += fmt.Sprintf(" [yellow]+%d[white]", newSlowLogs)
+		}
+		if tab == active {
+			text += `[::b][white:teal] ` + label + ` [white::-]`
+		} else {
+			text += `[white] ` + label + ` [-]`
+		}
+	}
+	return text
+}
+
+// newSlowLogs counts the slow log entries added since the slow log tab was last shown, marking
+// them seen while it is.
+func (b *BodyView) newSlowLogs() int {
+	if b.state == nil {
+		return 0
+	}
+	slowLogs := b.state.SlowLog.Entries()
+	if len(slowLogs) == 0 {
+		return 0
+	}
+	if b.activeView == TabSlowLog {
+		b.slowLogSeenID = slowLogs[0].ID
+		return 0
+	}
+	return slowLogs.NewSince(b.slowLogSeenID)
+}
+
 func (b *BodyView) SetActiveView(view Tab) {
 	b.activeView = view
+	b.inspecting = false
+	b.listing = false
+	b.acting = false
+	b.TabBar.SetText(renderTabBar(view, b.newSlowLogs()))
+	b.Search.SetText(b.searches[view])
+	if b.watchLatency != nil {
+		b.watchLatency(view == TabLatency)
+	}
+	// Capturing the mouse stops the terminal selecting text, so only the treemap, which is
+	// clicked, has it
+	b.app.EnableMouse(view == TabTreemap)
 
 	switch view {
 	case TabNamespace:
 		b.ContentFlex.Clear().AddItem(b.namespace.Flex, 0, 2, true)
 		b.Shortcuts.SetText(components.StatsHeader)
-		b.TabBar.SetText(
-			`[::b][white:teal][[yellow]N[-]]amespace [white::-]` +
-				`[white:black][-:-]` +
-				`[white] Slow [[yellow]L[-]]og [-]` +
-				`[white:black][-:-]` +
-				`[white] [[yellow]B[-]]ig Keys [-]` +
-				`[white:black][-:-]` +
-				`[white] [[yellow]H[-]]ot Keys [-]`,
-		)
 		b.namespace.Table.Select(1, 0)
 		b.app.SetFocus(b.namespace.Table)
 	case TabSlowLog:
-		b.ContentFlex.Clear().AddItem(b.slowLog.Table, 0, 2, true)
-		b.Shortcuts.SetText(components.SlowLogHeader)
-		b.TabBar.SetText(
-			`[white][[yellow]N[-]]amespace [-]` +
-				`[white:black][-:-]` +
-				`[::b][white:teal] Slow [[yellow]L[-]]og [white::-]` +
-				`[white:black][-:-]` +
-				`[white] [[yellow]B[-]]ig Keys [-]` +
-				`[white:black][-:-]` +
-				`[white] [[yellow]H[-]]ot Keys [-]`,
-		)
+		b.ContentFlex.Clear().AddItem(b.slowLog.Flex, 0, 2, true)
+		if b.slowLog.Grouped {
+			b.Shortcuts.SetText(components.SlowLogGroupHeader)
+		} else {
+			b.Shortcuts.SetText(components.SlowLogHeader)
+		}
 		b.slowLog.Table.Select(1, 0)
 		b.app.SetFocus(b.slowLog.Table)
 	case TabBigKeys:
 		b.ContentFlex.Clear().AddItem(b.bigKeyTable, 0, 2, true)
 		b.Shortcuts.SetText(components.BigKeysShortcutsText)
-		b.TabBar.SetText(
-			`[white][[yellow]N[-]]amespace [-]` +
-				`[white:black][-:-]` +
-				`[white] Slow [[yellow]L[-]]og [-]` +
-				`[white:black][-:-]` +
-				`[::b][white:teal] [[yellow]B[-]]ig Keys [white::-]` +
-				`[white:black][-:-]` +
-				`[white] [[yellow]H[-]]ot Keys [-]`,
-		)
 		b.bigKeyTable.Select(1, 0)
 		b.app.SetFocus(b.bigKeyTable)
 	case TabHotKeys:
 		b.ContentFlex.Clear().AddItem(b.hotKeyTable, 0, 2, true)
 		b.Shortcuts.SetText(components.HotKeysShortcutsText)
-		b.TabBar.SetText(
-			`[white][[yellow]N[-]]amespace [-]` +
-				`[white:black][-:-]` +
-				`[white] Slow [[yellow]L[-]]og [-]` +
-				`[white:black][-:-]` +
-				`[white] [[yellow]B[-]]ig Keys [-]` +
-				`[white:black][-:-]` +
-				`[::b][white:teal] [[yellow]H[-]]ot Keys [white::-]`,
-		)
 		b.hotKeyTable.Select(1, 0)
 		b.app.SetFocus(b.hotKeyTable)
+	case TabEncoding:
+		b.ContentFlex.Clear().AddItem(b.encodingTable, 0, 2, true)
+		b.Shortcuts.SetText(components.EncodingShortcutsText)
+		b.encodingTable.Select(1, 0)
+		b.app.SetFocus(b.encodingTable)
+	case TabAccess:
+		b.ContentFlex.Clear().AddItem(b.accessTable, 0, 2, true)
+		b.Shortcuts.SetText(components.AccessShortcutsText)
+		b.accessTable.Select(1, 0)
+		b.app.SetFocus(b.accessTable)
+	case TabHistory:
+		b.ContentFlex.Clear().AddItem(b.historyTable, 0, 2, true)
+		b.Shortcuts.SetText(components.HistoryShortcutsText)
+		b.historyTable.Select(1, 0)
+		b.app.SetFocus(b.historyTable)
+	case TabTreemap:
+		b.ContentFlex.Clear().AddItem(b.treemap, 0, 2, true)
+		b.Shortcuts.SetText(components.TreemapShortcutsText)
+		b.app.SetFocus(b.treemap)
+	case TabAnomalies:
+		b.ContentFlex.Clear().AddItem(b.anomalyTable, 0, 2, true)
+		b.Shortcuts.SetText(components.AnomaliesShortcutsText)
+		b.anomalyTable.Select(1, 0)
+		b.app.SetFocus(b.anomalyTable)
+	case TabLatency:
+		b.ContentFlex.Clear().AddItem(b.latency.Flex, 0, 2, true)
+		b.Shortcuts.SetText(components.LatencyShortcutsText)
+		b.latency.Table.Select(1, 0)
+		b.app.SetFocus(b.latency.Table)
 	}
 }
 
 func (b *BodyView) ToggleView() {
-	switch b.activeView {
-	case TabNamespace:
-		b.SetActiveView(TabSlowLog)
-	case TabSlowLog:
-		b.SetActiveView(TabBigKeys)
-	case TabBigKeys:
-		b.SetActiveView(TabHotKeys)
-	default:
-		b.SetActiveView(TabNamespace)
+	for i, tab := range tabOrder {
+		if tab == b.activeView {
+			b.SetActiveView(tabOrder[(i+1)%len(tabOrder)])
+			return
+		}
 	}
+	b.SetActiveView(TabNamespace)
 }
 
 func (b *BodyView) Update(data *models.State) {
-	b.slowLog.Update(data.SlowLogs)
-	b.namespace.Update(data.CurrentPrefix, data.NamespaceStats)
-	components.UpdateBigKeyTable(b.bigKeyTable, data.BigKeys)
-	components.UpdateHotKeyTable(b.hotKeyTable, data.HotKeys)
+	b.state = data
+	b.namespaces, b.bigKeys, b.hotKeys = data.NamespaceStats, data.BigKeys, data.HotKeys
+	slowLogs := data.SlowLog.Entries()
+	if text := b.searches[TabNamespace]; text != "" {
+		b.namespaces = b.namespaces.Search(models.CompileSearch(text))
+	}
+	if text := b.searches[TabSlowLog]; text != "" && slowLogs != nil {
+		slowLogs = slowLogs.Search(models.CompileSearch(text))
+	}
+	// The scanner replaces the slow log as it polls, so the picked order is applied to a copy
+	if b.slowLogSort != "" && slowLogs != nil {
+		slowLogs = append(models.SlowLogList{}, slowLogs...)
+		slowLogs.Sort(b.slowLogSort)
+	}
+	if text := b.searches[TabBigKeys]; text != "" {
+		b.bigKeys = b.bigKeys.Search(models.CompileSearch(text))
+	}
+	if text := b.searches[TabHotKeys]; text != "" {
+		b.hotKeys = b.hotKeys.Search(models.CompileSearch(text))
+	}
+
+	// The namespace table and its detail pane only see the namespaces kept by the search
+	shown := *data
+	shown.NamespaceStats = b.namespaces
+
+	b.TabBar.SetText(renderTabBar(b.activeView, b.newSlowLogs()))
+	b.slowLog.SetPolls(data.SlowLog.Polls(), len(data.SlowLog.Entries()), b.config.SlowLogInterval)
+	b.slowLog.Update(slowLogs)
+	b.latency.Update(data.Latency, b.config.LatencyThreshold)
+	b.namespace.Update(&shown)
+	components.UpdateBigKeyTable(b.bigKeyTable, b.bigKeys)
+	components.UpdateHotKeyTable(b.hotKeyTable, b.hotKeys, data.WriteOpsOnly, data.OpsAreCounts())
+	components.UpdateEncodingTable(b.encodingTable, data.NamespaceStats, data.EncodingThresholds)
+	components.UpdateAccessTable(b.accessTable, data.NamespaceStats, data.RedisInfo.Memory.IsLFU(), b.config.ColdAfter)
+	components.UpdateHistoryTable(b.historyTable, data.HistoryGrowers)
+	b.treemap.Update(data.NamespaceStats)
+	components.UpdateAnomaliesTable(b.anomalyTable, data.Anomalies)
+	b.actionForm.UpdateProgress(data.Action)
 }
 
 func (b *BodyView) HandleInput(inp rune, state *models.State) {
@@ -182,22 +341,79 @@ func (b *BodyView) HandleInput(inp rune, state *models.State) {
 		b.SetActiveView(TabSlowLog)
 		return
 	}
-	if inp > '8' || inp < '1' {
+	if inp == 'E' || inp == 'e' {
+		b.SetActiveView(TabEncoding)
+		return
+	}
+	if inp == 'A' || inp == 'a' {
+		b.SetActiveView(TabAccess)
+		return
+	}
+	if inp == 'Y' || inp == 'y' {
+		b.SetActiveView(TabHistory)
+		return
+	}
+	if inp == 'R' || inp == 'r' {
+		b.SetActiveView(TabTreemap)
+		return
+	}
+	if inp == 'P' || inp == 'p' {
+		b.SetActiveView(TabLatency)
+		return
+	}
+	if (inp == 'D' || inp == 'd') && b.activeView == TabLatency {
+		if b.latency.ToggleDoctor() && b.fetchDoctor != nil {
+			b.fetchDoctor()
+		}
+		return
+	}
+	if (inp == 'C' || inp == 'c') && b.activeView == TabTreemap {
+		b.treemap.ToggleColor()
+		return
+	}
+	if inp == 'O' || inp == 'o' {
+		b.SetActiveView(TabAnomalies)
+		return
+	}
+	if (inp == 'G' || inp == 'g') && b.activeView == TabSlowLog {
+		b.Shortcuts.SetText(b.slowLog.ToggleGrouped())
+		b.Update(state)
+		return
+	}
+	if inp == 'D' || inp == 'd' {
+		if b.activeView == TabNamespace {
+			b.namespace.ToggleDetail()
+		}
+		return
+	}
+	if inp > '9' || inp < '1' {
 		return
 	}
 	key := ""
+	if b.Listing() {
+		if key = components.KeyListSortKeyMap[inp]; key != "" {
+			b.keyList.Sort(key)
+		}
+		return
+	}
 	if b.activeView == TabNamespace {
 		key = namespaceSortKeyMap[inp]
 		if key == "" {
 			return
 		}
 		state.NamespaceStats.Sort(key)
+	} else if b.activeView == TabSlowLog && b.slowLog.Grouped {
+		key = components.SlowLogGroupSortKeyMap[inp]
+		if key == "" {
+			return
+		}
+		b.slowLog.SortGroups(key)
 	} else if b.activeView == TabSlowLog {
 		key = slowLogSortKeyMap[inp]
 		if key == "" {
 			return
 		}
-		state.SlowLogs.Sort(key)
+		b.slowLogSort = key
 	}
 	b.Update(state)
 }
@@ -209,3 +425,285 @@ func (b *BodyView) ActiveView() Tab {
 func (b *BodyView) NamespaceTable() *tview.Table {
 	return b.namespace.Table
 }
+
+// SelectedKey returns the key selected in the key list, or in the Big Keys or Hot Keys tab.
+func (b *BodyView) SelectedKey(state *models.State) (string, int, bool) {
+	if b.listing {
+		k, ok := b.keyList.Selected()
+		return k.Name, k.DB, ok
+	}
+	switch b.activeView {
+	case TabBigKeys:
+		row, _ := b.bigKeyTable.GetSelection()
+		if row > 0 && row <= len(b.bigKeys) {
+			return b.bigKeys[row-1].Name, b.bigKeys[row-1].DB, true
+		}
+	case TabHotKeys:
+		row, _ := b.hotKeyTable.GetSelection()
+		if row > 0 && row <= len(b.hotKeys) {
+			return b.hotKeys[row-1].Name, b.hotKeys[row-1].DB, true
+		}
+	}
+	return "", 0, false
+}
+
+// SelectedNamespace returns the namespace selected in the namespace tab.
+func (b *BodyView) SelectedNamespace() (string, bool) {
+	row, _ := b.namespace.Table.GetSelection()
+	if row <= 0 || row > len(b.namespaces) {
+		return "", false
+	}
+	return b.namespaces[row-1].Namespace, true
+}
+
+// searchTable returns the table of the active tab when it can be searched.
+func (b *BodyView) searchTable() *tview.Table {
+	switch b.activeView {
+	case TabNamespace:
+		return b.namespace.Table
+	case TabSlowLog:
+		return b.slowLog.Table
+	case TabBigKeys:
+		return b.bigKeyTable
+	case TabHotKeys:
+		return b.hotKeyTable
+	}
+	return nil
+}
+
+// StartSearch moves the focus to the search of the active tab. Enter applies it, as a regexp
+// or else a substring, an empty search clears it and Esc leaves it as it was.
+func (b *BodyView) StartSearch() bool {
+	table := b.searchTable()
+	if table == nil {
+		return false
+	}
+	view := b.activeView
+	b.Search.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			b.searches[view] = b.Search.GetText()
+			if b.state != nil {
+				b.Update(b.state)
+			}
+			table.Select(1, 0)
+		}
+		b.Search.SetText(b.searches[view])
+		b.app.SetFocus(table)
+	})
+	b.app.SetFocus(b.Search)
+	return true
+}
+
+// Searching reports whether the search has the focus, so keys go to it.
+func (b *BodyView) Searching() bool {
+	return b.Search.HasFocus()
+}
+
+// Searched reports whether the active tab's rows are narrowed by a search.
+func (b *BodyView) Searched() bool {
+	return b.searchTable() != nil && b.searches[b.activeView] != ""
+}
+
+// JumpingMatches reports whether n and N jump between matches rather than open the Namespace
+// tab: while a search is applied to the active tab and its table has the focus.
+func (b *BodyView) JumpingMatches() bool {
+	return b.Searched() && b.app.GetFocus() == b.searchTable()
+}
+
+// ClearSearch shows every row of the active tab again.
+func (b *BodyView) ClearSearch() {
+	delete(b.searches, b.activeView)
+	b.Search.SetText("")
+	if b.state != nil {
+		b.Update(b.state)
+	}
+}
+
+// JumpMatch selects the next, or previous, matching row of the active tab, wrapping around.
+func (b *BodyView) JumpMatch(forward bool) {
+	table := b.searchTable()
+	if table == nil || table.GetRowCount() <= 1 {
+		return
+	}
+	matches := table.GetRowCount() - 1
+	row, _ := table.GetSelection()
+	step := 1
+	if !forward {
+		step = matches - 1
+	}
+	table.Select((row-1+step)%matches+1, 0)
+}
+
+// ShowInspection opens the key inspector over the active tab, or shows why it couldn't be.
+func (b *BodyView) ShowInspection(k *models.KeyInspection, err error) {
+	b.inspection = k
+	if err != nil {
+		b.inspection = nil
+		b.inspector.SetText(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+	} else {
+		b.inspector.SetText(components.KeyInspectorText(k))
+	}
+	b.inspector.ScrollToBeginning()
+	b.inspecting = true
+	b.ContentFlex.Clear().AddItem(b.inspector, 0, 2, true)
+	b.Shortcuts.SetText(components.KeyInspectorShortcutsText)
+	b.app.SetFocus(b.inspector)
+}
+
+// CloseInspector goes back to the key list or tab the key was inspected from.
+func (b *BodyView) CloseInspector() {
+	b.inspection = nil
+	b.inspecting = false
+	if b.listing {
+		b.showKeyList()
+		return
+	}
+	b.SetActiveView(b.activeView)
+}
+
+// ShowKeyList opens the sampled keys of a namespace over the namespace tab.
+func (b *BodyView) ShowKeyList(namespace string, keys models.SampledKeyList) {
+	b.keyList.SetKeys(namespace, keys)
+	b.listing = true
+	b.showKeyList()
+}
+
+func (b *BodyView) showKeyList() {
+	b.ContentFlex.Clear().AddItem(b.keyList.Flex, 0, 2, true)
+	b.Shortcuts.SetText(components.KeyListShortcutsText)
+	b.app.SetFocus(b.keyList.Table)
+}
+
+// CloseKeyList goes back to the namespace tab.
+func (b *BodyView) CloseKeyList() {
+	b.SetActiveView(TabNamespace)
+}
+
+// Listing reports whether the key list is open, and not covered by the inspector.
+func (b *BodyView) Listing() bool {
+	return b.listing && !b.inspecting
+}
+
+// FocusKeyFilter moves the focus to the key list filter until Enter or Esc.
+func (b *BodyView) FocusKeyFilter() {
+	b.keyList.Filter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			b.keyList.Filter.SetText("")
+		}
+		b.app.SetFocus(b.keyList.Table)
+	})
+	b.app.SetFocus(b.keyList.Filter)
+}
+
+// Filtering reports whether the key list filter has the focus, so keys go to it.
+func (b *BodyView) Filtering() bool {
+	return b.listing && b.keyList.Filter.HasFocus()
+}
+
+// ActionTarget returns what a write action would apply to: the inspected or selected key, or the
+// namespace selected in the namespace tab or treemap.
+func (b *BodyView) ActionTarget(state *models.State) (components.ActionTarget, bool) {
+	if b.inspecting {
+		if b.inspection == nil {
+			return components.ActionTarget{}, false
+		}
+		return components.ActionTarget{Name: b.inspection.Name, DB: b.inspection.DB}, true
+	}
+	if name, db, ok := b.SelectedKey(state); ok {
+		return components.ActionTarget{Name: name, DB: db}, true
+	}
+	var namespace string
+	var ok bool
+	switch b.activeView {
+	case TabNamespace:
+		namespace, ok = b.SelectedNamespace()
+	case TabTreemap:
+		namespace, ok = b.treemap.Selected()
+	}
+	return components.ActionTarget{Name: namespace, Namespace: true}, ok && !b.listing
+}
+
+// OpenAction shows the action form for target, or disabled instead when writes aren't allowed.
+func (b *BodyView) OpenAction(target components.ActionTarget, pattern string, disabled string) {
+	b.actionForm.Open(target, pattern, disabled)
+	b.acting = true
+	b.ContentFlex.Clear().AddItem(b.actionForm.Flex, 0, 2, true)
+	b.Shortcuts.SetText(components.ActionShortcutsText)
+	b.app.SetFocus(b.actionForm.Form)
+}
+
+// CloseAction goes back to what the action form was opened from.
+func (b *BodyView) CloseAction() {
+	b.acting = false
+	switch {
+	case b.inspecting && b.inspection != nil:
+		b.ShowInspection(b.inspection, nil)
+	case b.listing:
+		b.inspecting = false
+		b.showKeyList()
+	default:
+		b.SetActiveView(b.activeView)
+	}
+}
+
+// Acting reports whether the action form is open, so keys go to it.
+func (b *BodyView) Acting() bool {
+	return b.acting
+}
+
+// ActionMessage shows the outcome of an action in the action form.
+func (b *BodyView) ActionMessage(text string) {
+	b.actionForm.SetMessage(text)
+}
+
+// SetActionHandlers sets what running a confirmed action does.
+func (b *BodyView) SetActionHandlers(run func(components.ActionRequest)) {
+	b.actionForm.SetHandlers(run, b.CloseAction)
+}
+
+func (b *BodyView) Inspecting() bool {
+	return b.inspecting
+}
+
+// Inspection returns the key shown in the inspector, nil when it failed to load.
+func (b *BodyView) Inspection() *models.KeyInspection {
+	return b.inspection
+}
+
+// SetLatencyHandlers sets what fetching LATENCY DOCTOR, and showing or leaving the Latency
+// Spikes tab, do.
+func (b *BodyView) SetLatencyHandlers(fetchDoctor func(), watch func(on bool)) {
+	b.fetchDoctor = fetchDoctor
+	b.watchLatency = watch
+}
+
+// LatencyDoctor shows a LATENCY DOCTOR report in the Latency tab.
+func (b *BodyView) LatencyDoctor(text string, err error) {
+	b.latency.SetDoctor(text, err)
+}
+
+// ConfirmLatencyMonitor asks to set latency-monitor-threshold, while the monitor is disabled.
+func (b *BodyView) ConfirmLatencyMonitor(threshold time.Duration) {
+	switch {
+	case b.config.ReadOnly:
+		b.latency.SetMessage("[yellow]Read-only mode never sets latency-monitor-threshold, run with --read-only=false to set it from here[-]")
+	case !b.latency.Confirm(true, threshold):
+		b.latency.SetMessage("[yellow]latency-monitor-threshold is only set from here while the monitor is known to be disabled[-]")
+	}
+}
+
+// ConfirmingLatencyMonitor reports whether the Latency tab waits for Y to set the threshold.
+func (b *BodyView) ConfirmingLatencyMonitor() bool {
+	return b.activeView == TabLatency && b.latency.Confirming()
+}
+
+// LatencyMessage stops asking to set the threshold and shows message instead.
+func (b *BodyView) LatencyMessage(message string) {
+	b.latency.Confirm(false, b.config.LatencyThreshold)
+	b.latency.SetMessage(message)
+}
+
+// SetTreemapHandlers sets what drilling into a treemap tile, and going a level up, do.
+func (b *BodyView) SetTreemapHandlers(drillDown func(namespace string), levelUp func()) {
+	b.treemap.SetHandlers(drillDown, levelUp)
+}
@@ -0,0 +1,86 @@
+package components
+
+import (
+	"fmt"
+	"redscout/lib/utils"
+	"redscout/models"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const AccessShortcutsText = "[yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR  |  [yellow]T[-] Toggle View  |  [yellow]Q[-] Quit"
+
+func NewAccessTable() *tview.Table {
+	table := tview.NewTable().SetFixed(1, 0)
+	table.SetTitle(" Access (Namespaces by Cold Memory) ").SetTitleAlign(tview.AlignLeft)
+	table.SetSelectable(true, false)
+	table.SetBorders(false)
+	table.SetBorderPadding(0, 0, 1, 0)
+	return table
+}
+
+// UpdateAccessTable shows idle time histograms under LRU policies, or LFU counters under LFU ones.
+func UpdateAccessTable(table *tview.Table, stats models.NamespaceMetricList, lfu bool, coldAfter time.Duration) {
+	labels := BucketLabels(models.DurationBuckets)
+	histHeader := fmt.Sprintf("Idle Memory %s…%s", labels[0], labels[len(labels)-1])
+	coldHeader := fmt.Sprintf("~Cold (idle ≥%s)", utils.FormatDuration(int64(coldAfter.Seconds())))
+	if lfu {
+		histHeader = "Avg LFU Freq"
+		coldHeader = "~Cold (LFU 0)"
+	}
+	headers := []string{"Namespace", "~Memory", coldHeader, "Cold %", histHeader}
+	colors := []tcell.Color{
+		tcell.ColorWhite,
+		tcell.ColorAqua,
+		tcell.ColorLightBlue,
+		tcell.ColorLightCyan,
+		tcell.ColorYellow,
+	}
+
+	rows := make(models.NamespaceMetricList, len(stats))
+	copy(rows, stats)
+	rows.Sort("Cold Memory")
+
+	table.Clear()
+	for i, h := range headers {
+		cell := tview.NewTableCell(fmt.Sprintf("[white::b]%s", h)).
+			SetTextColor(tcell.ColorWhite).
+			SetAttributes(tcell.AttrBold).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetSelectable(false).
+			SetAlign(tview.AlignLeft)
+		table.SetCell(0, i, cell)
+	}
+
+	for i, row := range rows {
+		access := fmt.Sprintf("%12.1f", row.AvgFreq)
+		if !lfu {
+			access = ""
+			if row.IdleHistogram != nil {
+				values := make([]float64, len(row.IdleHistogram.Memory))
+				for j, m := range row.IdleHistogram.Memory {
+					values[j] = float64(m)
+				}
+				access = Sparkline(values)
+			}
+		}
+
+		values := []string{
+			fmt.Sprintf("%-20s", row.Namespace),
+			fmt.Sprintf("%12s", utils.FormatBytes(row.EstMemory)),
+			fmt.Sprintf("%12s", utils.FormatBytes(row.EstColdMemory)),
+			fmt.Sprintf("%11.1f%%", row.ColdPercent*100),
+			access,
+		}
+		for j, val := range values {
+			cell := tview.NewTableCell(fmt.Sprintf("[%s]%s", colors[j], val)).
+				SetAlign(tview.AlignLeft).
+				SetExpansion(0).
+				SetBackgroundColor(tcell.ColorBlack)
+			table.SetCell(i+1, j, cell)
+		}
+	}
+	table.ScrollToBeginning()
+}
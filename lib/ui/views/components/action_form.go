@@ -0,0 +1,181 @@
+package components
+
+import (
+	"fmt"
+	"redscout/lib/utils"
+	"redscout/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const ActionShortcutsText = "[yellow]Tab[-] Next Field  |  [yellow]Enter[-] Select/Run  |  [yellow]Esc[-] Close"
+
+// ActionTarget is what a write action applies to, a key or a namespace below the current prefix.
+type ActionTarget struct {
+	Name      string
+	DB        int
+	Namespace bool
+}
+
+// ActionRequest is a write action confirmed in the form.
+type ActionRequest struct {
+	Target ActionTarget
+	Kind   string
+	TTL    time.Duration
+	DryRun bool
+}
+
+var actionKinds = []string{models.ActionExpire, models.ActionUnlink}
+
+// ActionForm sets up an expire or unlink of a key or namespace, which only runs once the name of
+// the target is typed in. Dry runs of namespace actions don't need the confirmation.
+type ActionForm struct {
+	Flex   *tview.Flex
+	Form   *tview.Form
+	Status *tview.TextView
+
+	target  ActionTarget
+	kind    string
+	ttl     string
+	dryRun  bool
+	confirm string
+
+	// Whether the progress of bulk actions is shown, once one was started from the form
+	bulk bool
+
+	run   func(ActionRequest)
+	close func()
+}
+
+func NewActionForm() *ActionForm {
+	f := &ActionForm{
+		Flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		Form:   tview.NewForm(),
+		Status: tview.NewTextView().SetDynamicColors(true),
+		run:    func(ActionRequest) {},
+		close:  func() {},
+	}
+	f.Form.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	f.Form.SetCancelFunc(func() { f.close() })
+	f.Form.SetFieldBackgroundColor(tcell.ColorDarkSlateGray)
+	f.Status.SetBorder(true).SetBorderPadding(0, 0, 1, 1)
+	f.Status.SetTitle(" Status ").SetTitleAlign(tview.AlignLeft)
+
+	f.Flex.AddItem(f.Form, 13, 0, true)
+	f.Flex.AddItem(f.Status, 0, 1, false)
+	return f
+}
+
+// SetHandlers sets what running a confirmed action, and closing the form, do.
+func (f *ActionForm) SetHandlers(run func(ActionRequest), close func()) {
+	f.run = run
+	f.close = close
+}
+
+// Open resets the form for an action on target. pattern is what a namespace action matches,
+// and disabled, when set, is shown instead of the form.
+func (f *ActionForm) Open(target ActionTarget, pattern string, disabled string) {
+	f.target = target
+	f.kind = models.ActionExpire
+	f.ttl = ""
+	f.dryRun = target.Namespace
+	f.confirm = ""
+	f.bulk = false
+
+	what := "Key"
+	if target.Namespace {
+		what = "Namespace"
+	}
+	f.Form.Clear(true)
+	f.Form.SetTitle(fmt.Sprintf(" %s %s in db%d ", what, tview.Escape(models.FormatElement(target.Name)), target.DB))
+
+	if disabled != "" {
+		f.Status.SetText(fmt.Sprintf("[red]%s[-]", tview.Escape(disabled)))
+		f.Form.AddButton("Close", func() { f.close() })
+		return
+	}
+
+	f.Form.AddDropDown("Action", actionKinds, 0, func(option string, _ int) { f.kind = option })
+	f.Form.AddInputField("TTL (seconds)", "", 12, tview.InputFieldInteger, func(text string) { f.ttl = text })
+	if target.Namespace {
+		f.Form.AddCheckbox("Dry run", true, func(checked bool) { f.dryRun = checked })
+	}
+	f.Form.AddInputField("Type name to confirm", "", 40, nil, func(text string) { f.confirm = text })
+	f.Form.AddButton("Run", f.submit)
+	f.Form.AddButton("Cancel", func() { f.close() })
+	f.Form.SetFocus(0)
+
+	if target.Namespace {
+		f.Status.SetText(fmt.Sprintf("Matches [yellow]%s[-] below the current prefix.\nRun a dry run first to count the keys.", tview.Escape(pattern)))
+	} else {
+		f.Status.SetText("Expire sets the TTL of the key, unlink removes it.")
+	}
+}
+
+func (f *ActionForm) submit() {
+	req := ActionRequest{Target: f.target, Kind: f.kind, DryRun: f.target.Namespace && f.dryRun}
+	if req.Kind == models.ActionExpire {
+		seconds, err := strconv.ParseInt(f.ttl, 10, 64)
+		if err != nil || seconds <= 0 {
+			f.SetMessage("[red]Expire needs a TTL of at least one second[-]")
+			return
+		}
+		req.TTL = time.Duration(seconds) * time.Second
+	}
+	if !req.DryRun && f.confirm != f.target.Name {
+		f.SetMessage(fmt.Sprintf("[red]Type %s to confirm[-]", tview.Escape(f.target.Name)))
+		return
+	}
+	f.bulk = f.target.Namespace
+	f.run(req)
+}
+
+// SetMessage shows the outcome of an action.
+func (f *ActionForm) SetMessage(text string) {
+	f.Status.SetText(text)
+}
+
+// UpdateProgress shows how far a bulk action started from the form got.
+func (f *ActionForm) UpdateProgress(p *models.ActionProgress) {
+	if !f.bulk || p == nil {
+		return
+	}
+	f.Status.SetText(ActionProgressText(p))
+}
+
+// ActionProgressText renders the progress of a bulk action.
+func ActionProgressText(p *models.ActionProgress) string {
+	var sb strings.Builder
+	a := p.Action
+	verb := a.Kind
+	if a.Kind == models.ActionExpire {
+		verb = fmt.Sprintf("expire in %s", utils.FormatDuration(int64(a.TTL.Seconds())))
+	}
+	if a.DryRun {
+		verb = "dry run, " + verb
+	}
+	fmt.Fprintf(&sb, "[::b]%s[::-] in db%d (%s)\n\n", tview.Escape(a.Pattern), a.DB, verb)
+
+	if p.Total > 0 {
+		fmt.Fprintf(&sb, "%s\n", CreateProgressBar(100*float64(p.Scanned)/float64(p.Total), 100, 40))
+	}
+	fmt.Fprintf(&sb, "[teal]Scanned[-]  ~%s of %s keys\n", utils.FormatNumber(float64(p.Scanned)), utils.FormatNumber(float64(p.Total)))
+	fmt.Fprintf(&sb, "[teal]Matched[-]  %s\n", utils.FormatNumber(float64(p.Matched)))
+	if !a.DryRun {
+		fmt.Fprintf(&sb, "[teal]Written[-]  %s\n", utils.FormatNumber(float64(p.Done)))
+	}
+
+	switch {
+	case p.Err != nil:
+		fmt.Fprintf(&sb, "\n[red]%s[-]", tview.Escape(p.Err.Error()))
+	case p.Finished && a.DryRun:
+		fmt.Fprintf(&sb, "\n[green]Dry run done, %s keys would be written[-]", utils.FormatNumber(float64(p.Matched)))
+	case p.Finished:
+		sb.WriteString("\n[green]Done[-]")
+	}
+	return sb.String()
+}
@@ -0,0 +1,61 @@
+package components
+
+import (
+	"fmt"
+	"redscout/lib/history"
+	"redscout/models"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const AnomaliesShortcutsText = "[yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR  |  [yellow]Q[-] Quit"
+
+func NewAnomaliesTable() *tview.Table {
+	table := tview.NewTable().SetFixed(1, 0)
+	table.SetTitle(" Anomalies (Namespace Metrics far from their Baseline over Past Runs) ").SetTitleAlign(tview.AlignLeft)
+	table.SetSelectable(true, false)
+	table.SetBorders(false)
+	table.SetBorderPadding(0, 0, 1, 0)
+	return table
+}
+
+func UpdateAnomaliesTable(table *tview.Table, anomalies models.AnomalyList) {
+	headers := []string{"Namespace", "Metric", "Value", "Baseline", "Score"}
+	colors := []tcell.Color{
+		tcell.ColorWhite,
+		tcell.ColorAqua,
+		tcell.ColorYellow,
+		tcell.ColorGray,
+		tcell.ColorRed,
+	}
+
+	table.Clear()
+	for i, h := range headers {
+		cell := tview.NewTableCell(fmt.Sprintf("[white::b]%s", h)).
+			SetTextColor(tcell.ColorWhite).
+			SetAttributes(tcell.AttrBold).
+			SetBackgroundColor(tcell.ColorAqua).
+			SetSelectable(false).
+			SetAlign(tview.AlignLeft)
+		table.SetCell(0, i, cell)
+	}
+
+	for i, row := range anomalies {
+		values := []string{
+			row.Namespace,
+			row.Metric,
+			fmt.Sprintf("%12s", history.FormatMetricValue(row.Metric, row.Value)),
+			fmt.Sprintf("%12s", history.FormatMetricValue(row.Metric, row.Mean)),
+			fmt.Sprintf("%+7.1fσ", row.Score),
+		}
+		for j, val := range values {
+			cell := tview.NewTableCell(fmt.Sprintf("[%s]%s", colors[j], val)).
+				SetAlign(tview.AlignLeft).
+				SetExpansion(0).
+				SetBackgroundColor(tcell.ColorBlack)
+			table.SetCell(i+1, j, cell)
+		}
+	}
+	table.ScrollToBeginning()
+}
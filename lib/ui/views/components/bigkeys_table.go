@@ -9,7 +9,7 @@ import (
 	"github.com/rivo/tview"
 )
 
-const BigKeysShortcutsText = "[yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR  |  [yellow]Q[-] Quit"
+const BigKeysShortcutsText = "[yellow]Enter[-] Inspect  |  [yellow]/[-] Search  |  [yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR  |  [yellow]Q[-] Quit"
 
 func NewBigKeyTable() *tview.Table {
 	table := tview.NewTable().SetFixed(1, 0)
@@ -21,10 +21,12 @@ func NewBigKeyTable() *tview.Table {
 }
 
 func UpdateBigKeyTable(table *tview.Table, bigKeys models.BigKeyList) {
-	headers := []string{"Key", "Size"}
+	headers := []string{"Key", "Size", "Elements", "Type"}
 	colors := []tcell.Color{
 		tcell.ColorWhite,
 		tcell.ColorYellow,
+		tcell.ColorOrange,
+		tcell.ColorGray,
 	}
 
 	table.Clear()
@@ -42,6 +44,8 @@ func UpdateBigKeyTable(table *tview.Table, bigKeys models.BigKeyList) {
 		values := []string{
 			row.Key.String(),
 			fmt.Sprintf("%12s", utils.FormatBytes(row.Size)),
+			fmt.Sprintf("%12s", utils.FormatNumber(float64(row.Elements))),
+			row.Type,
 		}
 		for j, val := range values {
 			cell := tview.NewTableCell(fmt.Sprintf("[%s]%s", colors[j], val)).
@@ -0,0 +1,107 @@
+package components
+
+import (
+	"fmt"
+	"redscout/lib/utils"
+	"redscout/models"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const EncodingShortcutsText = "[yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR  |  [yellow]T[-] Toggle View  |  [yellow]Q[-] Quit"
+
+func NewEncodingTable() *tview.Table {
+	table := tview.NewTable().SetFixed(1, 0)
+	table.SetTitle(" Encoding (Namespaces by Est. Saving) ").SetTitleAlign(tview.AlignLeft)
+	table.SetSelectable(true, false)
+	table.SetBorders(false)
+	table.SetBorderPadding(0, 0, 1, 0)
+	return table
+}
+
+func UpdateEncodingTable(table *tview.Table, stats models.NamespaceMetricList, thresholds models.EncodingThresholds) {
+	headers := []string{"Namespace", "Encodings", "~Near Threshold", "~Saving", "Advice"}
+	colors := []tcell.Color{
+		tcell.ColorWhite,
+		tcell.ColorAqua,
+		tcell.ColorOrange,
+		tcell.ColorLightGreen,
+		tcell.ColorYellow,
+	}
+
+	rows := make(models.NamespaceMetricList, len(stats))
+	copy(rows, stats)
+	rows.Sort("Encoding Saving")
+
+	table.Clear()
+	for i, h := range headers {
+		cell := tview.NewTableCell(fmt.Sprintf("[white::b]%s", h)).
+			SetTextColor(tcell.ColorWhite).
+			SetAttributes(tcell.AttrBold).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetSelectable(false).
+			SetAlign(tview.AlignLeft)
+		table.SetCell(0, i, cell)
+	}
+
+	for i, row := range rows {
+		values := []string{
+			fmt.Sprintf("%-20s", row.Namespace),
+			formatEncodingMix(row.Encodings),
+			fmt.Sprintf("%15s", utils.FormatNumber(float64(row.EncodingAdvice.TotalKeys()))),
+			fmt.Sprintf("%12s", utils.FormatBytes(row.EncodingAdvice.TotalSaving())),
+			formatEncodingAdvice(row.EncodingAdvice),
+		}
+		for j, val := range values {
+			cell := tview.NewTableCell(fmt.Sprintf("[%s]%s", colors[j], val)).
+				SetAlign(tview.AlignLeft).
+				SetExpansion(0).
+				SetBackgroundColor(tcell.ColorBlack)
+			table.SetCell(i+1, j, cell)
+		}
+	}
+
+	table.SetTitle(fmt.Sprintf(" Encoding (Namespaces by Est. Saving)  %s ", formatThresholds(thresholds)))
+	table.ScrollToBeginning()
+}
+
+func formatEncodingMix(encodings map[string]float64) string {
+	names := make([]string, 0, len(encodings))
+	for name := range encodings {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return encodings[names[i]] > encodings[names[j]]
+	})
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s %.0f%%", name, encodings[name]*100))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatEncodingAdvice(advice models.EncodingAdviceList) string {
+	parts := make([]string, 0, len(advice))
+	for _, a := range advice {
+		parts = append(parts, fmt.Sprintf("%s %d → %d", a.Config, a.Current, a.Suggested))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatThresholds(thresholds models.EncodingThresholds) string {
+	keyTypes := make([]string, 0, len(thresholds))
+	for keyType := range thresholds {
+		keyTypes = append(keyTypes, keyType)
+	}
+	sort.Strings(keyTypes)
+
+	parts := make([]string, 0, len(keyTypes))
+	for _, keyType := range keyTypes {
+		parts = append(parts, fmt.Sprintf("%s=%d", thresholds[keyType].Config, thresholds[keyType].MaxEntries))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,61 @@
+package components
+
+import (
+	"fmt"
+	"redscout/lib/utils"
+	"redscout/models"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const HistoryShortcutsText = "[yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR  |  [yellow]Q[-] Quit"
+
+func NewHistoryTable() *tview.Table {
+	table := tview.NewTable().SetFixed(1, 0)
+	table.SetTitle(" Top Growers (Estimated Memory across Recorded Runs) ").SetTitleAlign(tview.AlignLeft)
+	table.SetSelectable(true, false)
+	table.SetBorders(false)
+	table.SetBorderPadding(0, 0, 1, 0)
+	return table
+}
+
+func UpdateHistoryTable(table *tview.Table, growers models.NamespaceGrowthList) {
+	headers := []string{"Namespace", "Since", "~Keys", "~Memory", "Growth"}
+	colors := []tcell.Color{
+		tcell.ColorWhite,
+		tcell.ColorGray,
+		tcell.ColorAqua,
+		tcell.ColorYellow,
+		tcell.ColorOrange,
+	}
+
+	table.Clear()
+	for i, h := range headers {
+		cell := tview.NewTableCell(fmt.Sprintf("[white::b]%s", h)).
+			SetTextColor(tcell.ColorWhite).
+			SetAttributes(tcell.AttrBold).
+			SetBackgroundColor(tcell.ColorAqua).
+			SetSelectable(false).
+			SetAlign(tview.AlignLeft)
+		table.SetCell(0, i, cell)
+	}
+
+	for i, row := range growers {
+		values := []string{
+			row.Namespace,
+			row.From.Format("2006-01-02 15:04"),
+			fmt.Sprintf("%8s → %-8s", utils.FormatNumber(float64(row.FromKeys)), utils.FormatNumber(float64(row.ToKeys))),
+			fmt.Sprintf("%10s → %-10s", utils.FormatBytes(row.FromMemory), utils.FormatBytes(row.ToMemory)),
+			fmt.Sprintf("%12s", utils.FormatSignedBytes(row.MemoryGrowth())),
+		}
+		for j, val := range values {
+			cell := tview.NewTableCell(fmt.Sprintf("[%s]%s", colors[j], val)).
+				SetAlign(tview.AlignLeft).
+				SetExpansion(0).
+				SetBackgroundColor(tcell.ColorBlack)
+			table.SetCell(i+1, j, cell)
+		}
+	}
+	table.ScrollToBeginning()
+}
@@ -3,27 +3,37 @@ package components
 import (
 	"fmt"
 	"redscout/models"
+	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-const HotKeysShortcutsText = "[yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR  |  [yellow]Q[-] Quit"
+const HotKeysShortcutsText = "[yellow]Enter[-] Inspect  |  [yellow]/[-] Search  |  [yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR  |  [yellow]Q[-] Quit"
 
 func NewHotKeyTable() *tview.Table {
 	table := tview.NewTable().SetFixed(1, 0)
-	table.SetTitle(" Hot Keys (Top N by Ops) ").SetTitleAlign(tview.AlignLeft)
+	table.SetTitle(" Hot Keys (Top N by Ops, then LFU Counter) ").SetTitleAlign(tview.AlignLeft)
 	table.SetSelectable(true, false)
 	table.SetBorders(false)
 	table.SetBorderPadding(0, 0, 1, 0)
 	return table
 }
 
-func UpdateHotKeyTable(table *tview.Table, hotKeys models.HotKeyList) {
-	headers := []string{"Key", "Ops"}
+// UpdateHotKeyTable redraws the hot keys, with ops as counts rather than rates when counts is set.
+func UpdateHotKeyTable(table *tview.Table, hotKeys models.HotKeyList, writeOnly, counts bool) {
+	if writeOnly {
+		table.SetTitle(" Hot Keys (Top N by Writes, from keyspace notifications) ")
+	} else {
+		table.SetTitle(" Hot Keys (Top N by Ops, then LFU Counter) ")
+	}
+
+	headers := []string{"Key", "Ops", "LFU", "Source"}
 	colors := []tcell.Color{
 		tcell.ColorWhite,
 		tcell.ColorAqua,
+		tcell.ColorOrange,
+		tcell.ColorGray,
 	}
 
 	table.Clear()
@@ -38,9 +48,22 @@ func UpdateHotKeyTable(table *tview.Table, hotKeys models.HotKeyList) {
 	}
 
 	for i, row := range hotKeys {
+		// Keys only found by LFU sampling have no observed ops, and vice versa
+		ops, freq := fmt.Sprintf("%10s", "-"), fmt.Sprintf("%5s", "-")
+		if row.Ops > 0 {
+			ops = fmt.Sprintf("%8.1f/s", row.Ops)
+			if counts {
+				ops = fmt.Sprintf("%10.0f", row.Ops)
+			}
+		}
+		if row.Freq > 0 {
+			freq = fmt.Sprintf("%5d", row.Freq)
+		}
 		values := []string{
 			row.Key.String(),
-			fmt.Sprintf("%8.1f/s", row.Ops),
+			ops,
+			freq,
+			strings.Join(row.Sources, "+"),
 		}
 		for j, val := range values {
 			cell := tview.NewTableCell(fmt.Sprintf("[%s]%s", colors[j], val)).
@@ -0,0 +1,55 @@
+package components
+
+import (
+	"fmt"
+	"redscout/lib/utils"
+	"redscout/models"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+const KeyInspectorShortcutsText = "[yellow]PgDn[-] Next Page  |  [yellow]Home[-] First Page  |  [yellow]Esc[-] Back  |  [yellow]Q[-] Quit"
+
+func NewKeyInspector() *tview.TextView {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false)
+	view.SetBorder(true).SetBorderPadding(0, 0, 1, 1)
+	view.SetTitle(" Key Inspector ").SetTitleAlign(tview.AlignLeft)
+	return view
+}
+
+// KeyInspectorText renders the metadata and value page of an inspected key.
+func KeyInspectorText(k *models.KeyInspection) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[::b]%s[::-]  [gray]db%d[-]\n\n", tview.Escape(models.FormatElement(k.Name)), k.DB)
+
+	ttl := "[gray]none[-]"
+	if k.TTL >= 0 {
+		ttl = utils.FormatDuration(int64(k.TTL.Seconds()))
+	}
+	elements := "Elements"
+	if k.Type == "string" {
+		elements = "Length"
+	}
+	fmt.Fprintf(&sb, " [teal]Type[-]      %s\n", k.Type)
+	fmt.Fprintf(&sb, " [teal]Encoding[-]  %s\n", k.Encoding)
+	fmt.Fprintf(&sb, " [teal]TTL[-]       %s\n", ttl)
+	fmt.Fprintf(&sb, " [teal]Memory[-]    %s\n", utils.FormatBytes(k.Memory))
+	fmt.Fprintf(&sb, " [teal]%-8s[-]  %s\n", elements, utils.FormatNumber(float64(k.Elements)))
+
+	page := "first page"
+	if k.PageStart != "" {
+		page = "from " + k.PageStart
+	}
+	if k.NextPage == "" {
+		page += ", last page"
+	}
+	fmt.Fprintf(&sb, "\n [teal]Value[-] [gray](%s)[-]\n", page)
+	for _, line := range k.Page {
+		sb.WriteString(" " + tview.Escape(line) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,122 @@
+package components
+
+import (
+	"fmt"
+	"redscout/lib/utils"
+	"redscout/models"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const KeyListShortcutsText = "[yellow]Enter[-] Inspect  |  [yellow]/[-] Filter  |  [yellow]1-4[-] Sort  |  [yellow]Esc[-] Back  |  [yellow]Q[-] Quit"
+
+var KeyListSortKeyMap = map[rune]string{
+	'1': "Memory",
+	'2': "TTL",
+	'3': "Elements",
+	'4': "Key",
+}
+
+// KeyList lists the sampled keys of a namespace, with a filter below the table.
+type KeyList struct {
+	Flex   *tview.Flex
+	Table  *tview.Table
+	Filter *tview.InputField
+
+	namespace string
+	keys      models.SampledKeyList
+	shown     models.SampledKeyList
+}
+
+func NewKeyList() *KeyList {
+	l := &KeyList{
+		Flex:   tview.NewFlex().SetDirection(tview.FlexRow),
+		Table:  tview.NewTable().SetFixed(1, 0),
+		Filter: tview.NewInputField().SetLabel(" / ").SetFieldBackgroundColor(tcell.ColorBlack),
+	}
+	l.Table.SetSelectable(true, false)
+	l.Table.SetBorders(false)
+	l.Table.SetBorder(true).SetBorderPadding(0, 0, 1, 0)
+	l.Table.SetTitleAlign(tview.AlignLeft)
+	l.Filter.SetChangedFunc(func(string) { l.render() })
+
+	l.Flex.AddItem(l.Table, 0, 1, true)
+	l.Flex.AddItem(l.Filter, 1, 0, false)
+	return l
+}
+
+// SetKeys shows the keys of a namespace, clearing the filter.
+func (l *KeyList) SetKeys(namespace string, keys models.SampledKeyList) {
+	l.namespace = namespace
+	l.keys = keys
+	l.Filter.SetText("")
+	l.render()
+	l.Table.Select(1, 0)
+}
+
+func (l *KeyList) Sort(sortBy string) {
+	l.keys.Sort(sortBy)
+	l.render()
+}
+
+// Selected returns the key on the selected row.
+func (l *KeyList) Selected() (models.SampledKey, bool) {
+	row, _ := l.Table.GetSelection()
+	if row <= 0 || row > len(l.shown) {
+		return models.SampledKey{}, false
+	}
+	return l.shown[row-1], true
+}
+
+func (l *KeyList) render() {
+	l.shown = l.keys.Filter(l.Filter.GetText())
+	title := fmt.Sprintf(" Sampled Keys in %s (%d) ", l.namespace, len(l.keys))
+	if len(l.shown) != len(l.keys) {
+		title = fmt.Sprintf(" Sampled Keys in %s (%d of %d) ", l.namespace, len(l.shown), len(l.keys))
+	}
+	l.Table.SetTitle(title)
+
+	headers := []string{"Key", "Memory", "TTL", "Elements", "Type", "Encoding"}
+	colors := []tcell.Color{
+		tcell.ColorWhite,
+		tcell.ColorYellow,
+		tcell.ColorGreen,
+		tcell.ColorOrange,
+		tcell.ColorGray,
+		tcell.ColorGray,
+	}
+
+	l.Table.Clear()
+	for i, h := range headers {
+		cell := tview.NewTableCell(fmt.Sprintf("[white::b]%s", h)).
+			SetTextColor(tcell.ColorWhite).
+			SetAttributes(tcell.AttrBold).
+			SetBackgroundColor(tcell.ColorAqua).
+			SetSelectable(false).
+			SetAlign(tview.AlignLeft)
+		l.Table.SetCell(0, i, cell)
+	}
+
+	for i, row := range l.shown {
+		ttl := "-"
+		if row.TTL > 0 {
+			ttl = utils.FormatDuration(row.TTL)
+		}
+		values := []string{
+			tview.Escape(models.FormatElement(row.Name)),
+			fmt.Sprintf("%12s", utils.FormatBytes(row.Memory)),
+			fmt.Sprintf("%12s", ttl),
+			fmt.Sprintf("%12s", utils.FormatNumber(float64(row.Elements))),
+			row.Type,
+			row.Encoding,
+		}
+		for j, val := range values {
+			cell := tview.NewTableCell(fmt.Sprintf("[%s]%s", colors[j], val)).
+				SetAlign(tview.AlignLeft).
+				SetExpansion(0).
+				SetBackgroundColor(tcell.ColorBlack)
+			l.Table.SetCell(i+1, j, cell)
+		}
+	}
+}
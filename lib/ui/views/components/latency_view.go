@@ -0,0 +1,204 @@
+package components
+
+import (
+	"fmt"
+	"redscout/models"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const LatencyShortcutsText = "[yellow]D[-] Doctor  |  [yellow]W[-] Enable Monitor  |  [yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR  |  [yellow]T[-] Toggle View  |  [yellow]Q[-] Quit"
+
+// latencySparkSamples is how many of the latest spikes of an event its sparkline shows.
+const latencySparkSamples = 60
+
+// LatencyView shows the latency monitor events with their spikes, and LATENCY DOCTOR below them.
+type LatencyView struct {
+	Flex    *tview.Flex
+	Summary *tview.TextView
+	Table   *tview.Table
+	Doctor  *tview.TextView
+
+	report    models.LatencyReport
+	threshold time.Duration
+	message   string
+	// Waiting for Y to set latency-monitor-threshold, which a read-only client never does
+	confirming bool
+	readOnly   bool
+	showDoctor bool
+}
+
+func NewLatencyView(readOnly bool) *LatencyView {
+	v := &LatencyView{readOnly: readOnly}
+	v.Summary = tview.NewTextView().SetDynamicColors(true)
+
+	v.Table = tview.NewTable().SetFixed(1, 0)
+	v.Table.SetSelectable(true, false)
+	v.Table.SetBorders(false)
+
+	v.Doctor = tview.NewTextView().SetDynamicColors(true).SetWrap(true)
+	v.Doctor.SetBorder(true).SetTitle("[teal]Latency Doctor[-]").SetTitleAlign(tview.AlignLeft)
+
+	v.Flex = tview.NewFlex().SetDirection(tview.FlexRow)
+	v.Flex.SetBorderPadding(0, 0, 1, 0)
+	v.layout()
+	return v
+}
+
+func (v *LatencyView) layout() {
+	v.Flex.Clear().
+		AddItem(v.Summary, 2, 0, false).
+		AddItem(v.Table, 0, 1, true)
+	if v.showDoctor {
+		v.Flex.AddItem(v.Doctor, 0, 1, false)
+	}
+}
+
+// ToggleDoctor shows or hides the LATENCY DOCTOR output, returning whether it is shown and has
+// yet to be fetched.
+func (v *LatencyView) ToggleDoctor() bool {
+	v.showDoctor = !v.showDoctor
+	v.layout()
+	if v.showDoctor && v.report.Doctor == "" {
+		v.Doctor.SetText("[yellow]Asking LATENCY DOCTOR...[-]")
+		return true
+	}
+	return false
+}
+
+// SetDoctor shows a LATENCY DOCTOR report, or why it couldn't be fetched.
+func (v *LatencyView) SetDoctor(text string, err error) {
+	if err != nil {
+		v.Doctor.SetText(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+		return
+	}
+	v.report.Doctor = text
+	v.Doctor.SetText(tview.Escape(text))
+}
+
+// Confirm asks, or stops asking, to set latency-monitor-threshold to threshold. It only asks
+// while the monitor is known to be disabled and never in read-only mode, and reports whether it does.
+func (v *LatencyView) Confirm(ask bool, threshold time.Duration) bool {
+	v.confirming = ask && !v.readOnly && v.report.Threshold == 0 && v.report.Err == nil
+	v.threshold = threshold
+	v.message = ""
+	v.renderSummary()
+	return v.confirming
+}
+
+func (v *LatencyView) Confirming() bool {
+	return v.confirming
+}
+
+// SetMessage shows a note below the summary, such as why enabling the monitor failed.
+func (v *LatencyView) SetMessage(message string) {
+	v.message = message
+	v.renderSummary()
+}
+
+func (v *LatencyView) Update(report models.LatencyReport, threshold time.Duration) {
+	v.report = report
+	v.threshold = threshold
+	if v.confirming && report.Threshold != 0 {
+		v.confirming = false
+	}
+	v.renderSummary()
+	if v.showDoctor && report.Doctor != "" {
+		v.Doctor.SetText(tview.Escape(report.Doctor))
+	}
+	v.renderTable()
+}
+
+func (v *LatencyView) renderSummary() {
+	report := v.report
+	var text string
+	switch {
+	case report.At.IsZero():
+		text = "[yellow]Latency monitor not read yet[-]"
+	case report.Err != nil:
+		text = fmt.Sprintf("[red]%s[-]", tview.Escape(report.Err.Error()))
+	case v.confirming:
+		text = fmt.Sprintf("[yellow]Set latency-monitor-threshold to %d ms on the server until RedScout exits?[-]  [green]Y[-]es / [red]N[-]o",
+			v.threshold.Milliseconds())
+	case report.Threshold == 0 && v.readOnly:
+		text = "[red]Latency monitor disabled[-] (latency-monitor-threshold 0), only events logged before are shown. " +
+			"Run with --read-only=false to set it from here."
+	case report.Threshold == 0:
+		text = fmt.Sprintf("[red]Latency monitor disabled[-] (latency-monitor-threshold 0), only events logged before are shown. "+
+			"[yellow]W[-] sets it to %d ms until RedScout exits.", v.threshold.Milliseconds())
+	case report.Threshold < 0:
+		text = "[yellow]latency-monitor-threshold unknown[-], CONFIG GET was refused"
+	default:
+		text = fmt.Sprintf("[green]Latency monitor on[-], logging events over %d ms", report.Threshold.Milliseconds())
+		if report.ThresholdSet {
+			text += ", [yellow]set by RedScout until it exits[-]"
+		}
+	}
+	if !report.At.IsZero() {
+		text += fmt.Sprintf("  [gray]%d events, read at %s[-]", len(report.Events), report.At.Format("15:04:05"))
+	}
+	if v.message != "" {
+		text += "\n" + v.message
+	}
+	v.Summary.SetText(text)
+}
+
+func (v *LatencyView) renderTable() {
+	headers := []string{"Event", "Kind", "Latest", "Max", "Last Spike", "Spikes", "History"}
+	colors := []tcell.Color{
+		tcell.ColorWhite,
+		tcell.ColorGray,
+		tcell.ColorYellow,
+		tcell.ColorRed,
+		tcell.ColorTeal,
+		tcell.ColorAqua,
+		tcell.ColorOrange,
+	}
+
+	v.Table.Clear()
+	for i, h := range headers {
+		cell := tview.NewTableCell(fmt.Sprintf("[white::b]%s", h)).
+			SetTextColor(tcell.ColorWhite).
+			SetAttributes(tcell.AttrBold).
+			SetBackgroundColor(tcell.ColorAqua).
+			SetSelectable(false).
+			SetAlign(tview.AlignLeft)
+		v.Table.SetCell(0, i, cell)
+	}
+
+	for i, event := range v.report.Events {
+		history := v.report.History[event.Name]
+		values := []string{
+			event.Name,
+			models.LatencyEventKind(event.Name),
+			fmt.Sprintf("%8d ms", event.Latency.Milliseconds()),
+			fmt.Sprintf("%8d ms", event.Max.Milliseconds()),
+			event.Time.Format("2006-01-02 15:04:05"),
+			fmt.Sprintf("%6d", len(history)),
+			latencySparkline(history),
+		}
+		for j, val := range values {
+			cell := tview.NewTableCell(fmt.Sprintf("[%s]%s", colors[j], val)).
+				SetAlign(tview.AlignLeft).
+				SetExpansion(0).
+				SetBackgroundColor(tcell.ColorBlack)
+			v.Table.SetCell(i+1, j, cell)
+		}
+	}
+}
+
+// latencySparkline draws the latest spikes of an event, oldest first, with the span they cover.
+func latencySparkline(history []models.LatencySample) string {
+	if len(history) == 0 {
+		return ""
+	}
+	history = history[max(0, len(history)-latencySparkSamples):]
+	values := make([]float64, len(history))
+	for i, sample := range history {
+		values[i] = float64(sample.Latency.Milliseconds())
+	}
+	span := history[len(history)-1].Time.Sub(history[0].Time).Round(time.Second)
+	return Sparkline(values) + fmt.Sprintf("  [gray]over %v[-]", span)
+}
@@ -5,23 +5,28 @@ import (
 	"redscout/lib/utils"
 	"redscout/models"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
 
-const StatsHeader = "[yellow]Sort:[-] [yellow]1[-] Keys  [yellow]2[-] Memory  [yellow]3[-] Avg TTL  [yellow]4[-] % TTL  [yellow]5[-] GET  [yellow]6[-] SET  [yellow]7[-] DEL  [yellow]8[-] OPS  |  [yellow]Enter/→[-] Drill Down  [yellow]Backspace/←[-] Level Up  |  [yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR |  [yellow]T[-] Toggle View  |  [yellow]Q[-] Quit"
+const StatsHeader = "[yellow]Sort:[-] [yellow]1[-] Keys  [yellow]2[-] Memory  [yellow]3[-] Avg TTL  [yellow]4[-] % TTL  [yellow]5[-] GET  [yellow]6[-] SET  [yellow]7[-] DEL  [yellow]8[-] OPS  [yellow]9[-] Max Elems  |  [yellow]Enter/→[-] Drill Down  [yellow]Backspace/←[-] Level Up  [yellow]D[-] Details  [yellow]K[-] Keys  [yellow]/[-] Search  |  [yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR |  [yellow]T[-] Toggle View  |  [yellow]Q[-] Quit"
 
 type Namespace struct {
-	Title *tview.TextView
-	Table *tview.Table
-	Flex  *tview.Flex
+	Title  *tview.TextView
+	Table  *tview.Table
+	Flex   *tview.Flex
+	Detail *tview.TextView
+
+	state      *models.State
+	showDetail bool
 }
 
 func NewNamespace() *Namespace {
 	ns := &Namespace{}
 	ns.Table = tview.NewTable().SetFixed(1, 0)
-	ns.Table.SetTitle(" Namespace Stats (Press 1-8 to sort) ").SetTitleAlign(tview.AlignLeft)
+	ns.Table.SetTitle(" Namespace Stats (Press 1-9 to sort) ").SetTitleAlign(tview.AlignLeft)
 	ns.Table.SetSelectable(true, false)
 	ns.Table.SetBorders(false)
 
@@ -35,23 +40,202 @@ func NewNamespace() *Namespace {
 	ns.Flex.AddItem(ns.Title, 1, -1, false)
 	ns.Flex.AddItem(ns.Table, 0, 1, true)
 
+	ns.Detail = tview.NewTextView().SetDynamicColors(true)
+	ns.Detail.SetBorder(true).SetTitle("[teal]Details[-]").SetTitleAlign(tview.AlignLeft)
+	ns.Table.SetSelectionChangedFunc(func(row, _ int) {
+		ns.renderDetail(row)
+	})
+
 	return ns
 }
 
-func (ns *Namespace) Update(prefix models.Key, stats models.NamespaceMetricList) {
-	headers := []string{"Namespace", "~Keys", "~Memory", "Avg TTL", "% TTL", "GET/s", "SET/s", "DEL/s", "Total Ops/s", "Types"}
+// ToggleDetail shows or hides the detail pane of the selected namespace.
+func (ns *Namespace) ToggleDetail() {
+	ns.showDetail = !ns.showDetail
+	if ns.showDetail {
+		ns.Flex.AddItem(ns.Detail, 22, 0, false)
+		row, _ := ns.Table.GetSelection()
+		ns.renderDetail(row)
+	} else {
+		ns.Flex.RemoveItem(ns.Detail)
+	}
+}
+
+func (ns *Namespace) renderDetail(row int) {
+	if !ns.showDetail {
+		return
+	}
+	if ns.state == nil || row <= 0 || row > len(ns.state.NamespaceStats) {
+		ns.Detail.SetText("")
+		return
+	}
+	m := ns.state.NamespaceStats[row-1]
+	ns.Detail.SetText(namespaceDetailText(m, ns.state.OpsTimeline, ns.state.History.Series(ns.state.CurrentPrefix, m.Namespace)))
+}
+
+// Samples shown in the Trend column and the size of the detail charts
+const (
+	trendSamples       = 12
+	historyChartWidth  = 60
+	historyChartHeight = 3
+)
+
+// memoryTrend returns the estimated memory of the last n samples.
+func memoryTrend(series []models.MetricSample, n int) []float64 {
+	series = series[max(len(series)-n, 0):]
+	values := make([]float64, len(series))
+	for i, sample := range series {
+		values[i] = float64(sample.EstMemory)
+	}
+	return values
+}
+
+func namespaceDetailText(m *models.NamespaceMetrics, timeline *models.Timeline, history []models.MetricSample) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, " [yellow]%s[-]  [teal]~Keys:[-] %s  [teal]~Memory:[-] %s  [teal]%% TTL:[-] %.1f%%\n\n",
+		m.Namespace,
+		utils.FormatNumber(float64(m.EstKeys)),
+		utils.FormatBytes(m.EstMemory),
+		m.TTLPercent*100,
+	)
+
+	if m.EvictionPressure {
+		fmt.Fprintf(&sb, " [red]⚠ No TTLs and %.1f writes/s while other namespaces are evicted[-]\n\n", m.Ops[models.SetOp])
+	}
+
+	if m.TTLHistogram == nil || m.TTLHistogram.Total() == 0 {
+		sb.WriteString(" [gray]No keys with a TTL in the sample[-]\n")
+	} else {
+		sb.WriteString(" [teal]Remaining TTL[-]\n")
+		sb.WriteString(histogramBars(m.TTLHistogram, 30))
+
+		fmt.Fprintf(&sb, "\n [teal]Expiring within[-]  1m: [white]%s[-]  1h: [white]%s[-]  1d: [white]%s[-]\n",
+			utils.FormatBytes(m.ExpiryForecast(60)),
+			utils.FormatBytes(m.ExpiryForecast(3600)),
+			utils.FormatBytes(m.ExpiryForecast(86400)),
+		)
+	}
+
+	if timeline != nil && m.OpsTimeline != nil {
+		sb.WriteString(opsTimelineText(m.OpsTimeline, timeline))
+	}
+	if len(history) > 1 {
+		sb.WriteString(historyText(history))
+	}
+	return sb.String()
+}
+
+// historyText charts the memory and ops samples recorded for a namespace this session.
+func historyText(history []models.MetricSample) string {
+	// One column per sample, the most recent ones when there are more than fit
+	history = history[max(len(history)-historyChartWidth, 0):]
+	memory := make([]float64, len(history))
+	ops := make([]float64, len(history))
+	for i, sample := range history {
+		memory[i] = float64(sample.EstMemory)
+		ops[i] = sample.Ops
+	}
+
+	first, last := history[0], history[len(history)-1]
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n [teal]~Memory[-]  %s → %s  (%s → %s)\n",
+		utils.FormatBytes(first.EstMemory),
+		utils.FormatBytes(last.EstMemory),
+		first.Time.Format("15:04:05"),
+		last.Time.Format("15:04:05"),
+	)
+	sb.WriteString(Chart(memory, historyChartHeight, "green"))
+	fmt.Fprintf(&sb, "\n [teal]Ops/s[-]  %.1f → %.1f\n", first.Ops, last.Ops)
+	sb.WriteString(Chart(ops, historyChartHeight, "purple"))
+	return sb.String()
+}
+
+// opsTimelineText plots replayed ops per timeline bucket, labelled with the covered time range.
+func opsTimelineText(counts []int64, timeline *models.Timeline) string {
+	values := make([]float64, len(counts))
+	var peak int64
+	for i, c := range counts {
+		values[i] = float64(c)
+		peak = max(peak, c)
+	}
+
+	end := timeline.Start.Add(time.Duration(timeline.Buckets) * timeline.Bucket)
+	return fmt.Sprintf("\n [teal]Ops per %s[-]  %s → %s  [teal]peak:[-] %s\n [green]%s[-]\n",
+		utils.FormatDuration(int64(timeline.Bucket.Seconds())),
+		timeline.Start.Format("2006-01-02 15:04"),
+		end.Format("2006-01-02 15:04"),
+		utils.FormatNumber(float64(peak)),
+		Sparkline(values),
+	)
+}
+
+// histogramBars plots a histogram as one horizontal bar per bucket, scaled to the largest bucket.
+func histogramBars(h *models.Histogram, width int) string {
+	var maxCount int64
+	for _, c := range h.Counts {
+		maxCount = max(maxCount, c)
+	}
+
+	var sb strings.Builder
+	for i, label := range BucketLabels(h.Bounds) {
+		filled := 0
+		if maxCount > 0 {
+			filled = int(float64(h.Counts[i]) / float64(maxCount) * float64(width))
+		}
+		fmt.Fprintf(&sb, " %-6s [green]%-*s[-] %8s keys  %10s\n",
+			label,
+			width,
+			strings.Repeat("█", filled),
+			utils.FormatNumber(float64(h.Counts[i])),
+			utils.FormatBytes(h.Memory[i]),
+		)
+	}
+	return sb.String()
+}
+
+// Update redraws the table. When ops come from keyspace notifications, which don't report
+// reads, GET/s is left blank rather than shown as zero. Ops without a monitored time to rate
+// them over are shown as counts. In live mode the rolling rates of each models.LiveWindows are
+// added before the types.
+func (ns *Namespace) Update(state *models.State) {
+	ns.state = state
+	prefix, stats := state.CurrentPrefix, state.NamespaceStats
+	writeOnly, live, counts := state.WriteOpsOnly, state.Live, state.OpsAreCounts()
+	removals := state.RemovalsTracked
+
+	headers := []string{"Namespace", "~Keys", "~Memory", "Trend", "Avg Elems", "Max Elems", "Avg TTL", "% TTL", "GET/s", "SET/s", "DEL/s", "Total Ops/s", "Evict/s", "Expire/s", "Types"}
+	opsFormat := "%8.1f/s"
+	if counts {
+		copy(headers[8:14], []string{"GETs", "SETs", "DELs", "Total Ops", "Evicts", "Expires"})
+		opsFormat = "%10.0f"
+	}
 	colors := []tcell.Color{
 		tcell.ColorWhite,
 		tcell.ColorYellow,
 		tcell.ColorAqua,
+		tcell.ColorGreen,
+		tcell.ColorOrange,
+		tcell.ColorOrange,
 		tcell.ColorLightGreen,
 		tcell.ColorLightCyan,
 		tcell.ColorBlue,
 		tcell.ColorGreen,
 		tcell.ColorRed,
 		tcell.ColorPurple,
+		tcell.ColorOrangeRed,
+		tcell.ColorLightSalmon,
 		tcell.ColorGray,
 	}
+	if live != nil {
+		types, typesColor := headers[len(headers)-1], colors[len(colors)-1]
+		headers, colors = headers[:len(headers)-1], colors[:len(colors)-1]
+		for _, window := range models.LiveWindows {
+			headers = append(headers, "Live "+utils.FormatDuration(int64(window.Seconds())))
+			colors = append(colors, tcell.ColorFuchsia)
+		}
+		headers, colors = append(headers, types), append(colors, typesColor)
+	}
+	now := time.Now()
 
 	// Calculate max width for each column
 	colWidths := make([]int, len(headers))
@@ -76,20 +260,54 @@ func (ns *Namespace) Update(prefix models.Key, stats models.NamespaceMetricList)
 		ns.Table.SetCell(0, i, cell)
 	}
 
+	title := " Namespace Stats (Press 1-9 to sort) "
+	if writeOnly {
+		title += "[orange]Ops: writes only, from keyspace notifications[-] "
+	}
+	if counts {
+		title += "[orange]Ops: command counts, the AOF has no timestamps[-] "
+	}
+	if !removals {
+		title += "[orange]Evictions, expirations and the ⚠ alert need --track-removals[-] "
+	}
+	ns.Table.SetTitle(title)
+
 	// Add data rows and update max widths
 	for i, row := range stats {
+		name := row.Namespace
+		if row.EvictionPressure {
+			name = "⚠ " + name
+		}
+		getOps := fmt.Sprintf(opsFormat, row.Ops[models.GetOp])
+		if writeOnly {
+			getOps = fmt.Sprintf("%10s", "-")
+		}
+		evictOps, expireOps := fmt.Sprintf(opsFormat, row.Ops[models.EvictedOp]), fmt.Sprintf(opsFormat, row.Ops[models.ExpiredOp])
+		if !removals {
+			evictOps, expireOps = fmt.Sprintf("%10s", "-"), fmt.Sprintf("%10s", "-")
+		}
 		values := []string{
-			fmt.Sprintf("%-20s", row.Namespace),
+			fmt.Sprintf("%-20s", name),
 			fmt.Sprintf("%12s", utils.FormatNumber(float64(row.EstKeys))),
 			fmt.Sprintf("%12s", utils.FormatBytes(row.EstMemory)),
+			fmt.Sprintf("%-*s", trendSamples, Sparkline(memoryTrend(state.History.Series(prefix, row.Namespace), trendSamples))),
+			fmt.Sprintf("%12s", utils.FormatNumber(row.AvgElems)),
+			fmt.Sprintf("%12s", utils.FormatNumber(float64(row.MaxElems))),
 			fmt.Sprintf("%12s", utils.FormatDuration(row.AvgTTL)),
 			fmt.Sprintf("%11.1f%%", row.TTLPercent*100),
-			fmt.Sprintf("%8.1f/s", row.Ops[models.GetOp]),
-			fmt.Sprintf("%8.1f/s", row.Ops[models.SetOp]),
-			fmt.Sprintf("%8.1f/s", row.Ops[models.DelOp]),
-			fmt.Sprintf("%8.1f/s", row.Ops[models.TotalOp]),
-			fmt.Sprintf("%-12s", strings.Join(row.Types[:], ",")),
+			getOps,
+			fmt.Sprintf(opsFormat, row.Ops[models.SetOp]),
+			fmt.Sprintf(opsFormat, row.Ops[models.DelOp]),
+			fmt.Sprintf(opsFormat, row.Ops[models.TotalOp]),
+			evictOps,
+			expireOps,
+		}
+		if live != nil {
+			for _, window := range models.LiveWindows {
+				values = append(values, fmt.Sprintf("%8.1f/s", live.Rate(row.Namespace, window, now)))
+			}
 		}
+		values = append(values, fmt.Sprintf("%-12s", strings.Join(row.Types[:], ",")))
 
 		// Update max widths
 		for j, val := range values {
@@ -125,6 +343,8 @@ func (ns *Namespace) Update(prefix models.Key, stats models.NamespaceMetricList)
 	// Set statsTable width to total width of columns
 	ns.Table.SetFixed(1, 0)
 	ns.Table.ScrollToBeginning()
+	row, _ := ns.Table.GetSelection()
+	ns.renderDetail(row)
 	separator := " › "
 	if len(prefix) == 0 {
 		ns.Title.SetText("[yellow:black]/ root[-]")
@@ -6,12 +6,29 @@ import (
 	"github.com/rivo/tview"
 	"redscout/models"
 	"strings"
+	"time"
 )
 
-const SlowLogHeader = "[yellow]Sort:[-] [yellow]1[-] ID  [yellow]2[-] Timestamp  [yellow]3[-] Duration  [yellow]4[-] Command  |  [yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR |  [yellow]T[-] Toggle View  |  [yellow]Q[-] Quit"
+const SlowLogHeader = "[yellow]Sort:[-] [yellow]1[-] ID  [yellow]2[-] Timestamp  [yellow]3[-] Duration  [yellow]4[-] Command  |  [yellow]G[-] Group  |  [yellow]/[-] Search  |  [yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR |  [yellow]T[-] Toggle View  |  [yellow]Q[-] Quit"
+
+const SlowLogGroupHeader = "[yellow]Sort:[-] [yellow]1[-] Count  [yellow]2[-] Total  [yellow]3[-] P95  [yellow]4[-] Max  |  [yellow]G[-] Entries  |  [yellow]/[-] Search  |  [yellow]S[-] +SCAN  |  [yellow]M[-] +MONITOR |  [yellow]T[-] Toggle View  |  [yellow]Q[-] Quit"
+
+// SlowLogGroupSortKeyMap maps the sort shortcuts of the grouped slow log to SlowLogGroupList keys.
+var SlowLogGroupSortKeyMap = map[rune]string{
+	'1': "Count",
+	'2': "Total",
+	'3': "P95",
+	'4': "Max",
+}
 
 type SlowLogTable struct {
-	Table *tview.Table
+	// Summary of the slow log polls above the table
+	Summary *tview.TextView
+	Table   *tview.Table
+	Flex    *tview.Flex
+	// Grouped shows the entries aggregated by command and namespace
+	Grouped   bool
+	groupSort string
 }
 
 func NewSlowLogTable() *SlowLogTable {
@@ -19,44 +36,70 @@ func NewSlowLogTable() *SlowLogTable {
 	table.SetTitle(" Slow Log (Press 1-5 to sort) ").SetTitleAlign(tview.AlignLeft)
 	table.SetSelectable(true, false)
 	table.SetBorders(false)
-	table.SetBorderPadding(0, 0, 1, 0)
-	return &SlowLogTable{table}
+	summary := tview.NewTextView().SetDynamicColors(true)
+	flex := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(summary, 1, 0, false).
+		AddItem(table, 0, 1, true)
+	flex.SetBorderPadding(0, 0, 1, 0)
+	return &SlowLogTable{Summary: summary, Table: table, Flex: flex, groupSort: "Total"}
+}
+
+// SetPolls shows how much of the slow log the session kept and missed.
+func (sl *SlowLogTable) SetPolls(polls models.SlowLogPolls, kept int, interval time.Duration) {
+	if polls.Polls == 0 {
+		sl.Summary.SetText("[yellow:black]Slow log not fetched yet[-]")
+		return
+	}
+	text := fmt.Sprintf("[yellow:black]%d entries kept, SLOWLOG LEN %d", kept, polls.Len)
+	if polls.Dropped > 0 {
+		text += fmt.Sprintf(", [red]%d rotated out unseen[yellow]", polls.Dropped)
+	}
+	if interval > 0 {
+		text += fmt.Sprintf(", polled every %v, last at %s", interval, polls.LastPoll.Format("15:04:05"))
+	} else {
+		text += fmt.Sprintf(", fetched once at %s", polls.LastPoll.Format("15:04:05"))
+	}
+	sl.Summary.SetText(text + "[-]")
+}
+
+// ToggleGrouped switches between the slow log entries and their aggregates, returning the
+// shortcuts of the new mode.
+func (sl *SlowLogTable) ToggleGrouped() string {
+	sl.Grouped = !sl.Grouped
+	sl.Table.Select(1, 0)
+	if sl.Grouped {
+		return SlowLogGroupHeader
+	}
+	return SlowLogHeader
+}
+
+// SortGroups sets the order of the grouped slow log.
+func (sl *SlowLogTable) SortGroups(key string) {
+	sl.groupSort = key
 }
 
 func (sl *SlowLogTable) Update(slowLogs models.SlowLogList) {
-	if slowLogs == nil || len(slowLogs) == 0 {
+	// Nil until the slow log is fetched, empty when a search matches nothing
+	if slowLogs == nil {
+		return
+	}
+	if sl.Grouped {
+		sl.updateGroups(slowLogs)
 		return
 	}
 
-	headers := []string{"ID", "Timestamp", "Duration", "Command", "Arguments"}
+	headers := []string{"ID", "Timestamp", "Duration", "Command", "Namespace", "Client", "Arguments"}
 	colors := []tcell.Color{
 		tcell.ColorWhite,
 		tcell.ColorYellow,
 		tcell.ColorTeal,
 		tcell.ColorLightGreen,
+		tcell.ColorAqua,
+		tcell.ColorGray,
 		tcell.ColorBlue,
 	}
 
-	// Set minimum column widths
-	colWidths := []int{8, 20, 12, 12, 30}
-
-	// Add header row
-	sl.Table.Clear()
-	for i, h := range headers {
-		align := tview.AlignLeft
-		if i != 0 && i != 4 {
-			align = tview.AlignRight
-		}
-		cell := tview.NewTableCell(fmt.Sprintf("[white::b]%s", h)).
-			SetTextColor(tcell.ColorWhite).
-			SetAttributes(tcell.AttrBold).
-			SetBackgroundColor(tcell.ColorTeal).
-			SetSelectable(false).
-			SetAlign(align)
-		sl.Table.SetCell(0, i, cell)
-	}
-
-	// Add data rows
+	rows := make([][]string, len(slowLogs))
 	for i, log := range slowLogs {
 		// Split command and arguments
 		command := ""
@@ -66,28 +109,80 @@ func (sl *SlowLogTable) Update(slowLogs models.SlowLogList) {
 			args = log.Args[1:]
 		}
 
-		values := []string{
+		rows[i] = []string{
 			fmt.Sprintf("%d ", log.ID),
 			log.Time.Format("2006-01-02 15:04:05"),
 			fmt.Sprintf("%12d ms", log.Duration.Milliseconds()),
 			command,
+			log.Namespace,
+			log.Client(),
 			strings.Join(args, " "),
 		}
+	}
+	sl.fill(headers, colors, rows)
+}
 
-		// Update max widths based on content
-		for j, val := range values {
-			contentWidth := len(val)
-			if contentWidth > colWidths[j] {
-				colWidths[j] = contentWidth
-			}
+// updateGroups shows the slow log aggregated by command and namespace.
+func (sl *SlowLogTable) updateGroups(slowLogs models.SlowLogList) {
+	groups := models.GroupSlowLog(slowLogs)
+	groups.Sort(sl.groupSort)
+
+	headers := []string{"Command", "Namespace", "Count", "Total", "P95", "Max", "Clients"}
+	colors := []tcell.Color{
+		tcell.ColorLightGreen,
+		tcell.ColorAqua,
+		tcell.ColorWhite,
+		tcell.ColorTeal,
+		tcell.ColorYellow,
+		tcell.ColorRed,
+		tcell.ColorGray,
+	}
+
+	rows := make([][]string, len(groups))
+	for i, g := range groups {
+		clients := strings.Join(g.Clients[:min(3, len(g.Clients))], ", ")
+		if len(g.Clients) > 3 {
+			clients += fmt.Sprintf(" +%d", len(g.Clients)-3)
+		}
+		rows[i] = []string{
+			g.Command,
+			g.Namespace,
+			fmt.Sprintf("%d", g.Count),
+			fmt.Sprintf("%d ms", g.Total.Milliseconds()),
+			fmt.Sprintf("%d ms", g.P95.Milliseconds()),
+			fmt.Sprintf("%d ms", g.Max.Milliseconds()),
+			clients,
 		}
+	}
+	sl.fill(headers, colors, rows)
+}
+
+// fill replaces the table with a header and rows, numbers aligned right and text left.
+func (sl *SlowLogTable) fill(headers []string, colors []tcell.Color, rows [][]string) {
+	leftAligned := map[string]bool{"ID": true, "Command": true, "Namespace": true, "Client": true, "Clients": true, "Arguments": true}
 
+	sl.Table.Clear()
+	for i, h := range headers {
+		align := tview.AlignRight
+		if leftAligned[h] {
+			align = tview.AlignLeft
+		}
+		cell := tview.NewTableCell(fmt.Sprintf("[white::b]%s", h)).
+			SetTextColor(tcell.ColorWhite).
+			SetAttributes(tcell.AttrBold).
+			SetBackgroundColor(tcell.ColorTeal).
+			SetSelectable(false).
+			SetAlign(align)
+		sl.Table.SetCell(0, i, cell)
+	}
+
+	for i, values := range rows {
 		for j, val := range values {
-			align := tview.AlignLeft
-			if j != 0 && j != 4 {
-				align = tview.AlignRight
+			align := tview.AlignRight
+			if leftAligned[headers[j]] {
+				align = tview.AlignLeft
 			}
-			cell := tview.NewTableCell(fmt.Sprintf("[%s]%s", colors[j], val)).
+			cell := tview.NewTableCell(fmt.Sprintf("[%s]%s", colors[j], tview.Escape(val))).
 				SetAlign(align).
 				SetExpansion(0).
 				SetBackgroundColor(tcell.ColorBlack)
@@ -96,15 +191,5 @@ func (sl *SlowLogTable) Update(slowLogs models.SlowLogList) {
 		}
 	}
 
-	// Set column widths with padding
-	totalWidth := 0
-	for i, width := range colWidths {
-		width += 2 // Add padding
-		sl.Table.SetCell(0, i, sl.Table.GetCell(0, i).SetExpansion(0))
-		sl.Table.SetCell(0, i, sl.Table.GetCell(0, i).SetMaxWidth(width))
-		totalWidth += width
-	}
-
-	// Set table width to total width of columns
 	sl.Table.SetFixed(1, 0)
 }
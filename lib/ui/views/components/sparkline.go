@@ -0,0 +1,71 @@
+package components
+
+import (
+	"redscout/lib/utils"
+	"strings"
+)
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a row of block characters scaled to the largest value.
+func Sparkline(values []float64) string {
+	maxVal := 0.0
+	for _, v := range values {
+		maxVal = max(maxVal, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		if maxVal <= 0 || v <= 0 {
+			sb.WriteRune(' ')
+			continue
+		}
+		idx := int(v / maxVal * float64(len(sparkBlocks)-1))
+		sb.WriteRune(sparkBlocks[idx])
+	}
+	return sb.String()
+}
+
+// Chart renders values as a block chart height rows tall, one column per value, scaled to the
+// largest value.
+func Chart(values []float64, height int, color string) string {
+	maxVal := 0.0
+	for _, v := range values {
+		maxVal = max(maxVal, v)
+	}
+
+	levels := len(sparkBlocks)
+	var sb strings.Builder
+	for row := height - 1; row >= 0; row-- {
+		sb.WriteString(" [" + color + "]")
+		for _, v := range values {
+			// Eighths of a row filled by this value, counted from the bottom row
+			filled := 0
+			if maxVal > 0 {
+				filled = int(v/maxVal*float64(height*levels)) - row*levels
+			}
+			switch {
+			case filled >= levels:
+				sb.WriteRune(sparkBlocks[levels-1])
+			case filled > 0:
+				sb.WriteRune(sparkBlocks[filled-1])
+			default:
+				sb.WriteRune(' ')
+			}
+		}
+		sb.WriteString("[-]\n")
+	}
+	return sb.String()
+}
+
+// BucketLabels names histogram buckets bounded above by bounds in seconds, e.g. ≤1m … >30d.
+func BucketLabels(bounds []int64) []string {
+	labels := make([]string, 0, len(bounds)+1)
+	for _, b := range bounds {
+		labels = append(labels, "≤"+utils.FormatDuration(b))
+	}
+	if len(bounds) > 0 {
+		labels = append(labels, ">"+utils.FormatDuration(bounds[len(bounds)-1]))
+	}
+	return labels
+}
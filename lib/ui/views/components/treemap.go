@@ -0,0 +1,294 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"redscout/lib/utils"
+	"redscout/models"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const TreemapShortcutsText = "[yellow]Arrows[-] Select  |  [yellow]Enter/Click[-] Drill Down  [yellow]Backspace[-] Level Up  |  [yellow]C[-] Color by Ops/TTL  |  [yellow]Q[-] Quit"
+
+// Tile color scales
+const (
+	TreemapColorOps = "ops"
+	TreemapColorTTL = "ttl"
+)
+
+// Most sub-namespace tiles drawn inside a namespace tile
+const treemapMaxChildren = 12
+
+type treemapTile struct {
+	index    int
+	metrics  *models.NamespaceMetrics
+	x, y     int
+	w, h     int
+	children []treemapChild
+}
+
+type treemapChild struct {
+	name       string
+	x, y, w, h int
+}
+
+// Treemap draws the namespaces of the current prefix as tiles sized by estimated memory, each
+// split into its sub-namespaces, and colored by ops/sec or TTL percent.
+type Treemap struct {
+	*tview.Box
+
+	stats    models.NamespaceMetricList
+	tiles    []treemapTile
+	selected int
+	ColorBy  string
+
+	drillDown func(namespace string)
+	levelUp   func()
+}
+
+func NewTreemap() *Treemap {
+	t := &Treemap{
+		Box:       tview.NewBox(),
+		ColorBy:   TreemapColorOps,
+		drillDown: func(string) {},
+		levelUp:   func() {},
+	}
+	t.SetBorder(true).SetTitleAlign(tview.AlignLeft)
+	t.updateTitle()
+	return t
+}
+
+// SetHandlers sets what Enter or a click on a tile, and Backspace, do.
+func (t *Treemap) SetHandlers(drillDown func(namespace string), levelUp func()) {
+	t.drillDown = drillDown
+	t.levelUp = levelUp
+}
+
+// Update replaces the namespaces shown, keeping the selection on the same namespace.
+func (t *Treemap) Update(stats models.NamespaceMetricList) {
+	var selected string
+	if t.selected < len(t.stats) {
+		selected = t.stats[t.selected].Namespace
+	}
+
+	t.stats = make(models.NamespaceMetricList, 0, len(stats))
+	for _, m := range stats {
+		if m.EstMemory > 0 {
+			t.stats = append(t.stats, m)
+		}
+	}
+	sort.SliceStable(t.stats, func(i, j int) bool { return t.stats[i].EstMemory > t.stats[j].EstMemory })
+
+	t.selected = 0
+	for i, m := range t.stats {
+		if m.Namespace == selected {
+			t.selected = i
+		}
+	}
+}
+
+// ToggleColor switches the tile colors between ops/sec and TTL percent.
+func (t *Treemap) ToggleColor() {
+	if t.ColorBy == TreemapColorOps {
+		t.ColorBy = TreemapColorTTL
+	} else {
+		t.ColorBy = TreemapColorOps
+	}
+	t.updateTitle()
+}
+
+func (t *Treemap) updateTitle() {
+	by := "Ops/s"
+	if t.ColorBy == TreemapColorTTL {
+		by = "% TTL"
+	}
+	t.SetTitle(fmt.Sprintf(" Memory Treemap (colored by %s) ", by))
+}
+
+// layout places the tiles in the area of the box. Terminal cells are about twice as tall as
+// they are wide, so rows count double to keep tiles looking square.
+func (t *Treemap) layout(x, y, width, height int) {
+	values := make([]float64, len(t.stats))
+	for i, m := range t.stats {
+		values[i] = float64(m.EstMemory)
+	}
+
+	t.tiles = t.tiles[:0]
+	for i, r := range models.Squarify(values, models.Rect{W: float64(width), H: float64(height * 2)}) {
+		tile := treemapTile{index: i, metrics: t.stats[i]}
+		tile.x, tile.w = cellSpan(x, r.X, r.W)
+		tile.y, tile.h = cellSpan(y, r.Y/2, r.H/2)
+		if tile.w == 0 || tile.h == 0 {
+			continue
+		}
+		// Leave a column between neighbouring tiles, which may share a color
+		if tile.w > 2 {
+			tile.w--
+		}
+		// The first row holds the label, sub-namespaces fill the rest
+		if tile.h > 2 && tile.w > 4 {
+			tile.children = layoutChildren(t.stats[i].ChildMemory, tile.x, tile.y+1, tile.w, tile.h-1)
+		}
+		t.tiles = append(t.tiles, tile)
+	}
+}
+
+func layoutChildren(childMemory map[string]int64, x, y, width, height int) []treemapChild {
+	names := make([]string, 0, len(childMemory))
+	for name := range childMemory {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return childMemory[names[i]] > childMemory[names[j]] })
+	if len(names) > treemapMaxChildren {
+		names = names[:treemapMaxChildren]
+	}
+
+	values := make([]float64, len(names))
+	for i, name := range names {
+		values[i] = float64(childMemory[name])
+	}
+	var children []treemapChild
+	for i, r := range models.Squarify(values, models.Rect{W: float64(width), H: float64(height * 2)}) {
+		child := treemapChild{name: names[i]}
+		child.x, child.w = cellSpan(x, r.X, r.W)
+		child.y, child.h = cellSpan(y, r.Y/2, r.H/2)
+		if child.w > 0 && child.h > 0 {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// cellSpan rounds a span of the layout to whole cells, so that adjacent tiles share edges.
+func cellSpan(origin int, start, size float64) (int, int) {
+	from := int(math.Round(start))
+	to := int(math.Round(start + size))
+	return origin + from, to - from
+}
+
+// heat returns a value in [0, 1] for the tile color of a namespace.
+func (t *Treemap) heat(m *models.NamespaceMetrics) float64 {
+	if t.ColorBy == TreemapColorTTL {
+		// Keys without a TTL are the ones to look at, so less TTL is hotter
+		return 1 - m.TTLPercent
+	}
+	var maxOps float64
+	for _, s := range t.stats {
+		maxOps = max(maxOps, s.Ops[models.TotalOp])
+	}
+	if maxOps == 0 {
+		return 0
+	}
+	return m.Ops[models.TotalOp] / maxOps
+}
+
+// heatColor goes from green through yellow to red.
+func heatColor(heat float64, shade float64) tcell.Color {
+	r := math.Min(1, 2*heat)
+	g := math.Min(1, 2*(1-heat))
+	return tcell.NewRGBColor(int32(r*160*shade), int32(g*140*shade), int32(40*shade))
+}
+
+func (t *Treemap) Draw(screen tcell.Screen) {
+	t.Box.DrawForSubclass(screen, t)
+	x, y, width, height := t.GetInnerRect()
+	if len(t.stats) == 0 {
+		tview.Print(screen, "No namespace memory to show", x, y, width, tview.AlignCenter, tcell.ColorGray)
+		return
+	}
+	t.layout(x, y, width, height)
+
+	for _, tile := range t.tiles {
+		bg := heatColor(t.heat(tile.metrics), 1)
+		fill(screen, tile.x, tile.y, tile.w, tile.h, tcell.StyleDefault.Background(bg))
+
+		for j, child := range tile.children {
+			// Alternate shades so that neighbouring sub-namespaces stand apart
+			shade := 0.75
+			if j%2 == 1 {
+				shade = 0.6
+			}
+			style := tcell.StyleDefault.Background(heatColor(t.heat(tile.metrics), shade)).Foreground(tcell.ColorSilver)
+			fill(screen, child.x, child.y, child.w, child.h, style)
+			printCells(screen, child.name, child.x, child.y, child.w, style)
+		}
+
+		label := fmt.Sprintf("%s %s", tile.metrics.Namespace, utils.FormatBytes(tile.metrics.EstMemory))
+		style := tcell.StyleDefault.Background(bg).Foreground(tcell.ColorWhite).Bold(true)
+		if tile.index == t.selected {
+			style = style.Reverse(true)
+		}
+		printCells(screen, label, tile.x, tile.y, tile.w, style)
+	}
+}
+
+func fill(screen tcell.Screen, x, y, w, h int, style tcell.Style) {
+	for row := y; row < y+h; row++ {
+		for col := x; col < x+w; col++ {
+			screen.SetContent(col, row, ' ', nil, style)
+		}
+	}
+}
+
+// printCells writes text from x, cut to width cells.
+func printCells(screen tcell.Screen, text string, x, y, width int, style tcell.Style) {
+	col := 0
+	for _, r := range text {
+		if col >= width {
+			break
+		}
+		screen.SetContent(x+col, y, r, nil, style)
+		col++
+	}
+}
+
+// Selected returns the namespace of the selected tile.
+func (t *Treemap) Selected() (string, bool) {
+	if t.selected >= len(t.stats) {
+		return "", false
+	}
+	return t.stats[t.selected].Namespace, true
+}
+
+func (t *Treemap) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return t.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		if len(t.stats) == 0 {
+			if event.Key() == tcell.KeyBackspace || event.Key() == tcell.KeyBackspace2 {
+				t.levelUp()
+			}
+			return
+		}
+		switch event.Key() {
+		case tcell.KeyRight, tcell.KeyDown, tcell.KeyTab:
+			t.selected = (t.selected + 1) % len(t.stats)
+		case tcell.KeyLeft, tcell.KeyUp, tcell.KeyBacktab:
+			t.selected = (t.selected + len(t.stats) - 1) % len(t.stats)
+		case tcell.KeyEnter:
+			if namespace, ok := t.Selected(); ok {
+				t.drillDown(namespace)
+			}
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			t.levelUp()
+		}
+	})
+}
+
+func (t *Treemap) MouseHandler() func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (bool, tview.Primitive) {
+	return t.WrapMouseHandler(func(action tview.MouseAction, event *tcell.EventMouse, setFocus func(p tview.Primitive)) (bool, tview.Primitive) {
+		if action != tview.MouseLeftClick || !t.InRect(event.Position()) {
+			return false, nil
+		}
+		setFocus(t)
+		mx, my := event.Position()
+		for _, tile := range t.tiles {
+			if mx >= tile.x && mx < tile.x+tile.w && my >= tile.y && my < tile.y+tile.h {
+				t.drillDown(tile.metrics.Namespace)
+				break
+			}
+		}
+		return true, nil
+	})
+}
@@ -47,32 +47,42 @@ func NewHeaderView() *HeaderView {
 }
 
 func (header *HeaderView) Update(state *models.State) {
-	header.updateHeaderSystemView(state.RedisInfo)
-	header.updateHeaderPerformanceView(state.RedisInfo)
+	header.updateHeaderSystemView(state)
+	header.updateHeaderPerformanceView(state.RedisInfo, state.DBs)
 	header.updateHeaderResourcesView(state.RedisInfo)
 	header.updateLogs(state)
 }
 
-func (header *HeaderView) updateHeaderSystemView(info *models.RedisInfo) {
+func (header *HeaderView) updateHeaderSystemView(state *models.State) {
+	info := state.RedisInfo
 	uptime := time.Duration(info.Server.Uptime) * time.Second
-	text := fmt.Sprintf(" [teal]Redis: [-][white]v%s[-]\n [teal]OS:[-][white] %s[-]\n [teal]Uptime:[-][white] %s[-]\n [teal]Clients:[-][white] %d[-]",
+	writes := "[red]allowed[-]"
+	if state.ReadOnly {
+		writes = "[green]blocked[-]"
+	}
+	text := fmt.Sprintf(" [teal]Redis: [-][white]v%s[-]\n [teal]OS:[-][white] %s[-]\n [teal]Uptime:[-][white] %s[-]\n [teal]Clients:[-][white] %d[-]\n [teal]Writes:[-] %s, [white]ACL %s[-]",
 		info.Server.RedisVersion,
 		info.Server.OS,
 		utils.FormatDuration(int64(uptime.Seconds())),
 		info.Clients.ConnectedClients,
+		writes,
+		state.ACL,
 	)
 	header.system.SetText(text)
 }
 
-func (header *HeaderView) updateHeaderPerformanceView(info *models.RedisInfo) {
-	totalKeys := info.Keyspace["db0"].Keys
-	avgTTL := info.Keyspace["db0"].AvgTTL
+func (header *HeaderView) updateHeaderPerformanceView(info *models.RedisInfo, dbs []int) {
+	keyspace := info.KeyspaceTotals(dbs)
+	totalKeys := keyspace.Keys
+	avgTTL := keyspace.AvgTTL
 
-	text := fmt.Sprintf(" [teal]Total Keys:[-][white] %s[-]\n [teal]Ops:[-][white] %s[-]\n [teal]Hit Rate:[-][white] %.1f%%[-]\n [teal]Avg TTL:[-][white] %s[-]",
+	text := fmt.Sprintf(" [teal]Total Keys:[-][white] %s[-]\n [teal]Ops:[-][white] %s[-]  [teal]Hit Rate:[-][white] %.1f%%[-]\n [teal]Avg TTL:[-][white] %s[-]\n [teal]Evicted:[-][white] %s[-]  [teal]Expired:[-][white] %s[-]",
 		utils.FormatNumber(float64(totalKeys)),
 		utils.FormatOpsPerSec(float64(info.Stats.OpsPerSec)),
 		info.Computed.HitRate*100,
 		utils.FormatDuration(avgTTL),
+		utils.FormatOpsPerSec(info.Computed.EvictedPerSec),
+		utils.FormatOpsPerSec(info.Computed.ExpiredPerSec),
 	)
 	header.performance.SetText(text)
 }
@@ -98,12 +108,31 @@ func (header *HeaderView) updateHeaderResourcesView(info *models.RedisInfo) {
 }
 
 func (header *HeaderView) updateLogs(state *models.State) {
+	cursor := fmt.Sprintf("%d", state.Cursors[state.ScanDB])
+	if state.DBLevel {
+		cursor = fmt.Sprintf("%s:%s", models.DBName(state.ScanDB), cursor)
+	}
+
 	text := fmt.Sprintf(
-		" [teal]Keys Scanned:[-] %d\n [teal]Monitored Duration:[-] %s\n [teal]Scan Cursor:[-] %d\n [teal]Logs:[-] %s\n",
+		" [teal]Keys Scanned:[-] %d\n [teal]Monitored Duration:[-] %s\n [teal]Scan Cursor:[-] %s\n [teal]Logs:[-] %s\n [teal]Load Guard:[-] %s",
 		state.ScannedKeys,
 		utils.FormatDuration(int64(state.TotalMonitorDuration.Seconds())),
-		state.Cursor,
+		cursor,
 		state.Status,
+		loadGuardText(state.LoadGuard),
 	)
 	header.logs.SetText(text)
 }
+
+func loadGuardText(guard models.LoadGuardState) string {
+	switch {
+	case !guard.Enabled:
+		return "[gray]off[-]"
+	case guard.Paused:
+		return fmt.Sprintf("[red]paused %s[-] (%s)", utils.FormatDuration(int64(time.Since(guard.Since).Seconds())), guard.Reason)
+	case guard.Sample.At.IsZero():
+		return "[green]ok[-]"
+	default:
+		return fmt.Sprintf("[green]ok[-] (PING %v, CPU %.0f%%, %d pauses)", guard.Sample.RTT.Round(time.Millisecond), guard.Sample.CPU*100, guard.Pauses)
+	}
+}
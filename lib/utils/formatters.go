@@ -18,6 +18,14 @@ func FormatBytes(b int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
 }
 
+// FormatSignedBytes formats a change in bytes with its sign.
+func FormatSignedBytes(b int64) string {
+	if b < 0 {
+		return "-" + FormatBytes(-b)
+	}
+	return "+" + FormatBytes(b)
+}
+
 func FormatNumber(n float64) string {
 	switch {
 	case n >= 1_000_000_000:
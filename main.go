@@ -9,6 +9,13 @@ import (
 
 func main() {
 	log.SetFlags(0)
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		if err := lib.RunHistory(os.Args[2:]); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+
 	for _, arg := range os.Args {
 		if arg == "--help" {
 			os.Setenv("TVIEW_DISABLE", "1")
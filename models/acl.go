@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ACLStatus is whether the ACL user RedScout is connected as can write data. Checked is false
+// when the user may not read its own rules, or the server wasn't asked.
+type ACLStatus struct {
+	User     string
+	Checked  bool
+	CanWrite bool
+	Err      error
+}
+
+func (a ACLStatus) String() string {
+	switch {
+	case !a.Checked && a.Err != nil:
+		return "user unknown"
+	case !a.Checked:
+		return "not checked"
+	case a.CanWrite:
+		return fmt.Sprintf("user %s can write", a.User)
+	default:
+		return fmt.Sprintf("user %s is read-only", a.User)
+	}
+}
+
+// aclWriteCategories are the ACL categories that include commands writing data, besides @all
+// and @scripting.
+var aclWriteCategories = map[string]bool{
+	"write":       true,
+	"keyspace":    true,
+	"string":      true,
+	"hash":        true,
+	"list":        true,
+	"set":         true,
+	"sortedset":   true,
+	"stream":      true,
+	"bitmap":      true,
+	"hyperloglog": true,
+	"geo":         true,
+	"dangerous":   true,
+}
+
+// aclWriteCommands are common commands writing data, for rules granting single commands.
+var aclWriteCommands = map[string]bool{
+	"set": true, "setex": true, "psetex": true, "setnx": true, "mset": true, "append": true,
+	"incr": true, "incrby": true, "decr": true, "decrby": true, "getdel": true, "getex": true,
+	"del": true, "unlink": true, "expire": true, "pexpire": true, "expireat": true, "persist": true,
+	"rename": true, "copy": true, "move": true, "restore": true, "flushdb": true, "flushall": true,
+	"hset": true, "hdel": true, "hincrby": true, "lpush": true, "rpush": true, "lpop": true,
+	"rpop": true, "lset": true, "ltrim": true, "sadd": true, "srem": true, "spop": true,
+	"zadd": true, "zrem": true, "zincrby": true, "xadd": true, "xdel": true, "xtrim": true,
+}
+
+// aclScriptCommands run scripts, which may write whatever the other rules deny.
+var aclScriptCommands = map[string]bool{"eval": true, "evalsha": true, "fcall": true}
+
+// ACLRulesCanWrite reports whether the command rules of an ACL user, as listed by ACL GETUSER,
+// allow any command that writes data. Rules apply in order, so a later -@write takes back the
+// writes of an earlier +@all. Scripts aren't in @write, so they are only taken back by
+// -@scripting or their own command rules. Subcommand and first-arg rules are ignored.
+func ACLRulesCanWrite(rules string) bool {
+	canWrite, canScript := false, false
+	for _, rule := range strings.Fields(strings.ToLower(rules)) {
+		switch {
+		case rule == "allcommands" || rule == "+@all":
+			canWrite, canScript = true, true
+		case rule == "nocommands" || rule == "-@all":
+			canWrite, canScript = false, false
+		case rule == "+@scripting":
+			canScript = true
+		case rule == "-@scripting":
+			canScript = false
+		case strings.HasPrefix(rule, "+@"):
+			if aclWriteCategories[rule[2:]] {
+				canWrite = true
+			}
+		case rule == "-@write":
+			canWrite = false
+		case strings.HasPrefix(rule, "+"):
+			if aclScriptCommands[rule[1:]] {
+				canScript = true
+			} else if aclWriteCommands[rule[1:]] {
+				canWrite = true
+			}
+		}
+	}
+	return canWrite || canScript
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Kinds of write actions
+const (
+	ActionExpire = "expire"
+	ActionUnlink = "unlink"
+)
+
+// BulkAction expires or unlinks every key of a namespace, found with SCAN MATCH Pattern in DB
+// and checked against Prefix. A dry run only counts them.
+type BulkAction struct {
+	Kind    string
+	TTL     time.Duration
+	DryRun  bool
+	DB      int
+	Pattern string
+	Prefix  Key
+}
+
+// ActionProgress is how far the running or last bulk action got. Total is the number of keys
+// in its database when it started, and Scanned estimates how many of them SCAN went through.
+type ActionProgress struct {
+	Action   BulkAction
+	Total    int64
+	Scanned  int64
+	Matched  int64
+	Done     int64
+	Finished bool
+	Err      error
+}
+
+// globEscaper escapes the characters SCAN MATCH treats as a pattern.
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// NamespacePattern returns the SCAN MATCH pattern of the keys below prefix. Parts inferred as
+// IDs match any value, which may span delimiters, so matches are to be checked with Covers.
+func NamespacePattern(prefix Key, delimiter string) string {
+	globs := make([]string, len(prefix))
+	for i, part := range prefix {
+		if part == patternPlaceholder {
+			globs[i] = "*"
+		} else {
+			globs[i] = globEscaper.Replace(part)
+		}
+	}
+	return strings.Join(globs, delimiter) + delimiter + "*"
+}
+
+// Covers reports whether the action applies to a key, which must be below its prefix. A key
+// equal to the prefix names the namespace but isn't part of it.
+func (a BulkAction) Covers(kp *KeyParser, k Key) bool {
+	return len(k) > len(a.Prefix) && kp.IsA(k, a.Prefix)
+}
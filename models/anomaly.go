@@ -0,0 +1,137 @@
+package models
+
+import (
+	"math"
+	"sort"
+)
+
+// Metrics a namespace baseline tracks
+const (
+	MetricMemory     = "Memory"
+	MetricKeys       = "Keys"
+	MetricTTLPercent = "TTL %"
+	MetricOps        = "Ops/s"
+)
+
+const (
+	// Weight of the newest run in a baseline
+	BaselineAlpha = 0.3
+	// Runs a baseline needs before namespaces are scored against it
+	BaselineWarmup = 3
+	// Standard deviations from the baseline mean that make a metric anomalous
+	AnomalyThreshold = 3.0
+	// Smallest deviation taken as the spread, relative to the mean, so that a metric that has
+	// been flat isn't flagged for a tiny change
+	minRelativeSpread = 0.05
+	// Smallest spread, for metrics that have always been zero
+	minSpread = 1
+)
+
+// EWMStat is an exponentially weighted moving mean and variance.
+type EWMStat struct {
+	Mean float64
+	Var  float64
+}
+
+// Update folds in a new value, the first one setting the mean.
+func (s *EWMStat) Update(x float64, first bool) {
+	if first {
+		s.Mean, s.Var = x, 0
+		return
+	}
+	diff := x - s.Mean
+	incr := BaselineAlpha * diff
+	s.Mean += incr
+	s.Var = (1 - BaselineAlpha) * (s.Var + diff*incr)
+}
+
+// Score is how many standard deviations x is from the mean.
+func (s EWMStat) Score(x float64) float64 {
+	spread := max(math.Sqrt(s.Var), minRelativeSpread*math.Abs(s.Mean), minSpread)
+	return (x - s.Mean) / spread
+}
+
+// NamespaceBaseline is the usual value of each metric of a namespace over past runs.
+type NamespaceBaseline struct {
+	Runs  int
+	Stats map[string]*EWMStat
+}
+
+// Baselines are the namespace baselines of a source, keyed by top level namespace.
+type Baselines map[string]*NamespaceBaseline
+
+func namespaceMetricValues(m *NamespaceMetrics, withOps bool) map[string]float64 {
+	values := map[string]float64{
+		MetricMemory:     float64(m.EstMemory),
+		MetricKeys:       float64(m.EstKeys),
+		MetricTTLPercent: m.TTLPercent * 100,
+	}
+	// A run that tracked no ops says nothing about them
+	if withOps {
+		values[MetricOps] = m.Ops[TotalOp]
+	}
+	return values
+}
+
+// Update folds the metrics of a completed run into the baselines.
+func (b Baselines) Update(metrics NamespaceMetricList, withOps bool) {
+	for _, m := range metrics {
+		baseline, ok := b[m.Namespace]
+		if !ok {
+			baseline = &NamespaceBaseline{Stats: make(map[string]*EWMStat)}
+			b[m.Namespace] = baseline
+		}
+		for metric, value := range namespaceMetricValues(m, withOps) {
+			stat, ok := baseline.Stats[metric]
+			if !ok {
+				stat = &EWMStat{}
+				baseline.Stats[metric] = stat
+			}
+			stat.Update(value, !ok)
+		}
+		baseline.Runs++
+	}
+}
+
+// Anomaly is a namespace metric far from its baseline.
+type Anomaly struct {
+	Namespace string
+	Metric    string
+	Value     float64
+	Mean      float64
+	Score     float64
+}
+
+type AnomalyList []Anomaly
+
+// Score returns the metrics deviating strongly from their baseline, most deviating first.
+// Namespaces without a warmed up baseline aren't scored.
+func (b Baselines) Score(metrics NamespaceMetricList, withOps bool) AnomalyList {
+	var anomalies AnomalyList
+	for _, m := range metrics {
+		baseline, ok := b[m.Namespace]
+		if !ok || baseline.Runs < BaselineWarmup {
+			continue
+		}
+		for metric, value := range namespaceMetricValues(m, withOps) {
+			stat, ok := baseline.Stats[metric]
+			if !ok {
+				continue
+			}
+			score := stat.Score(value)
+			if math.Abs(score) >= AnomalyThreshold {
+				anomalies = append(anomalies, Anomaly{
+					Namespace: m.Namespace,
+					Metric:    metric,
+					Value:     value,
+					Mean:      stat.Mean,
+					Score:     score,
+				})
+			}
+		}
+	}
+	sort.Slice(anomalies, func(i, j int) bool {
+		return math.Abs(anomalies[i].Score) > math.Abs(anomalies[j].Score)
+	})
+	return anomalies
+}
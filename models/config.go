@@ -1,26 +1,72 @@
 package models
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"time"
 )
 
+// Sources of live ops: MONITOR sees every command, keyspace notifications only writes
+const (
+	OpsSourceMonitor  = "monitor"
+	OpsSourceKeyspace = "keyspace"
+)
+
 type Config struct {
 	RedisHost       string
 	RedisPort       int
 	RedisUser       string
 	RedisPassword   string
 	RedisDB         int
+	AllDBs          bool
 	UseTLS          bool
 	KeysScanSize    int64
 	MonitorDuration time.Duration
+	OpsSource       string
+	// Track keys expired or evicted while MONITOR runs, which enables keyspace notifications
+	TrackRemovals bool
+	// Keep streaming ops, for DutyCycle percent of every MonitorDuration
+	Live            bool
+	DutyCycle       int
 	RefreshInterval time.Duration
+	ColdAfter       time.Duration
 	Delimiter       string
 	LogsDir         string
 	TopK            int64
 	IDPatterns      []*regexp.Regexp
+
+	// Slow log polled every SlowLogInterval, 0 to fetch it once, keeping the newest SlowLogMax
+	// entries of the session and appending every new one to SlowLogFile unless empty
+	SlowLogInterval time.Duration
+	SlowLogMax      int
+	SlowLogFile     string
+
+	// Offline sources read instead of querying a live server
+	RDBFile string
+	AOFPath string
+	// Captured MONITOR output, "-" for stdin, replayed instead of running MONITOR
+	MonitorFile string
+	// Never connect to Redis, even without an offline scan or ops source
+	NoConnect bool
+
+	// Database every run's results are kept in, empty to keep nothing
+	HistoryDB string
+
+	// Server load above which scanning and monitoring back off
+	LoadGuard LoadThresholds
+
+	// latency-monitor-threshold set from the Latency tab while the monitor is disabled
+	LatencyThreshold time.Duration
+
+	// Refuse every command outside the read-only allow-list at the client
+	ReadOnly bool
+
+	// Allow expiring and unlinking keys from the UI, each write logged to AuditLog
+	AllowWrites bool
+	AuditLog    string
 }
 
 func DefaultConfig() Config {
@@ -49,13 +95,73 @@ func DefaultConfig() Config {
 		RedisUser:       "default",
 		RedisPassword:   password,
 		RedisDB:         0,
+		AllDBs:          false,
 		UseTLS:          false,
 		KeysScanSize:    5000,
 		MonitorDuration: 10 * time.Second,
+		OpsSource:       OpsSourceMonitor,
+		TrackRemovals:   false,
+		Live:            false,
+		DutyCycle:       100,
 		RefreshInterval: 5 * time.Second,
+		ColdAfter:       7 * 24 * time.Hour,
 		Delimiter:       ":",
 		LogsDir:         os.TempDir(),
 		TopK:            100,
 		IDPatterns:      []*regexp.Regexp{},
+		SlowLogInterval: 10 * time.Second,
+		SlowLogMax:      10000,
+		SlowLogFile:     "",
+		RDBFile:         "",
+		AOFPath:         "",
+		MonitorFile:     "",
+		NoConnect:       false,
+		HistoryDB:       defaultDataFile("history.db"),
+		ReadOnly:        true,
+		AllowWrites:     false,
+		AuditLog:        defaultDataFile("audit.log"),
+		LoadGuard: LoadThresholds{
+			Enabled:         true,
+			MaxRTT:          50 * time.Millisecond,
+			MaxEventLatency: 100 * time.Millisecond,
+			MaxCPU:          0.8,
+		},
+		LatencyThreshold: 100 * time.Millisecond,
+	}
+}
+
+// defaultDataFile is where RedScout keeps a file across runs, empty when there is no user
+// config directory.
+func defaultDataFile(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "redscout", name)
+}
+
+// HistorySource names a database of what is analyzed, so runs against the same server or dump
+// are compared whether the database was analyzed alone or with --all-dbs. Dumps are named by
+// absolute path, as dumps of different servers often share a base name.
+func (c *Config) HistorySource(db int) string {
+	switch {
+	case c.RDBFile != "":
+		return fmt.Sprintf("rdb:%s/%d", absPath(c.RDBFile), db)
+	case c.AOFPath != "":
+		return fmt.Sprintf("aof:%s/%d", absPath(c.AOFPath), db)
+	default:
+		return fmt.Sprintf("%s:%d/%d", c.RedisHost, c.RedisPort, db)
 	}
 }
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// Offline reports whether the analysis runs from local files without connecting to Redis.
+func (c *Config) Offline() bool {
+	return c.NoConnect || c.RDBFile != "" || c.AOFPath != ""
+}
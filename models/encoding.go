@@ -0,0 +1,241 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+)
+
+// EncodingThreshold is the element count above which a collection type converts from its
+// compact encoding (listpack, ziplist or intset) to a hashtable or skiplist. It also converts
+// once a single field, value or member is longer than MaxValue bytes. Intset has no such setting,
+// its MaxValue is the longest integer, as only integer members fit it.
+type EncodingThreshold struct {
+	Config     string
+	MaxEntries int64
+
+	ValueConfig string
+	MaxValue    int64
+}
+
+// EncodingThresholds holds the compact encoding threshold of each collection type. Sets have
+// two: "set" for listpack and IntsetThreshold for integer-only sets.
+type EncodingThresholds map[string]EncodingThreshold
+
+// IntsetThreshold keys the set-max-intset-entries threshold
+const IntsetThreshold = "intset"
+
+// Config names checked for each type, newest first
+var encodingThresholdConfigs = map[string][]string{
+	"hash":          {"hash-max-listpack-entries", "hash-max-ziplist-entries"},
+	"zset":          {"zset-max-listpack-entries", "zset-max-ziplist-entries"},
+	"set":           {"set-max-listpack-entries"},
+	IntsetThreshold: {"set-max-intset-entries"},
+}
+
+// Value config names checked for each type, newest first
+var encodingValueConfigs = map[string][]string{
+	"hash": {"hash-max-listpack-value", "hash-max-ziplist-value"},
+	"zset": {"zset-max-listpack-value", "zset-max-ziplist-value"},
+	"set":  {"set-max-listpack-value"},
+}
+
+// Approximate bytes a hashtable or skiplist entry takes besides its strings: the dict entry,
+// its bucket and the sds headers, plus the skiplist node for a zset. Hash entries hold two
+// strings, a field and a value.
+var (
+	entryOverhead = map[string]int64{"hash": 48, "set": 40, "zset": 96}
+	entryStrings  = map[string]int64{"hash": 2, "set": 1, "zset": 1}
+)
+
+func DefaultEncodingThresholds() EncodingThresholds {
+	return EncodingThresholds{
+		"hash":          {Config: "hash-max-listpack-entries", MaxEntries: 128, ValueConfig: "hash-max-listpack-value", MaxValue: 64},
+		"zset":          {Config: "zset-max-listpack-entries", MaxEntries: 128, ValueConfig: "zset-max-listpack-value", MaxValue: 64},
+		"set":           {Config: "set-max-listpack-entries", MaxEntries: 128, ValueConfig: "set-max-listpack-value", MaxValue: 64},
+		IntsetThreshold: {Config: "set-max-intset-entries", MaxEntries: 512, MaxValue: int64(len("-9223372036854775808"))},
+	}
+}
+
+// firstConfig returns the first of names set to an integer in a CONFIG GET reply.
+func firstConfig(config map[string]string, names []string) (string, int64, bool) {
+	for _, name := range names {
+		val, ok := config[name]
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return name, n, true
+		}
+	}
+	return "", 0, false
+}
+
+// ParseEncodingThresholds reads the thresholds from a CONFIG GET *-max-* reply, keeping the
+// defaults for anything missing.
+func ParseEncodingThresholds(config map[string]string) EncodingThresholds {
+	thresholds := DefaultEncodingThresholds()
+	for keyType, names := range encodingThresholdConfigs {
+		if name, entries, ok := firstConfig(config, names); ok {
+			threshold := thresholds[keyType]
+			threshold.Config, threshold.MaxEntries = name, entries
+			thresholds[keyType] = threshold
+		}
+	}
+	for keyType, names := range encodingValueConfigs {
+		if name, value, ok := firstConfig(config, names); ok {
+			threshold := thresholds[keyType]
+			threshold.ValueConfig, threshold.MaxValue = name, value
+			thresholds[keyType] = threshold
+		}
+	}
+	// Before Redis 7.2 sets had no listpack encoding, only intset
+	_, hasIntset := config["set-max-intset-entries"]
+	if _, ok := config["set-max-listpack-entries"]; hasIntset && !ok {
+		delete(thresholds, "set")
+	}
+	return thresholds
+}
+
+func IsCompactEncoding(encoding string) bool {
+	switch encoding {
+	case "listpack", "ziplist", "intset":
+		return true
+	default:
+		return false
+	}
+}
+
+// Threshold returns the name of the threshold a key of this size is checked against. Integer-only
+// sets stay intset up to set-max-intset-entries, above set-max-listpack-entries, so a set past
+// the intset threshold is taken as an intset that outgrew it; a smaller one can only have
+// outgrown listpack.
+func (t EncodingThresholds) Threshold(keyType string, elements int64) (string, bool) {
+	if keyType == "set" {
+		if intset, ok := t[IntsetThreshold]; ok && elements > intset.MaxEntries {
+			return IntsetThreshold, true
+		}
+	}
+	_, ok := t[keyType]
+	return keyType, ok
+}
+
+// NearThreshold reports whether a key has just outgrown the compact encoding of its type,
+// i.e. it is not compact and holds at most factor times the threshold. Keys whose average entry,
+// from their memory, is longer than the value threshold hold a value that keeps them converted
+// however far the entries threshold is raised, so they are left out. A single long value among
+// short ones can't be told apart this way.
+func (t EncodingThresholds) NearThreshold(keyType, encoding string, elements, memory, factor int64) bool {
+	name, ok := t.Threshold(keyType, elements)
+	if !ok || encoding == "" || IsCompactEncoding(encoding) {
+		return false
+	}
+	threshold := t[name]
+	if elements <= threshold.MaxEntries || elements > threshold.MaxEntries*factor {
+		return false
+	}
+	if threshold.MaxValue > 0 {
+		perEntry := memory/elements - entryOverhead[keyType]
+		return perEntry <= threshold.MaxValue*entryStrings[keyType]
+	}
+	return true
+}
+
+// NearThresholdStats aggregates the sampled keys of one type sitting just above its threshold.
+type NearThresholdStats struct {
+	Keys        int64
+	Memory      int64
+	Elements    int64
+	MaxElements int64
+}
+
+// CompactFootprint tracks memory per element of compact-encoded keys by type, used to
+// estimate what near-threshold keys would take if they were compact.
+type CompactFootprint struct {
+	Memory   map[string]int64
+	Elements map[string]int64
+}
+
+func NewCompactFootprint() *CompactFootprint {
+	return &CompactFootprint{
+		Memory:   make(map[string]int64),
+		Elements: make(map[string]int64),
+	}
+}
+
+func (f *CompactFootprint) Add(keyType, encoding string, memory, elements int64) {
+	if !IsCompactEncoding(encoding) || elements == 0 {
+		return
+	}
+	f.Memory[keyType] += memory
+	f.Elements[keyType] += elements
+}
+
+func (f *CompactFootprint) BytesPerElement(keyType string) (float64, bool) {
+	if f == nil || f.Elements[keyType] == 0 {
+		return 0, false
+	}
+	return float64(f.Memory[keyType]) / float64(f.Elements[keyType]), true
+}
+
+// EncodingAdvice suggests raising a compact encoding threshold for a namespace.
+type EncodingAdvice struct {
+	KeyType   string
+	Config    string
+	Current   int64
+	Suggested int64
+	EstKeys   int64
+	EstSaving int64
+}
+
+type EncodingAdviceList []EncodingAdvice
+
+func (l EncodingAdviceList) TotalSaving() int64 {
+	var total int64
+	for _, a := range l {
+		total += a.EstSaving
+	}
+	return total
+}
+
+func (l EncodingAdviceList) TotalKeys() int64 {
+	var total int64
+	for _, a := range l {
+		total += a.EstKeys
+	}
+	return total
+}
+
+// encodingAdvice turns the near-threshold keys of a snapshot into advice, scaling sample counts by
+// scale to estimate the whole namespace.
+func (r *NamespaceSnapshot) encodingAdvice(s *State, scale float64) EncodingAdviceList {
+	var advice EncodingAdviceList
+	for name, near := range r.NearThreshold {
+		threshold, ok := s.EncodingThresholds[name]
+		if !ok || near.Keys == 0 {
+			continue
+		}
+
+		keyType := name
+		if name == IntsetThreshold {
+			keyType = "set"
+		}
+
+		var saving int64
+		if bpe, ok := s.CompactFootprint.BytesPerElement(keyType); ok {
+			saving = max(near.Memory-int64(bpe*float64(near.Elements)), 0)
+		}
+
+		advice = append(advice, EncodingAdvice{
+			KeyType:   keyType,
+			Config:    threshold.Config,
+			Current:   threshold.MaxEntries,
+			Suggested: near.MaxElements,
+			EstKeys:   int64(float64(near.Keys) * scale),
+			EstSaving: int64(float64(saving) * scale),
+		})
+	}
+	sort.Slice(advice, func(i, j int) bool {
+		return advice[i].EstSaving > advice[j].EstSaving
+	})
+	return advice
+}
@@ -0,0 +1,66 @@
+package models
+
+// DurationBuckets are the log-scaled upper bounds, in seconds, used for idle time and TTL histograms.
+var DurationBuckets = []int64{60, 600, 3600, 6 * 3600, 86400, 7 * 86400, 30 * 86400}
+
+// Histogram counts samples, and the memory they hold, into buckets bounded above (inclusive)
+// by Bounds. The last bucket is unbounded, so there is one more bucket than bounds.
+type Histogram struct {
+	Bounds []int64
+	Counts []int64
+	Memory []int64
+}
+
+func NewHistogram(bounds []int64) *Histogram {
+	return &Histogram{
+		Bounds: bounds,
+		Counts: make([]int64, len(bounds)+1),
+		Memory: make([]int64, len(bounds)+1),
+	}
+}
+
+func (h *Histogram) Add(value, memory int64) {
+	i := h.Bucket(value)
+	h.Counts[i]++
+	h.Memory[i] += memory
+}
+
+func (h *Histogram) Bucket(value int64) int {
+	for i, bound := range h.Bounds {
+		if value <= bound {
+			return i
+		}
+	}
+	return len(h.Bounds)
+}
+
+func (h *Histogram) Total() int64 {
+	var total int64
+	for _, c := range h.Counts {
+		total += c
+	}
+	return total
+}
+
+// Scaled returns a copy with counts and memory multiplied by scale, used to extrapolate
+// a sample to the whole namespace.
+func (h *Histogram) Scaled(scale float64) *Histogram {
+	scaled := NewHistogram(h.Bounds)
+	for i := range h.Counts {
+		scaled.Counts[i] = int64(float64(h.Counts[i]) * scale)
+		scaled.Memory[i] = int64(float64(h.Memory[i]) * scale)
+	}
+	return scaled
+}
+
+// MemoryUpTo sums the memory of the buckets whose upper bound is at most bound.
+func (h *Histogram) MemoryUpTo(bound int64) int64 {
+	var total int64
+	for i, b := range h.Bounds {
+		if b > bound {
+			break
+		}
+		total += h.Memory[i]
+	}
+	return total
+}
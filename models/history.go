@@ -0,0 +1,128 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// HistoryRun is what a run keeps in the history database: INFO highlights, the top level
+// namespace metrics, the big keys and the anomalies found against the baselines.
+type HistoryRun struct {
+	Time       time.Time
+	Source     string
+	UsedMemory int64
+	Keys       int64
+	OpsPerSec  int64
+	Namespaces []HistoryNamespace
+	BigKeys    BigKeyList
+	Anomalies  AnomalyList
+}
+
+type HistoryNamespace struct {
+	Namespace string
+	DB        int
+	EstKeys   int64
+	EstMemory int64
+	Ops       float64
+}
+
+// NewHistoryRun captures the top level namespace metrics of a database, with its big keys and
+// the server's INFO highlights, as a run of its source.
+func NewHistoryRun(s *State, db int, metrics NamespaceMetricList, source string, at time.Time) HistoryRun {
+	run := HistoryRun{
+		Time:       at,
+		Source:     source,
+		UsedMemory: s.RedisInfo.Memory.UsedMemory,
+		Keys:       s.RedisInfo.DBKeyspace(db).Keys,
+		OpsPerSec:  s.RedisInfo.Stats.OpsPerSec,
+	}
+	for _, k := range s.BigKeys {
+		if k.DB == db {
+			run.BigKeys = append(run.BigKeys, k)
+		}
+	}
+	for _, m := range metrics {
+		run.Namespaces = append(run.Namespaces, HistoryNamespace{
+			Namespace: m.Namespace,
+			DB:        m.DB,
+			EstKeys:   m.EstKeys,
+			EstMemory: m.EstMemory,
+			Ops:       m.Ops[TotalOp],
+		})
+	}
+	return run
+}
+
+// NamespaceTrend returns a namespace's metrics in each run it was seen in, runs oldest first.
+func NamespaceTrend(runs []HistoryRun, namespace string) []MetricSample {
+	var trend []MetricSample
+	for _, run := range runs {
+		for _, ns := range run.Namespaces {
+			if ns.Namespace != namespace {
+				continue
+			}
+			trend = append(trend, MetricSample{
+				Time:      run.Time,
+				EstKeys:   ns.EstKeys,
+				EstMemory: ns.EstMemory,
+				Ops:       ns.Ops,
+			})
+		}
+	}
+	return trend
+}
+
+// NamespaceGrowth compares a namespace between the first and last run it was seen in.
+type NamespaceGrowth struct {
+	Namespace  string
+	From, To   time.Time
+	FromKeys   int64
+	ToKeys     int64
+	FromMemory int64
+	ToMemory   int64
+}
+
+func (g NamespaceGrowth) MemoryGrowth() int64 {
+	return g.ToMemory - g.FromMemory
+}
+
+type NamespaceGrowthList []NamespaceGrowth
+
+// TopGrowers returns the n namespaces whose estimated memory grew the most over the runs, runs
+// oldest first. Namespaces seen in a single run have nothing to compare and are left out.
+func TopGrowers(runs []HistoryRun, n int) NamespaceGrowthList {
+	index := make(map[string]int)
+	var growth NamespaceGrowthList
+	for _, run := range runs {
+		for _, ns := range run.Namespaces {
+			i, ok := index[ns.Namespace]
+			if !ok {
+				index[ns.Namespace] = len(growth)
+				growth = append(growth, NamespaceGrowth{
+					Namespace:  ns.Namespace,
+					From:       run.Time,
+					FromKeys:   ns.EstKeys,
+					FromMemory: ns.EstMemory,
+				})
+				continue
+			}
+			growth[i].To = run.Time
+			growth[i].ToKeys = ns.EstKeys
+			growth[i].ToMemory = ns.EstMemory
+		}
+	}
+
+	compared := growth[:0]
+	for _, g := range growth {
+		if !g.To.IsZero() {
+			compared = append(compared, g)
+		}
+	}
+	sort.Slice(compared, func(i, j int) bool {
+		return compared[i].MemoryGrowth() > compared[j].MemoryGrowth()
+	})
+	if len(compared) > n {
+		compared = compared[:n]
+	}
+	return compared
+}
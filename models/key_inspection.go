@@ -0,0 +1,96 @@
+package models
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyInspection is what the inspector shows of a key: its metadata and one page of its value.
+type KeyInspection struct {
+	Name     string
+	DB       int
+	Type     string
+	Encoding string
+	// Negative when the key has no expiry
+	TTL      time.Duration
+	Memory   int64
+	Elements int64
+
+	// Preview lines of the current page, and where the next page starts, empty on the last page
+	Page      []string
+	PageStart string
+	NextPage  string
+}
+
+// NextStreamID returns the smallest stream ID after id, to page through XRANGE inclusively. The
+// exclusive ( range needs Redis 6.2, streams exist since 5.0.
+func NextStreamID(id string) (string, error) {
+	msStr, seqStr, ok := strings.Cut(id, "-")
+	if !ok {
+		return "", fmt.Errorf("invalid stream ID %q", id)
+	}
+	ms, err := strconv.ParseUint(msStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid stream ID %q: %w", id, err)
+	}
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid stream ID %q: %w", id, err)
+	}
+	if seq == math.MaxUint64 {
+		return strconv.FormatUint(ms+1, 10) + "-0", nil
+	}
+	return msStr + "-" + strconv.FormatUint(seq+1, 10), nil
+}
+
+// IsBinary reports whether a value would garble the terminal as text.
+func IsBinary(value string) bool {
+	if !utf8.ValidString(value) {
+		return true
+	}
+	for _, r := range value {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatValue renders a string value for the preview: pretty-printed when it is JSON, a hex
+// dump when it is binary, as is otherwise.
+func FormatValue(value string) []string {
+	if IsBinary(value) {
+		return strings.Split(strings.TrimRight(hex.Dump([]byte(value)), "\n"), "\n")
+	}
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var out bytes.Buffer
+		if json.Indent(&out, []byte(trimmed), "", "  ") == nil {
+			return strings.Split(out.String(), "\n")
+		}
+	}
+	return strings.Split(value, "\n")
+}
+
+// FormatElement renders a collection element on a single line, binary ones in hex.
+func FormatElement(value string) string {
+	if IsBinary(value) {
+		return "0x" + hex.EncodeToString([]byte(value))
+	}
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var out bytes.Buffer
+		if json.Compact(&out, []byte(trimmed)) == nil {
+			return out.String()
+		}
+	}
+	return strings.ReplaceAll(value, "\n", `\n`)
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// LatencySample is a spike of a latency monitor event, from LATENCY HISTORY.
+type LatencySample struct {
+	Time    time.Time
+	Latency time.Duration
+}
+
+// LatencyReport is what the latency monitor reported at the last fetch.
+type LatencyReport struct {
+	// Zero before the first fetch
+	At time.Time
+	// latency-monitor-threshold, 0 while the monitor is disabled and -1 when it can't be read,
+	// and whether RedScout set it
+	Threshold    time.Duration
+	ThresholdSet bool
+	// Events by largest max latency first, with their spikes oldest first
+	Events  []LatencyEvent
+	History map[string][]LatencySample
+	// LATENCY DOCTOR output, empty until asked for
+	Doctor string
+	Err    error
+}
+
+// ParseLatencyHistory reads a LATENCY HISTORY reply, a list of [unix time, latency ms] entries.
+// Malformed entries are skipped.
+func ParseLatencyHistory(reply []interface{}) []LatencySample {
+	var samples []LatencySample
+	for _, entry := range reply {
+		fields, ok := entry.([]interface{})
+		if !ok || len(fields) < 2 {
+			continue
+		}
+		at, ok1 := fields[0].(int64)
+		latency, ok2 := fields[1].(int64)
+		if !ok1 || !ok2 {
+			continue
+		}
+		samples = append(samples, LatencySample{
+			Time:    time.Unix(at, 0),
+			Latency: time.Duration(latency) * time.Millisecond,
+		})
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Time.Before(samples[j].Time) })
+	return samples
+}
+
+// LatencyEventKind groups latency monitor events by what stalled the server: command, fork,
+// expire, eviction, aof or other.
+func LatencyEventKind(event string) string {
+	switch {
+	case event == "command" || event == "fast-command":
+		return "command"
+	case event == "fork":
+		return "fork"
+	case strings.HasPrefix(event, "expire"):
+		return "expire"
+	case strings.HasPrefix(event, "eviction"):
+		return "eviction"
+	case strings.HasPrefix(event, "aof"):
+		return "aof"
+	default:
+		return "other"
+	}
+}
+
+// SortLatencyEvents orders events by largest max latency first.
+func SortLatencyEvents(events []LatencyEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Max != events[j].Max {
+			return events[i].Max > events[j].Max
+		}
+		return events[i].Name < events[j].Name
+	})
+}
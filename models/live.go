@@ -0,0 +1,102 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// LiveWindows are the rolling windows live ops rates are shown over.
+var LiveWindows = []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute}
+
+// liveSeconds is the length of the ring buffers, enough for the longest window.
+const liveSeconds = 300
+
+// LiveCounters keeps per-second op counts per namespace in ring buffers, for rates over the
+// last few minutes of continuous monitoring. Seconds in which ops weren't being collected, the
+// off part of the duty cycle, are left out of the rates. Safe for concurrent use.
+type LiveCounters struct {
+	mu     sync.Mutex
+	head   int64 // Unix second of the newest slot
+	active [liveSeconds]bool
+	counts map[string]*[liveSeconds]int64
+}
+
+func NewLiveCounters() *LiveCounters {
+	return &LiveCounters{counts: make(map[string]*[liveSeconds]int64)}
+}
+
+// advance moves the head to now, clearing the slots of the seconds in between.
+func (c *LiveCounters) advance(now int64) {
+	if now <= c.head {
+		return
+	}
+	for sec := max(c.head+1, now-liveSeconds+1); sec <= now; sec++ {
+		i := sec % liveSeconds
+		c.active[i] = false
+		for _, ring := range c.counts {
+			ring[i] = 0
+		}
+	}
+	c.head = now
+}
+
+// Add counts an op against a namespace at the given time.
+func (c *LiveCounters) Add(namespace string, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sec := at.Unix()
+	c.advance(sec)
+	if sec <= c.head-liveSeconds {
+		return
+	}
+	ring, ok := c.counts[namespace]
+	if !ok {
+		ring = &[liveSeconds]int64{}
+		c.counts[namespace] = ring
+	}
+	ring[sec%liveSeconds]++
+}
+
+// MarkActive records that ops were being collected during the second holding at.
+func (c *LiveCounters) MarkActive(at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sec := at.Unix()
+	c.advance(sec)
+	c.active[sec%liveSeconds] = true
+}
+
+// Reset drops all counts, as namespaces change when drilling down or up.
+func (c *LiveCounters) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = make(map[string]*[liveSeconds]int64)
+}
+
+// Rate returns the ops per second of a namespace over the window ending before the current,
+// still partial, second.
+func (c *LiveCounters) Rate(namespace string, window time.Duration, now time.Time) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.advance(now.Unix())
+	ring := c.counts[namespace]
+
+	var ops, seconds int64
+	for sec := now.Unix() - int64(window.Seconds()); sec < now.Unix(); sec++ {
+		i := sec % liveSeconds
+		if !c.active[i] {
+			continue
+		}
+		seconds++
+		if ring != nil {
+			ops += ring[i]
+		}
+	}
+	if seconds == 0 {
+		return 0
+	}
+	return float64(ops) / float64(seconds)
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoadThresholds are the server load above which long operations back off. A zero threshold
+// is not checked.
+type LoadThresholds struct {
+	Enabled bool
+	// Round trip of a PING
+	MaxRTT time.Duration
+	// Latency of events the latency monitor logged since the last sample
+	MaxEventLatency time.Duration
+	MaxOpsPerSec    int64
+	MaxBlocked      int
+	// Share of one core used by the server, 1 is a whole core
+	MaxCPU float64
+}
+
+// LoadSample is the server load seen by the load guard at a point in time.
+type LoadSample struct {
+	At             time.Time
+	RTT            time.Duration
+	EventLatency   time.Duration
+	OpsPerSec      int64
+	BlockedClients int
+	CPU            float64
+}
+
+// Exceeded returns why the sample is over the thresholds, nothing when it isn't.
+func (t LoadThresholds) Exceeded(s LoadSample) []string {
+	var reasons []string
+	if t.MaxRTT > 0 && s.RTT > t.MaxRTT {
+		reasons = append(reasons, fmt.Sprintf("PING %v > %v", s.RTT.Round(time.Millisecond), t.MaxRTT))
+	}
+	if t.MaxEventLatency > 0 && s.EventLatency > t.MaxEventLatency {
+		reasons = append(reasons, fmt.Sprintf("latency event %v > %v", s.EventLatency, t.MaxEventLatency))
+	}
+	if t.MaxOpsPerSec > 0 && s.OpsPerSec > t.MaxOpsPerSec {
+		reasons = append(reasons, fmt.Sprintf("%d ops/s > %d", s.OpsPerSec, t.MaxOpsPerSec))
+	}
+	if t.MaxBlocked > 0 && s.BlockedClients > t.MaxBlocked {
+		reasons = append(reasons, fmt.Sprintf("%d blocked clients > %d", s.BlockedClients, t.MaxBlocked))
+	}
+	if t.MaxCPU > 0 && s.CPU > t.MaxCPU {
+		reasons = append(reasons, fmt.Sprintf("CPU %.0f%% > %.0f%%", s.CPU*100, t.MaxCPU*100))
+	}
+	return reasons
+}
+
+// LoadGuardState is whether the load guard holds back the running scan or monitor, and why.
+type LoadGuardState struct {
+	Enabled bool
+	Paused  bool
+	Reason  string
+	Since   time.Time
+	Sample  LoadSample
+	// Pauses so far and their total length, not counting a running one
+	Pauses      int
+	PausedTotal time.Duration
+}
+
+// LatencyEvent is an event of the latency monitor, from LATENCY LATEST or LATENCY HISTORY.
+type LatencyEvent struct {
+	Name    string
+	Time    time.Time
+	Latency time.Duration
+	Max     time.Duration
+}
+
+// ParseLatencyLatest reads a LATENCY LATEST reply, a list of [event, unix time, latest ms,
+// max ms] entries. Malformed entries are skipped.
+func ParseLatencyLatest(reply []interface{}) []LatencyEvent {
+	var events []LatencyEvent
+	for _, entry := range reply {
+		fields, ok := entry.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+		name, ok1 := fields[0].(string)
+		at, ok2 := fields[1].(int64)
+		latest, ok3 := fields[2].(int64)
+		most, ok4 := fields[3].(int64)
+		if !ok1 || !ok2 || !ok3 || !ok4 {
+			continue
+		}
+		events = append(events, LatencyEvent{
+			Name:    name,
+			Time:    time.Unix(at, 0),
+			Latency: time.Duration(latest) * time.Millisecond,
+			Max:     time.Duration(most) * time.Millisecond,
+		})
+	}
+	return events
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// MetricHistoryLimit is the number of samples kept per namespace, the oldest are dropped first.
+const MetricHistoryLimit = 360
+
+// MetricSample is a namespace's estimated size and ops at a point in time.
+type MetricSample struct {
+	Time      time.Time
+	EstKeys   int64
+	EstMemory int64
+	Ops       float64
+}
+
+// MetricHistory is an in-process time series of namespace metrics, keyed by the full namespace
+// path so that namespaces at different drill-down levels don't mix. Safe for concurrent use.
+type MetricHistory struct {
+	mu     sync.Mutex
+	series map[string][]MetricSample
+}
+
+func NewMetricHistory() *MetricHistory {
+	return &MetricHistory{series: make(map[string][]MetricSample)}
+}
+
+// NamespacePath is the full path of a namespace below the given prefix, e.g. user:session.
+func NamespacePath(prefix Key, namespace string) string {
+	return append(append(Key{}, prefix...), namespace).String()
+}
+
+// Record appends a sample for every namespace. A sample equal to the previous one is skipped,
+// so recomputing stats without new data, e.g. when drilling down and back, adds nothing.
+func (h *MetricHistory) Record(prefix Key, metrics NamespaceMetricList, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, m := range metrics {
+		path := NamespacePath(prefix, m.Namespace)
+		sample := MetricSample{
+			Time:      at,
+			EstKeys:   m.EstKeys,
+			EstMemory: m.EstMemory,
+			Ops:       m.Ops[TotalOp],
+		}
+
+		series := h.series[path]
+		if n := len(series); n > 0 {
+			last := series[n-1]
+			if last.EstKeys == sample.EstKeys && last.EstMemory == sample.EstMemory && last.Ops == sample.Ops {
+				continue
+			}
+		}
+		if len(series) >= MetricHistoryLimit {
+			series = series[1:]
+		}
+		h.series[path] = append(series, sample)
+	}
+}
+
+// Series returns a copy of the samples of a namespace, oldest first.
+func (h *MetricHistory) Series(prefix Key, namespace string) []MetricSample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]MetricSample(nil), h.series[NamespacePath(prefix, namespace)]...)
+}
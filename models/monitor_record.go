@@ -0,0 +1,166 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MonitorRecord is a single line of the monitor log written by MonitorOps.
+type MonitorRecord struct {
+	Key     string
+	Command string
+	DB      int
+	// Unix milliseconds when the command ran, zero when unknown
+	Time int64
+}
+
+const monitorRecordFields = 3
+
+// Keys are escaped in the line-based log, as they may hold line breaks
+var (
+	recordKeyEscaper   = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+	recordKeyUnescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r")
+)
+
+func (r MonitorRecord) String() string {
+	return fmt.Sprintf("%s %s %d %d", recordKeyEscaper.Replace(r.Key), r.Command, r.DB, r.Time)
+}
+
+func ParseMonitorRecord(line string) (MonitorRecord, error) {
+	key, fields, err := splitRecord(line, monitorRecordFields)
+	if err != nil {
+		return MonitorRecord{}, err
+	}
+
+	db, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return MonitorRecord{}, fmt.Errorf("invalid db %q: %w", fields[1], err)
+	}
+	ts, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return MonitorRecord{}, fmt.Errorf("invalid time %q: %w", fields[2], err)
+	}
+
+	return MonitorRecord{Key: recordKeyUnescaper.Replace(key), Command: fields[0], DB: db, Time: ts}, nil
+}
+
+// MonitorEvent is a parsed line of raw MONITOR output, e.g.
+// 1339518083.107412 [0 127.0.0.1:60866] "get" "user:1"
+type MonitorEvent struct {
+	Time time.Time
+	DB   int
+	// Address of the client that sent the command, "lua" for commands run by scripts
+	Addr string
+	Args []string
+}
+
+func ParseMonitorLine(line string) (MonitorEvent, error) {
+	line = strings.TrimSpace(line)
+
+	tsEnd := strings.IndexByte(line, ' ')
+	if tsEnd < 0 {
+		return MonitorEvent{}, fmt.Errorf("missing timestamp")
+	}
+	ts, err := strconv.ParseFloat(line[:tsEnd], 64)
+	if err != nil {
+		return MonitorEvent{}, fmt.Errorf("invalid timestamp %q: %w", line[:tsEnd], err)
+	}
+
+	rest := strings.TrimSpace(line[tsEnd:])
+	if !strings.HasPrefix(rest, "[") {
+		return MonitorEvent{}, fmt.Errorf("missing client info")
+	}
+	clientEnd := strings.IndexByte(rest, ']')
+	if clientEnd < 0 {
+		return MonitorEvent{}, fmt.Errorf("unterminated client info")
+	}
+	client := strings.Fields(rest[1:clientEnd])
+	if len(client) == 0 {
+		return MonitorEvent{}, fmt.Errorf("missing db in client info")
+	}
+	db, err := strconv.Atoi(client[0])
+	if err != nil {
+		return MonitorEvent{}, fmt.Errorf("invalid db %q: %w", client[0], err)
+	}
+
+	args := parseQuotedArgs(rest[clientEnd+1:])
+	if len(args) == 0 {
+		return MonitorEvent{}, fmt.Errorf("missing command")
+	}
+
+	var addr string
+	if len(client) > 1 {
+		addr = client[1]
+	}
+
+	sec := int64(ts)
+	nsec := int64((ts - float64(sec)) * 1e9)
+	return MonitorEvent{
+		Time: time.Unix(sec, nsec),
+		DB:   db,
+		Addr: addr,
+		Args: args,
+	}, nil
+}
+
+// parseQuotedArgs extracts the double-quoted, backslash-escaped arguments that
+// MONITOR prints after the client info.
+func parseQuotedArgs(s string) []string {
+	var (
+		args    []string
+		current strings.Builder
+		inQuote bool
+	)
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !inQuote {
+			if c == '"' {
+				inQuote = true
+				current.Reset()
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			args = append(args, current.String())
+			inQuote = false
+		case '\\':
+			if i+1 >= len(s) {
+				current.WriteByte(c)
+				continue
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				current.WriteByte('\n')
+			case 'r':
+				current.WriteByte('\r')
+			case 't':
+				current.WriteByte('\t')
+			case 'a':
+				current.WriteByte('\a')
+			case 'b':
+				current.WriteByte('\b')
+			case 'x':
+				if i+2 < len(s) {
+					if v, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+						current.WriteByte(byte(v))
+						i += 2
+						continue
+					}
+				}
+				current.WriteByte('x')
+			default:
+				current.WriteByte(s[i])
+			}
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	return args
+}
@@ -1,48 +1,181 @@
 package models
 
 import (
+	"slices"
 	"sort"
+	"time"
 )
 
 type NamespaceSnapshot struct {
 	Namespace    string
+	DB           int
 	Keys         int64
 	KeysWithTTL  int64
 	TotalMemory  int64
 	TotalTTL     int64
+	TotalElems   int64
+	MaxElems     int64
 	OpsFrequency map[string]int64
 	Types        []string
+
+	// Sampled key counts by OBJECT ENCODING, and keys just above a compact encoding threshold by threshold name
+	Encodings     map[string]int64
+	NearThreshold map[string]*NearThresholdStats
+
+	// Access history from OBJECT IDLETIME or OBJECT FREQ, depending on the eviction policy, and
+	// the memory of the keys it was sampled for
+	IdleHistogram *Histogram
+	FreqSamples   int64
+	TotalFreq     int64
+	AccessSampled int64
+	AccessMemory  int64
+	ColdKeys      int64
+	ColdMemory    int64
+
+	// Remaining TTL of keys with an expiry
+	TTLHistogram *Histogram
+
+	// Ops per State.OpsTimeline bucket, nil without timestamped ops
+	OpsTimeline []int64
+
+	// Sampled memory by namespace one level further down
+	ChildMemory map[string]int64
+}
+
+func NewNamespaceSnapshot(namespace string, db int) *NamespaceSnapshot {
+	return &NamespaceSnapshot{
+		Namespace:    namespace,
+		DB:           db,
+		OpsFrequency: make(map[string]int64),
+		Types:        make([]string, 0),
+		ChildMemory:  make(map[string]int64),
+
+		Encodings:     make(map[string]int64),
+		NearThreshold: make(map[string]*NearThresholdStats),
+		IdleHistogram: NewHistogram(DurationBuckets),
+		TTLHistogram:  NewHistogram(DurationBuckets),
+	}
+}
+
+// AddAccessSample records a key's access history. Under LRU policies a key is cold once idle for
+// coldAfter; under LFU policies, which keep no idle time, once its counter has decayed to zero.
+func (r *NamespaceSnapshot) AddAccessSample(record ScanRecord, coldAfter time.Duration) {
+	cold := false
+	switch {
+	case record.Idle >= 0:
+		r.IdleHistogram.Add(record.Idle, record.Memory)
+		cold = record.Idle >= int64(coldAfter.Seconds())
+	case record.Freq >= 0:
+		r.FreqSamples++
+		r.TotalFreq += record.Freq
+		cold = record.Freq == 0
+	default:
+		return
+	}
+
+	r.AccessSampled++
+	r.AccessMemory += record.Memory
+	if cold {
+		r.ColdKeys++
+		r.ColdMemory += record.Memory
+	}
+}
+
+// AddKey records a sampled key's memory, element count, TTL and type. Element counts of every
+// type add up alike, types without a cheap count adding 0.
+func (r *NamespaceSnapshot) AddKey(record ScanRecord) {
+	r.Keys++
+	r.TotalMemory += record.Memory
+	r.TotalElems += record.Elements
+	r.MaxElems = max(r.MaxElems, record.Elements)
+	r.AddTTL(record.TTL, record.Memory)
+	if !slices.Contains(r.Types, record.Type) {
+		r.Types = append(r.Types, record.Type)
+	}
+}
+
+// AddTTL records the remaining TTL in seconds of a key. Keys without one, or already expired as
+// they were sampled, read 0 or less and aren't counted as expiring.
+func (r *NamespaceSnapshot) AddTTL(ttl, memory int64) {
+	if ttl <= 0 {
+		return
+	}
+	r.KeysWithTTL++
+	r.TotalTTL += ttl
+	r.TTLHistogram.Add(ttl, memory)
 }
 
 type NamespaceMetrics struct {
 	Namespace  string
+	DB         int
 	EstKeys    int64
 	EstMemory  int64
 	TTLPercent float64
 	AvgTTL     int64
 	MemPerKey  float64
+	AvgElems   float64
+	MaxElems   int64
 	Ops        map[OpType]float64
 	Types      []string
+
+	// Share of sampled keys per encoding, and advice on raising compact encoding thresholds
+	Encodings      map[string]float64
+	EncodingAdvice EncodingAdviceList
+
+	// Idle time histogram, LFU counter average and memory not accessed recently
+	IdleHistogram *Histogram
+	AvgFreq       float64
+	EstColdMemory int64
+	ColdPercent   float64
+
+	// Remaining TTL histogram of keys with an expiry, extrapolated to the namespace
+	TTLHistogram *Histogram
+
+	// Ops per State.OpsTimeline bucket
+	OpsTimeline []int64
+
+	// Estimated memory by namespace one level further down
+	ChildMemory map[string]int64
+
+	// Set when the namespace has no TTLs and keeps writing while other namespaces are evicted
+	EvictionPressure bool
+
+	// Share of the scanned keys of its database, to rescale estimates as the keyspace changes
+	KeyShare float64
 }
 
-func (r *NamespaceSnapshot) ToMetric(s *State) *NamespaceMetrics {
-	if s.ScannedKeys == 0 || r.Keys == 0 {
-		return &NamespaceMetrics{
-			Namespace: r.Namespace,
-			Types:     r.Types,
-			Ops:       make(map[OpType]float64),
-		}
+// ExpiryForecast estimates the memory freed by keys expiring within the given number of seconds.
+// The horizon should be one of DurationBuckets for an exact figure.
+func (m *NamespaceMetrics) ExpiryForecast(within int64) int64 {
+	if m.TTLHistogram == nil {
+		return 0
 	}
+	return m.TTLHistogram.MemoryUpTo(within)
+}
 
+func (r *NamespaceSnapshot) ToMetric(s *State) *NamespaceMetrics {
 	processed := &NamespaceMetrics{}
+	processed.Namespace = r.Namespace
+	processed.DB = r.DB
+	processed.Types = r.Types
+	processed.Ops = r.opsPerSecond(s)
+	processed.OpsTimeline = r.OpsTimeline
+
+	// Namespaces only seen in monitored or replayed ops have no scanned keys to extrapolate from
+	scannedKeys := s.ScannedKeysByDB[r.DB]
+	if scannedKeys == 0 || r.Keys == 0 {
+		return processed
+	}
 
-	totalKeys := s.RedisInfo.Keyspace["db0"].Keys
+	totalKeys := s.RedisInfo.DBKeyspace(r.DB).Keys
 
-	processed.Namespace = r.Namespace
-	processed.EstKeys = (totalKeys * r.Keys) / s.ScannedKeys
+	processed.KeyShare = float64(r.Keys) / float64(scannedKeys)
+	processed.EstKeys = (totalKeys * r.Keys) / scannedKeys
 	processed.MemPerKey = float64(r.TotalMemory) / float64(r.Keys)
 	processed.EstMemory = int64(float64(processed.EstKeys) * processed.MemPerKey)
 	processed.TTLPercent = float64(r.KeysWithTTL) / float64(r.Keys)
+	processed.AvgElems = float64(r.TotalElems) / float64(r.Keys)
+	processed.MaxElems = r.MaxElems
 
 	if r.KeysWithTTL == 0 {
 		processed.AvgTTL = 0
@@ -50,24 +183,71 @@ func (r *NamespaceSnapshot) ToMetric(s *State) *NamespaceMetrics {
 		processed.AvgTTL = r.TotalTTL / r.KeysWithTTL
 	}
 
-	processed.Ops = make(map[OpType]float64)
+	processed.Encodings = make(map[string]float64)
+	for encoding, count := range r.Encodings {
+		processed.Encodings[encoding] = float64(count) / float64(r.Keys)
+	}
+	scale := float64(processed.EstKeys) / float64(r.Keys)
+	processed.EncodingAdvice = r.encodingAdvice(s, scale)
+
+	if r.IdleHistogram != nil {
+		processed.IdleHistogram = r.IdleHistogram.Scaled(scale)
+	}
+	if r.FreqSamples > 0 {
+		processed.AvgFreq = float64(r.TotalFreq) / float64(r.FreqSamples)
+	}
+	if r.TTLHistogram != nil {
+		processed.TTLHistogram = r.TTLHistogram.Scaled(scale)
+	}
+	processed.EstColdMemory = int64(float64(r.ColdMemory) * scale)
+	processed.ChildMemory = make(map[string]int64, len(r.ChildMemory))
+	for child, memory := range r.ChildMemory {
+		processed.ChildMemory[child] = int64(float64(memory) * scale)
+	}
+	// Keys without access history are neither cold nor warm
+	if r.AccessMemory > 0 {
+		processed.ColdPercent = float64(r.ColdMemory) / float64(r.AccessMemory)
+	}
+
+	return processed
+}
+
+func (r *NamespaceSnapshot) opsPerSecond(s *State) map[OpType]float64 {
+	ops := make(map[OpType]float64)
 	for op, count := range r.OpsFrequency {
-		processed.Ops[GetOpType(op)] += float64(count)
+		ops[GetOpType(op)] += float64(count)
 	}
 
 	if s.TotalMonitorDuration > 0 {
-		for opType, count := range processed.Ops {
-			processed.Ops[opType] = count / s.TotalMonitorDuration.Seconds()
+		for opType, count := range ops {
+			ops[opType] = count / s.TotalMonitorDuration.Seconds()
 		}
 	}
-	processed.Ops[TotalOp] = processed.Ops[GetOp] + processed.Ops[SetOp] + processed.Ops[DelOp] + processed.Ops[EvalOp]
-
-	processed.Types = r.Types
-	return processed
+	ops[TotalOp] = ops[GetOp] + ops[SetOp] + ops[DelOp] + ops[EvalOp]
+	return ops
 }
 
 type NamespaceMetricList []*NamespaceMetrics
 
+// FlagEvictionPressure marks namespaces likely pushing others out of memory while the server
+// evicts: they hold no keys with a TTL, are being written to, and see fewer evictions than the
+// rest of the list combined. Evictions only happen under a maxmemory-policy other than
+// noeviction, so the policy itself isn't checked.
+func (d NamespaceMetricList) FlagEvictionPressure() {
+	var evicted float64
+	for _, m := range d {
+		evicted += m.Ops[EvictedOp]
+	}
+
+	for _, m := range d {
+		others := evicted - m.Ops[EvictedOp]
+		m.EvictionPressure = m.EstKeys > 0 &&
+			m.TTLPercent == 0 &&
+			m.Ops[SetOp] > 0 &&
+			others > m.Ops[EvictedOp]
+	}
+}
+
 func (d NamespaceMetricList) Sort(sortBy string) {
 	sort.Slice(d, func(i, j int) bool {
 		switch sortBy {
@@ -85,8 +265,29 @@ func (d NamespaceMetricList) Sort(sortBy string) {
 			return d[i].Ops[DelOp] > d[j].Ops[DelOp]
 		case "Total Ops":
 			return d[i].Ops[TotalOp] > d[j].Ops[TotalOp]
+		case "Max Elems":
+			return d[i].MaxElems > d[j].MaxElems
+		case "Cold Memory":
+			return d[i].EstColdMemory > d[j].EstColdMemory
+		case "Encoding Saving":
+			return d[i].EncodingAdvice.TotalSaving() > d[j].EncodingAdvice.TotalSaving()
 		default:
 			return d[i].EstMemory > d[j].EstMemory
 		}
 	})
 }
+
+// Rescaled returns copies of the metrics with the key and memory estimates extrapolated to the
+// current keyspace sizes in info, keeping each namespace's share of its database.
+func (l NamespaceMetricList) Rescaled(info *RedisInfo) NamespaceMetricList {
+	rescaled := make(NamespaceMetricList, 0, len(l))
+	for _, m := range l {
+		c := *m
+		if c.KeyShare > 0 {
+			c.EstKeys = int64(c.KeyShare * float64(info.DBKeyspace(c.DB).Keys))
+			c.EstMemory = int64(float64(c.EstKeys) * c.MemPerKey)
+		}
+		rescaled = append(rescaled, &c)
+	}
+	return rescaled
+}
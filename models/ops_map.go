@@ -13,6 +13,10 @@ const (
 	EvalOp    OpType = "Eval"
 	TotalOp   OpType = "Total"
 	UnknownOp OpType = "Unknown"
+
+	// Keys removed by the server rather than a client, from keyspace notifications
+	EvictedOp OpType = "EVICTED"
+	ExpiredOp OpType = "EXPIRED"
 )
 
 // Map of Redis commands to their operation category
@@ -67,6 +71,20 @@ var redisCommandType = map[string]OpType{
 	"LINSERT":      SetOp,
 	"HINCRBY":      SetOp,
 	"HINCRBYFLOAT": SetOp,
+	"HSETNX":       SetOp,
+	"GETSET":       SetOp,
+	"SETRANGE":     SetOp,
+	"INCRBYFLOAT":  SetOp,
+	"DECRBY":       SetOp,
+	"PEXPIREAT":    SetOp,
+	"LPUSHX":       SetOp,
+	"RPUSHX":       SetOp,
+	"SMOVE":        SetOp,
+	"PFADD":        SetOp,
+	"GEOADD":       SetOp,
+	"XADD":         SetOp,
+	"RESTORE":      SetOp,
+	"COPY":         SetOp,
 
 	// DEL-like
 	"DEL":      DelOp,
@@ -80,6 +98,37 @@ var redisCommandType = map[string]OpType{
 	"HDEL":     DelOp,
 	"SREM":     DelOp,
 	"LTRIM":    DelOp,
+	"LREM":     DelOp,
+	"LMOVE":    DelOp,
+	"ZPOPMIN":  DelOp,
+	"ZPOPMAX":  DelOp,
+	"XDEL":     DelOp,
+	"XTRIM":    DelOp,
+	"GETDEL":   DelOp,
+
+	"ZREMRANGEBYRANK":  DelOp,
+	"ZREMRANGEBYSCORE": DelOp,
+	"ZREMRANGEBYLEX":   DelOp,
+
+	// Keyspace notification events not named after a command
+	"ZINCR":       SetOp,
+	"RENAME_TO":   SetOp,
+	"MOVE_TO":     SetOp,
+	"COPY_TO":     SetOp,
+	"XSETID":      SetOp,
+	"SINTERSTORE": SetOp,
+	"SUNIONSTORE": SetOp,
+	"SDIFFSTORE":  SetOp,
+	"ZINTERSTORE": SetOp,
+	"ZUNIONSTORE": SetOp,
+	"ZDIFFSTORE":  SetOp,
+	"RENAME_FROM": DelOp,
+	"MOVE_FROM":   DelOp,
+	"EXPIRED":     ExpiredOp,
+	"EVICTED":     EvictedOp,
+	"ZREMBYSCORE": DelOp,
+	"ZREMBYRANK":  DelOp,
+	"ZREMBYLEX":   DelOp,
 
 	// EVAL-like
 	"EVAL": EvalOp,
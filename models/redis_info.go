@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -43,6 +45,12 @@ type MemoryInfo struct {
 	UsedMemoryPeakPerc float64
 }
 
+// IsLFU reports whether the eviction policy tracks access frequency (OBJECT FREQ) rather than
+// recency (OBJECT IDLETIME).
+func (m MemoryInfo) IsLFU() bool {
+	return strings.Contains(m.MemoryPolicy, "lfu")
+}
+
 type CPUInfo struct {
 	UserTime   float64
 	SystemTime float64
@@ -53,6 +61,8 @@ type StatsInfo struct {
 	OpsPerSec        int64
 	KeyspaceHits     int64
 	KeyspaceMisses   int64
+	ExpiredKeys      int64
+	EvictedKeys      int64
 }
 
 type KeyspaceInfo struct {
@@ -61,9 +71,52 @@ type KeyspaceInfo struct {
 	AvgTTL  int64
 }
 
+// DBKeyspace returns the keyspace info of the given logical database.
+func (r *RedisInfo) DBKeyspace(db int) KeyspaceInfo {
+	return r.Keyspace[DBName(db)]
+}
+
+// KeyspaceTotals sums the keyspace info of the given databases, weighting the average TTL by expiring keys.
+func (r *RedisInfo) KeyspaceTotals(dbs []int) KeyspaceInfo {
+	var total KeyspaceInfo
+	var ttlSum int64
+	for _, db := range dbs {
+		ks := r.DBKeyspace(db)
+		total.Keys += ks.Keys
+		total.Expires += ks.Expires
+		ttlSum += ks.AvgTTL * ks.Expires
+	}
+	if total.Expires > 0 {
+		total.AvgTTL = ttlSum / total.Expires
+	}
+	return total
+}
+
+// NonEmptyDBs returns the logical databases listed in the keyspace section that hold at least one key.
+func (r *RedisInfo) NonEmptyDBs() []int {
+	var dbs []int
+	for name, ks := range r.Keyspace {
+		db, err := strconv.Atoi(strings.TrimPrefix(name, "db"))
+		if err != nil || ks.Keys == 0 {
+			continue
+		}
+		dbs = append(dbs, db)
+	}
+	sort.Ints(dbs)
+	return dbs
+}
+
+func DBName(db int) string {
+	return fmt.Sprintf("db%d", db)
+}
+
 type ComputedStats struct {
 	CPUUsage float64
 	HitRate  float64
+
+	// Server-wide rates since the previous INFO
+	ExpiredPerSec float64
+	EvictedPerSec float64
 }
 
 func ParseInfo(info string) RedisInfo {
@@ -130,6 +183,10 @@ func ParseInfo(info string) RedisInfo {
 			result.Stats.KeyspaceHits, _ = strconv.ParseInt(val, 10, 64)
 		case "keyspace_misses":
 			result.Stats.KeyspaceMisses, _ = strconv.ParseInt(val, 10, 64)
+		case "expired_keys":
+			result.Stats.ExpiredKeys, _ = strconv.ParseInt(val, 10, 64)
+		case "evicted_keys":
+			result.Stats.EvictedKeys, _ = strconv.ParseInt(val, 10, 64)
 
 		// Keyspace
 		default:
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// SampledKey is a key as recorded in the scan log.
+type SampledKey struct {
+	Name     string
+	DB       int
+	Memory   int64
+	TTL      int64
+	Type     string
+	Encoding string
+	Elements int64
+}
+
+type SampledKeyList []SampledKey
+
+func (l SampledKeyList) Sort(sortBy string) {
+	sort.SliceStable(l, func(i, j int) bool {
+		switch sortBy {
+		case "TTL":
+			return l[i].TTL > l[j].TTL
+		case "Elements":
+			return l[i].Elements > l[j].Elements
+		case "Key":
+			return l[i].Name < l[j].Name
+		default:
+			return l[i].Memory > l[j].Memory
+		}
+	})
+}
+
+// Filter returns the keys whose name contains text, or whose type or encoding is text, ignoring
+// case.
+func (l SampledKeyList) Filter(text string) SampledKeyList {
+	if text == "" {
+		return l
+	}
+	text = strings.ToLower(text)
+	var filtered SampledKeyList
+	for _, k := range l {
+		if strings.Contains(strings.ToLower(k.Name), text) || k.Type == text || k.Encoding == text {
+			filtered = append(filtered, k)
+		}
+	}
+	return filtered
+}
+
+// SampledKeyTop keeps the largest sampled keys by memory, up to a limit. A key scanned in
+// several passes is logged each time, so it is kept once, with its latest sample.
+type SampledKeyTop struct {
+	limit int
+	keys  SampledKeyList
+	index map[sampledKeyID]int
+}
+
+type sampledKeyID struct {
+	db   int
+	name string
+}
+
+func NewSampledKeyTop(limit int) *SampledKeyTop {
+	return &SampledKeyTop{limit: limit, index: make(map[sampledKeyID]int)}
+}
+
+// Add records a sample, replacing an earlier one of the same key.
+func (t *SampledKeyTop) Add(k SampledKey) {
+	id := sampledKeyID{k.DB, k.Name}
+	if i, ok := t.index[id]; ok {
+		t.keys[i] = k
+		return
+	}
+	t.index[id] = len(t.keys)
+	t.keys = append(t.keys, k)
+
+	// Trimmed as it goes, a namespace of an RDB file can hold millions of keys
+	if len(t.keys) >= 2*t.limit {
+		t.trim()
+	}
+}
+
+func (t *SampledKeyTop) trim() {
+	t.keys.Sort("Memory")
+	if len(t.keys) > t.limit {
+		t.keys = t.keys[:t.limit]
+	}
+	clear(t.index)
+	for i, k := range t.keys {
+		t.index[sampledKeyID{k.DB, k.Name}] = i
+	}
+}
+
+// List returns the kept keys, largest first.
+func (t *SampledKeyTop) List() SampledKeyList {
+	t.trim()
+	return t.keys
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ScanRecord is a single line of the scan log written by ScanMemory.
+type ScanRecord struct {
+	Key      string
+	Memory   int64
+	TTL      int64
+	Type     string
+	Encoding string
+	Elements int64
+	// Seconds since last access (LRU policies) and LFU counter (LFU policies), -1 when not sampled
+	Idle int64
+	Freq int64
+	DB   int
+	// Unix seconds when the key was sampled
+	Time int64
+}
+
+const scanRecordFields = 9
+
+// String encodes the record as a scan log line. The key goes first so that keys
+// containing spaces can still be recovered by splitting from the right.
+func (r ScanRecord) String() string {
+	return fmt.Sprintf(
+		"%s %d %d %s %s %d %d %d %d %d",
+		r.Key, r.Memory, r.TTL, r.Type, r.Encoding, r.Elements, r.Idle, r.Freq, r.DB, r.Time,
+	)
+}
+
+func ParseScanRecord(line string) (ScanRecord, error) {
+	key, fields, err := splitRecord(line, scanRecordFields)
+	if err != nil {
+		return ScanRecord{}, err
+	}
+
+	memory, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return ScanRecord{}, fmt.Errorf("invalid memory %q: %w", fields[0], err)
+	}
+	ttl, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return ScanRecord{}, fmt.Errorf("invalid ttl %q: %w", fields[1], err)
+	}
+	elements, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return ScanRecord{}, fmt.Errorf("invalid element count %q: %w", fields[4], err)
+	}
+	idle, err := strconv.ParseInt(fields[5], 10, 64)
+	if err != nil {
+		return ScanRecord{}, fmt.Errorf("invalid idle time %q: %w", fields[5], err)
+	}
+	freq, err := strconv.ParseInt(fields[6], 10, 64)
+	if err != nil {
+		return ScanRecord{}, fmt.Errorf("invalid frequency %q: %w", fields[6], err)
+	}
+	db, err := strconv.Atoi(fields[7])
+	if err != nil {
+		return ScanRecord{}, fmt.Errorf("invalid db %q: %w", fields[7], err)
+	}
+	sampledAt, err := strconv.ParseInt(fields[8], 10, 64)
+	if err != nil {
+		return ScanRecord{}, fmt.Errorf("invalid time %q: %w", fields[8], err)
+	}
+
+	return ScanRecord{
+		Key:      key,
+		Memory:   memory,
+		TTL:      ttl,
+		Type:     fields[2],
+		Encoding: fields[3],
+		Elements: elements,
+		Idle:     idle,
+		Freq:     freq,
+		DB:       db,
+		Time:     sampledAt,
+	}, nil
+}
+
+// splitRecord splits a log line into its leading key and the n trailing fields.
+func splitRecord(line string, n int) (string, []string, error) {
+	parts := strings.Split(line, " ")
+	if len(parts) < n+1 {
+		return "", nil, fmt.Errorf("expected at least %d fields, got %d", n+1, len(parts))
+	}
+
+	key := strings.Join(parts[:len(parts)-n], " ")
+	if key == "" {
+		return "", nil, fmt.Errorf("key is empty")
+	}
+	return key, parts[len(parts)-n:], nil
+}
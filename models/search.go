@@ -0,0 +1,56 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// CompileSearch turns a table search into a case-insensitive regexp, matching it as a plain
+// substring when it isn't a valid regexp.
+func CompileSearch(text string) *regexp.Regexp {
+	if re, err := regexp.Compile("(?i)" + text); err == nil {
+		return re
+	}
+	return regexp.MustCompile("(?i)" + regexp.QuoteMeta(text))
+}
+
+func (d NamespaceMetricList) Search(re *regexp.Regexp) NamespaceMetricList {
+	matches := NamespaceMetricList{}
+	for _, m := range d {
+		if re.MatchString(m.Namespace) {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// Search matches slow log entries by their command line, namespace or client.
+func (d SlowLogList) Search(re *regexp.Regexp) SlowLogList {
+	matches := SlowLogList{}
+	for _, entry := range d {
+		if re.MatchString(strings.Join(entry.Args, " ")) || re.MatchString(entry.Namespace) || re.MatchString(entry.Client()) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+func (b BigKeyList) Search(re *regexp.Regexp) BigKeyList {
+	matches := BigKeyList{}
+	for _, k := range b {
+		if re.MatchString(k.Key.String()) {
+			matches = append(matches, k)
+		}
+	}
+	return matches
+}
+
+func (h HotKeyList) Search(re *regexp.Regexp) HotKeyList {
+	matches := HotKeyList{}
+	for _, k := range h {
+		if re.MatchString(k.Key.String()) {
+			matches = append(matches, k)
+		}
+	}
+	return matches
+}
@@ -1,11 +1,95 @@
 package models
 
 import (
-	"github.com/redis/go-redis/v9"
+	"math"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
-type SlowLogList []redis.SlowLog
+// SlowLogEntry is a slow log entry with the namespace of the key it names, empty for commands
+// without a key.
+type SlowLogEntry struct {
+	redis.SlowLog
+	Namespace string
+}
+
+// Client names the client that sent the command, by name when it set one.
+func (e SlowLogEntry) Client() string {
+	if e.ClientName != "" {
+		return e.ClientName
+	}
+	return e.ClientAddr
+}
+
+type SlowLogList []SlowLogEntry
+
+// NewSlowLogList attributes slow log entries to the namespace of their first key.
+func NewSlowLogList(logs []redis.SlowLog, kp *KeyParser) SlowLogList {
+	list := make(SlowLogList, len(logs))
+	for i, log := range logs {
+		list[i] = SlowLogEntry{SlowLog: log}
+		if key, ok := SlowLogKey(log.Args); ok {
+			list[i].Namespace = KeyNamespace(kp.NewKey(key, true))
+		}
+	}
+	return list
+}
+
+// Commands without a key, or whose first argument isn't one
+var keylessCommands = map[string]bool{
+	"keys": true, "scan": true, "info": true, "config": true, "flushall": true, "flushdb": true,
+	"dbsize": true, "randomkey": true, "select": true, "ping": true, "auth": true, "hello": true,
+	"client": true, "cluster": true, "command": true, "slowlog": true, "latency": true,
+	"debug": true, "save": true, "bgsave": true, "bgrewriteaof": true,
+	"script": true, "function": true, "publish": true, "subscribe": true, "psubscribe": true,
+	"multi": true, "exec": true, "discard": true, "watch": true, "unwatch": true, "monitor": true,
+	"acl": true, "module": true, "swapdb": true, "wait": true, "time": true, "lastsave": true,
+}
+
+// SlowLogKey returns the first key a logged command names.
+func SlowLogKey(args []string) (string, bool) {
+	if len(args) < 2 {
+		return "", false
+	}
+	cmd := strings.ToLower(args[0])
+	switch {
+	case cmd == "eval" || cmd == "evalsha" || cmd == "eval_ro" || cmd == "evalsha_ro" || cmd == "fcall" || cmd == "fcall_ro":
+		// Keys follow the script and their count
+		if len(args) < 4 {
+			return "", false
+		}
+		if n, err := strconv.Atoi(args[2]); err != nil || n < 1 {
+			return "", false
+		}
+		return args[3], true
+	case cmd == "object" || (cmd == "memory" && strings.EqualFold(args[1], "usage")):
+		// OBJECT ENCODING key, MEMORY USAGE key
+		if len(args) < 3 {
+			return "", false
+		}
+		return args[2], true
+	case cmd == "memory" || keylessCommands[cmd]:
+		return "", false
+	}
+	return args[1], true
+}
+
+// NoNamespace groups the keys without a delimiter, which sit at the root of the hierarchy.
+const NoNamespace = "(no namespace)"
+
+// KeyNamespace is the namespace a key belongs to, all its parts but the last, or NoNamespace
+// when it has a single part, so that such keys group together rather than each on its own.
+func KeyNamespace(k Key) string {
+	if len(k) <= 1 {
+		return NoNamespace
+	}
+	return k[:len(k)-1].String()
+}
 
 func (d SlowLogList) Sort(key string) {
 	sort.Slice(d, func(i, j int) bool {
@@ -23,3 +107,193 @@ func (d SlowLogList) Sort(key string) {
 		}
 	})
 }
+
+// SlowLogGroup aggregates the slow log entries of a command on a namespace.
+type SlowLogGroup struct {
+	Command   string
+	Namespace string
+	Count     int
+	Total     time.Duration
+	P95       time.Duration
+	Max       time.Duration
+	// Clients that sent the commands, most frequent first
+	Clients []string
+}
+
+type SlowLogGroupList []*SlowLogGroup
+
+// GroupSlowLog aggregates slow log entries by command and namespace, largest total duration
+// first.
+func GroupSlowLog(entries SlowLogList) SlowLogGroupList {
+	type group struct {
+		*SlowLogGroup
+		durations []time.Duration
+		clients   map[string]int
+	}
+	groups := make(map[[2]string]*group)
+	var order [][2]string
+	for _, e := range entries {
+		if len(e.Args) == 0 {
+			continue
+		}
+		id := [2]string{strings.ToUpper(e.Args[0]), e.Namespace}
+		g, ok := groups[id]
+		if !ok {
+			g = &group{SlowLogGroup: &SlowLogGroup{Command: id[0], Namespace: id[1]}, clients: make(map[string]int)}
+			groups[id] = g
+			order = append(order, id)
+		}
+		g.Count++
+		g.Total += e.Duration
+		g.Max = max(g.Max, e.Duration)
+		g.durations = append(g.durations, e.Duration)
+		if client := e.Client(); client != "" {
+			g.clients[client]++
+		}
+	}
+
+	list := make(SlowLogGroupList, 0, len(groups))
+	for _, id := range order {
+		g := groups[id]
+		g.P95 = percentile(g.durations, 0.95)
+		for client := range g.clients {
+			g.Clients = append(g.Clients, client)
+		}
+		sort.Slice(g.Clients, func(i, j int) bool {
+			ci, cj := g.clients[g.Clients[i]], g.clients[g.Clients[j]]
+			if ci != cj {
+				return ci > cj
+			}
+			return g.Clients[i] < g.Clients[j]
+		})
+		list = append(list, g.SlowLogGroup)
+	}
+	list.Sort("Total")
+	return list
+}
+
+// percentile returns the nearest-rank percentile of durations, which it sorts.
+func percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	rank := int(math.Ceil(p*float64(len(durations)))) - 1
+	return durations[max(rank, 0)]
+}
+
+func (l SlowLogGroupList) Sort(key string) {
+	sort.SliceStable(l, func(i, j int) bool {
+		switch key {
+		case "Count":
+			return l[i].Count > l[j].Count
+		case "P95":
+			return l[i].P95 > l[j].P95
+		case "Max":
+			return l[i].Max > l[j].Max
+		default:
+			return l[i].Total > l[j].Total
+		}
+	})
+}
+
+// SlowLogPolls is what is known of the server's slow log from polling it over the session.
+type SlowLogPolls struct {
+	Polls    int
+	LastPoll time.Time
+	// Newest entry ID seen, -1 before any
+	LastID int64
+	// SLOWLOG LEN at the last poll
+	Len int64
+	// Entries rotated out of the slow log, or reset, before a poll saw them
+	Dropped int64
+}
+
+// Merge adds the entries of a SLOWLOG GET reply not seen before to the session history, kept
+// newest first and capped at limit entries unless limit is 0. It returns the new history and
+// the added entries, newest first. IDs going back mean the server restarted and all entries
+// are new.
+func (p *SlowLogPolls) Merge(history, reply SlowLogList, length int64, at time.Time, limit int) (SlowLogList, SlowLogList) {
+	reply = append(SlowLogList{}, reply...)
+	reply.Sort("ID")
+	if len(reply) > 0 && reply[0].ID < p.LastID {
+		p.LastID = -1
+	}
+
+	var added SlowLogList
+	for _, e := range reply {
+		if e.ID <= p.LastID {
+			break
+		}
+		added = append(added, e)
+	}
+	if len(added) > 0 {
+		// The first poll has no earlier ID to count gaps from
+		if oldest := added[len(added)-1].ID; p.LastID >= 0 && oldest > p.LastID+1 {
+			p.Dropped += oldest - p.LastID - 1
+		}
+		p.LastID = added[0].ID
+	}
+	p.Polls++
+	p.LastPoll = at
+	p.Len = length
+
+	merged := append(append(SlowLogList{}, added...), history...)
+	if limit > 0 && len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged, added
+}
+
+// NewSince counts the entries of a newest first history with an ID above id.
+func (d SlowLogList) NewSince(id int64) int {
+	for i, e := range d {
+		if e.ID <= id {
+			return i
+		}
+	}
+	return len(d)
+}
+
+// SlowLogHistory is the slow log polled over the session, newest first, with what is known of
+// the polls. Safe for concurrent use.
+type SlowLogHistory struct {
+	mu      sync.RWMutex
+	entries SlowLogList
+	polls   SlowLogPolls
+}
+
+func NewSlowLogHistory() *SlowLogHistory {
+	return &SlowLogHistory{entries: SlowLogList{}, polls: SlowLogPolls{LastID: -1}}
+}
+
+// Merge adds a SLOWLOG GET reply to the history as SlowLogPolls.Merge does, returning the
+// added entries.
+func (h *SlowLogHistory) Merge(reply SlowLogList, length int64, at time.Time, limit int) SlowLogList {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	merged, added := h.polls.Merge(h.entries, reply, length, at, limit)
+	h.entries = merged
+	return added
+}
+
+// LastID is the newest entry ID seen, -1 before any.
+func (h *SlowLogHistory) LastID() int64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.polls.LastID
+}
+
+// Entries returns the history, newest first. Merges replace it rather than change it in place.
+func (h *SlowLogHistory) Entries() SlowLogList {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.entries
+}
+
+func (h *SlowLogHistory) Polls() SlowLogPolls {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.polls
+}
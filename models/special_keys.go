@@ -4,22 +4,80 @@ import (
 	"sort"
 )
 
+// Sources a hot key can be found by
+const (
+	HotKeySourceMonitor  = "MONITOR"
+	HotKeySourceKeyspace = "KEYSPACE"
+	HotKeySourceAOF      = "AOF"
+	HotKeySourceLFU      = "LFU"
+)
+
 type HotKey struct {
 	Key Key
-	Ops float64
+	// Key as stored in Redis, and its database
+	Name string
+	DB   int
+	Ops  float64
+	// Decayed LFU counter, for keys found by LFU sampling
+	Freq    int64
+	Sources []string
 }
 
 type HotKeyList []HotKey
 
+// Sort orders keys by observed ops, then by LFU counter for keys only found by sampling.
 func (h HotKeyList) Sort() {
 	sort.Slice(h, func(i, j int) bool {
-		return h[i].Ops > h[j].Ops
+		if h[i].Ops != h[j].Ops {
+			return h[i].Ops > h[j].Ops
+		}
+		return h[i].Freq > h[j].Freq
 	})
 }
 
+// MergeHotKeys combines hot key lists from several sources, joining entries for the same key,
+// and keeps the hottest limit of them.
+func MergeHotKeys(limit int64, lists ...HotKeyList) HotKeyList {
+	index := make(map[string]int)
+	var merged HotKeyList
+	for _, list := range lists {
+		for _, hk := range list {
+			i, ok := index[hk.Key.String()]
+			if !ok {
+				index[hk.Key.String()] = len(merged)
+				hk.Sources = append([]string(nil), hk.Sources...)
+				merged = append(merged, hk)
+				continue
+			}
+			merged[i].Ops += hk.Ops
+			merged[i].Freq = max(merged[i].Freq, hk.Freq)
+			merged[i].Sources = append(merged[i].Sources, hk.Sources...)
+		}
+	}
+	merged.Sort()
+	if int64(len(merged)) > limit {
+		merged = merged[:limit]
+	}
+	return merged
+}
+
+// DecayLFU lowers an LFU counter sampled elapsed seconds ago the way Redis does for idle keys,
+// by one per decay minutes. A decay of zero never decays.
+func DecayLFU(freq, elapsed, decay int64) int64 {
+	if decay <= 0 || elapsed <= 0 {
+		return freq
+	}
+	return max(freq-elapsed/(decay*60), 0)
+}
+
 type BigKey struct {
-	Key  Key
-	Size int64
+	Key Key
+	// Key as stored in Redis, and its database
+	Name     string
+	DB       int
+	Size     int64
+	Type     string
+	Elements int64
 }
 
 type BigKeyList []BigKey
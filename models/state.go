@@ -8,21 +8,78 @@ type State struct {
 	//Current execution state variables
 	CurrentPrefix Key
 
+	// Logical databases under analysis, each scanned with its own cursor. When DBLevel is
+	// set the database name is the top level of the namespace hierarchy.
+	DBs             []int
+	DBLevel         bool
+	ScanDB          int
+	Cursors         map[int]uint64
+	ScannedKeysByDB map[int]int64
+
 	LastInfoCheck        time.Time
 	TotalMonitorDuration time.Duration
-	Cursor               uint64
 	ScannedKeys          int64
 
+	// When the analyzed RDB dump was taken, zero for a live server
+	SnapshotTime time.Time
+
+	// Set once ops come from keyspace notifications, which only report writes
+	WriteOpsOnly bool
+	// Set once keys expired or evicted by the server are tracked, through keyspace notifications
+	RemovalsTracked bool
+
+	// Rolling per-second ops per namespace, nil unless in live mode
+	Live *LiveCounters
+
+	// Time buckets for ops replayed from an AOF with timestamp annotations, nil otherwise
+	OpsTimeline *Timeline
+	// Time ranges of replayed ops sources, whose union counts as monitored
+	ReplayedSpans TimeSpans
+	// Set once ops are replayed from an AOF without timestamp annotations
+	UntimedOps bool
+
 	// Redis Info
 	RedisInfo *RedisInfo
 
+	// Whether the load guard holds back scanning or monitoring
+	LoadGuard LoadGuardState
+
+	// Latency monitor events and spikes, refreshed with the info
+	Latency LatencyReport
+
+	// Whether the client refuses writes, and whether the ACL user could make them
+	ReadOnly bool
+	ACL      ACLStatus
+
+	// Compact encoding thresholds from CONFIG GET and the compact memory footprint seen in the scan log
+	EncodingThresholds EncodingThresholds
+	CompactFootprint   *CompactFootprint
+
 	//Current Prefix and its analysis
 	NamespaceStats NamespaceMetricList
 
-	// Special Keys for Debugging and Analysis
-	SlowLogs SlowLogList
-	HotKeys  HotKeyList
-	BigKeys  BigKeyList
+	// Special Keys for Debugging and Analysis. HotKeys merges the hot keys found in the monitor
+	// log with those ranked by LFU counter in the scan log. SlowLog is the slow log polled over
+	// the session.
+	SlowLog    *SlowLogHistory
+	HotKeys    HotKeyList
+	OpsHotKeys HotKeyList
+	LFUHotKeys HotKeyList
+	BigKeys    BigKeyList
+
+	// Progress of the running or last bulk expire or unlink, nil before the first
+	Action *ActionProgress
+
+	// Namespace metric samples recorded this session, for trends
+	History *MetricHistory
+
+	// Namespaces that grew the most across the runs in the history database
+	HistoryGrowers NamespaceGrowthList
+	// Namespace metrics of this run far from their baseline over past runs
+	Anomalies AnomalyList
+
+	// Minutes for an idle key's LFU counter to decay by one
+	LFUDecayTime int64
 
 	//Chan to send updates
 	Updates chan *State
@@ -43,13 +100,23 @@ func NewState() *State {
 	return &State{
 		CurrentPrefix:        Key{},
 		LastInfoCheck:        time.Unix(0, 0),
+		DBs:                  []int{},
+		DBLevel:              false,
+		ScanDB:               0,
+		Cursors:              make(map[int]uint64),
+		ScannedKeysByDB:      make(map[int]int64),
 		TotalMonitorDuration: 0,
-		Cursor:               0,
 		ScannedKeys:          0,
 		RedisInfo:            &RedisInfo{},
+		EncodingThresholds:   DefaultEncodingThresholds(),
+		CompactFootprint:     NewCompactFootprint(),
 		NamespaceStats:       NamespaceMetricList{},
-		SlowLogs:             SlowLogList{},
+		SlowLog:              NewSlowLogHistory(),
 		HotKeys:              HotKeyList{},
+		OpsHotKeys:           HotKeyList{},
+		LFUHotKeys:           HotKeyList{},
+		LFUDecayTime:         1,
+		History:              NewMetricHistory(),
 		BigKeys:              BigKeyList{},
 		Updates:              make(chan *State, 100), // Buffered channel for updates
 		Status:               "Initializing",
@@ -61,3 +128,19 @@ func NewState() *State {
 		MonitorDurationTotal: 0,
 	}
 }
+
+// Analyzes reports whether the given logical database is under analysis.
+func (s *State) Analyzes(db int) bool {
+	for _, d := range s.DBs {
+		if d == db {
+			return true
+		}
+	}
+	return false
+}
+
+// OpsAreCounts reports whether ops are raw command counts rather than rates, with no monitored
+// time to rate them over, as after replaying an AOF without timestamps.
+func (s *State) OpsAreCounts() bool {
+	return s.UntimedOps && s.TotalMonitorDuration == 0
+}
@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// timelineBuckets are the bucket widths a timeline may use, smallest first.
+var timelineBuckets = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// MaxTimelineBuckets bounds the number of buckets a timeline spans, so it fits a sparkline.
+const MaxTimelineBuckets = 60
+
+// Timeline divides a time range into equal buckets, used to spread replayed ops over time.
+type Timeline struct {
+	Start   time.Time
+	Bucket  time.Duration
+	Buckets int
+}
+
+// NewTimeline picks the smallest bucket width that covers start to end in at most
+// MaxTimelineBuckets buckets.
+func NewTimeline(start, end time.Time) *Timeline {
+	span := end.Sub(start)
+	bucket := timelineBuckets[len(timelineBuckets)-1]
+	for _, b := range timelineBuckets {
+		if span < b*MaxTimelineBuckets {
+			bucket = b
+			break
+		}
+	}
+
+	start = start.Truncate(bucket)
+	return &Timeline{
+		Start:   start,
+		Bucket:  bucket,
+		Buckets: int(end.Sub(start)/bucket) + 1,
+	}
+}
+
+// Index returns the bucket holding t, clamped to the timeline.
+func (t *Timeline) Index(at time.Time) int {
+	i := int(at.Sub(t.Start) / t.Bucket)
+	return min(max(i, 0), t.Buckets-1)
+}
+
+// TimeSpan is the time range of a replayed ops source.
+type TimeSpan struct {
+	Start time.Time
+	End   time.Time
+}
+
+// TimeSpans are disjoint time ranges, sorted by start.
+type TimeSpans []TimeSpan
+
+// Add returns the spans with start to end added, merging those it overlaps, so that sources
+// covering the same period count it once.
+func (s TimeSpans) Add(start, end time.Time) TimeSpans {
+	merged := make(TimeSpans, 0, len(s)+1)
+	added := TimeSpan{Start: start, End: end}
+	for _, span := range s {
+		switch {
+		case span.End.Before(added.Start):
+			merged = append(merged, span)
+		case added.End.Before(span.Start):
+			merged = append(merged, added)
+			added = span
+		default:
+			if span.Start.Before(added.Start) {
+				added.Start = span.Start
+			}
+			if span.End.After(added.End) {
+				added.End = span.End
+			}
+		}
+	}
+	return append(merged, added)
+}
+
+// Duration is the time the spans cover together.
+func (s TimeSpans) Duration() time.Duration {
+	var total time.Duration
+	for _, span := range s {
+		total += span.End.Sub(span.Start)
+	}
+	return total
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"math"
+	"sort"
+)
+
+// Rect is an area of a treemap.
+type Rect struct {
+	X, Y, W, H float64
+}
+
+// Squarify lays values out as rectangles filling r, each with an area proportional to its
+// value and as close to square as the squarified treemap algorithm gets them. The rects are
+// returned in the order of values; values that aren't positive get empty rects.
+func Squarify(values []float64, r Rect) []Rect {
+	rects := make([]Rect, len(values))
+
+	var total float64
+	order := make([]int, 0, len(values))
+	for i, v := range values {
+		if v > 0 {
+			total += v
+			order = append(order, i)
+		}
+	}
+	if total == 0 || r.W <= 0 || r.H <= 0 {
+		return rects
+	}
+	// Largest first, which keeps the rows the algorithm builds close to square
+	sort.SliceStable(order, func(a, b int) bool { return values[order[a]] > values[order[b]] })
+
+	areas := make([]float64, len(order))
+	for i, idx := range order {
+		areas[i] = values[idx] / total * r.W * r.H
+	}
+
+	free := r
+	start := 0
+	for start < len(areas) {
+		side := math.Min(free.W, free.H)
+		end := start + 1
+		for end < len(areas) && worstRatio(areas[start:end+1], side) <= worstRatio(areas[start:end], side) {
+			end++
+		}
+		free = layoutRow(areas[start:end], order[start:end], free, rects)
+		start = end
+	}
+	return rects
+}
+
+// worstRatio is the highest aspect ratio among a row of areas laid along a side.
+func worstRatio(row []float64, side float64) float64 {
+	var sum, lo, hi float64
+	lo = math.Inf(1)
+	for _, a := range row {
+		sum += a
+		lo = math.Min(lo, a)
+		hi = math.Max(hi, a)
+	}
+	side2, sum2 := side*side, sum*sum
+	return math.Max(side2*hi/sum2, sum2/(side2*lo))
+}
+
+// layoutRow places a row of areas along the shorter side of free and returns what is left.
+func layoutRow(row []float64, order []int, free Rect, rects []Rect) Rect {
+	var sum float64
+	for _, a := range row {
+		sum += a
+	}
+
+	if free.W >= free.H {
+		// A column on the left
+		width := sum / free.H
+		y := free.Y
+		for i, a := range row {
+			height := a / width
+			rects[order[i]] = Rect{X: free.X, Y: y, W: width, H: height}
+			y += height
+		}
+		return Rect{X: free.X + width, Y: free.Y, W: free.W - width, H: free.H}
+	}
+
+	// A row along the top
+	height := sum / free.W
+	x := free.X
+	for i, a := range row {
+		width := a / height
+		rects[order[i]] = Rect{X: x, Y: free.Y, W: width, H: height}
+		x += width
+	}
+	return Rect{X: free.X, Y: free.Y + height, W: free.W, H: free.H - height}
+}
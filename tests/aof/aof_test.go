@@ -0,0 +1,81 @@
+package aof_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"redscout/lib/aof"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, input string) ([]aof.Command, error) {
+	t.Helper()
+	var cmds []aof.Command
+	err := aof.Read(strings.NewReader(input), func(c aof.Command) error {
+		cmds = append(cmds, c)
+		return nil
+	})
+	return cmds, err
+}
+
+func TestReadCommands(t *testing.T) {
+	input := "*2\r\n$6\r\nSELECT\r\n$1\r\n3\r\n" +
+		"#TS:1700000000\r\n" +
+		"*3\r\n$3\r\nset\r\n$10\r\nuser:1 foo\r\n$3\r\nbar\r\n" +
+		"*1\r\n$5\r\nMULTI\r\n" +
+		"#TS:1700000060\r\n" +
+		"*2\r\n$3\r\ndel\r\n$6\r\nuser:2\r\n"
+
+	cmds, err := readAll(t, input)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	want := []aof.Command{
+		{DB: 3, Args: []string{"set", "user:1 foo", "bar"}, Time: time.Unix(1700000000, 0)},
+		{DB: 3, Args: []string{"MULTI"}, Time: time.Unix(1700000000, 0)},
+		{DB: 3, Args: []string{"del", "user:2"}, Time: time.Unix(1700000060, 0)},
+	}
+	if !reflect.DeepEqual(cmds, want) {
+		t.Errorf("Read() = %+v, want %+v", cmds, want)
+	}
+}
+
+func TestReadTruncated(t *testing.T) {
+	input := "*2\r\n$3\r\ndel\r\n$6\r\nuser:2\r\n" +
+		"*2\r\n$3\r\ndel\r\n$6\r\nuse"
+
+	cmds, err := readAll(t, input)
+	if !errors.Is(err, aof.ErrTruncated) {
+		t.Fatalf("Read() error = %v, want ErrTruncated", err)
+	}
+	if len(cmds) != 1 {
+		t.Errorf("Read() returned %d commands before the truncated tail, want 1", len(cmds))
+	}
+}
+
+func TestFilesFromManifest(t *testing.T) {
+	dir := t.TempDir()
+	manifest := "file appendonly.aof.1.base.rdb seq 1 type b\n" +
+		"file appendonly.aof.3.incr.aof seq 3 type i\n" +
+		"file appendonly.aof.2.incr.aof seq 2 type i\n"
+	if err := os.WriteFile(filepath.Join(dir, "appendonly.aof.manifest"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := aof.Files(dir)
+	if err != nil {
+		t.Fatalf("Files() error = %v", err)
+	}
+
+	want := []string{
+		filepath.Join(dir, "appendonly.aof.2.incr.aof"),
+		filepath.Join(dir, "appendonly.aof.3.incr.aof"),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Files() = %v, want %v", files, want)
+	}
+}
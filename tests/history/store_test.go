@@ -0,0 +1,56 @@
+package history_test
+
+import (
+	"path/filepath"
+	"redscout/lib/history"
+	"redscout/models"
+	"testing"
+	"time"
+)
+
+func TestStoreRuns(t *testing.T) {
+	store, err := history.Open(filepath.Join(t.TempDir(), "history", "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		run := models.HistoryRun{
+			Time:       day.AddDate(0, 0, i),
+			Source:     "localhost:6379/0",
+			Namespaces: []models.HistoryNamespace{{Namespace: "user", EstKeys: int64(i)}},
+		}
+		if err := store.Record(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Record(models.HistoryRun{Time: day, Source: "rdb:dump.rdb"}); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := store.Runs("localhost:6379/0", time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || !all[0].Time.Equal(day) || all[2].Namespaces[0].EstKeys != 2 {
+		t.Errorf("all runs = %+v", all)
+	}
+
+	ranged, err := store.Runs("localhost:6379/0", day.AddDate(0, 0, 1), day.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranged) != 1 || ranged[0].Namespaces[0].EstKeys != 1 {
+		t.Errorf("ranged runs = %+v", ranged)
+	}
+
+	sources, err := store.Sources()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 2 {
+		t.Errorf("sources = %v, want 2", sources)
+	}
+}
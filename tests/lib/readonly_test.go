@@ -0,0 +1,62 @@
+package lib_test
+
+import (
+	"context"
+	"errors"
+	"redscout/lib"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestCommandAllowed(t *testing.T) {
+	tests := []struct {
+		args []interface{}
+		want bool
+	}{
+		{[]interface{}{"scan", 0, "count", 100}, true},
+		{[]interface{}{"MEMORY", "USAGE", "user:1"}, true},
+		{[]interface{}{"slowlog", "get", 10}, true},
+		{[]interface{}{"slowlog", "reset"}, false},
+		{[]interface{}{"client", "no-touch", "on"}, true},
+		{[]interface{}{"set", "user:1", "x"}, false},
+		{[]interface{}{"unlink", "user:1"}, false},
+		{[]interface{}{"flushall"}, false},
+		{[]interface{}{"config", "get", "maxmemory"}, true},
+		{[]interface{}{"config", "set", "notify-keyspace-events", "KEA"}, false},
+		{[]interface{}{"config", "set", "notify-keyspace-events", "KEA", "maxmemory", "1"}, false},
+		{[]interface{}{"config", "set", "dir", "/tmp"}, false},
+		{[]interface{}{"config", "set", "latency-monitor-threshold", "100"}, false},
+		{[]interface{}{"latency", "reset"}, false},
+		{[]interface{}{}, false},
+	}
+	for _, tt := range tests {
+		if got := lib.CommandAllowed(tt.args); got != tt.want {
+			t.Errorf("CommandAllowed(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+// Refused commands never reach the server, so none is needed
+func TestReadOnlyHookRefusesWrites(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer client.Close()
+	client.AddHook(lib.ReadOnlyHook{})
+	ctx := context.Background()
+
+	if err := client.Set(ctx, "user:1", "x", 0).Err(); !errors.Is(err, lib.ErrReadOnly) {
+		t.Errorf("SET error = %v, want ErrReadOnly", err)
+	}
+
+	pipe := client.Pipeline()
+	pipe.Type(ctx, "user:1")
+	del := pipe.Del(ctx, "user:1")
+	if _, err := pipe.Exec(ctx); !errors.Is(err, lib.ErrReadOnly) || !errors.Is(del.Err(), lib.ErrReadOnly) {
+		t.Errorf("pipeline error = %v, DEL error = %v, want ErrReadOnly", err, del.Err())
+	}
+
+	// Allowed commands go on to the server, which isn't there
+	if err := client.Type(ctx, "user:1").Err(); err == nil || errors.Is(err, lib.ErrReadOnly) {
+		t.Errorf("TYPE error = %v, want a connection error", err)
+	}
+}
@@ -0,0 +1,33 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+)
+
+func TestACLRulesCanWrite(t *testing.T) {
+	tests := []struct {
+		rules string
+		want  bool
+	}{
+		{"+@all", true},
+		{"-@all +@read +@connection", false},
+		// Scripts aren't in @write and can still write
+		{"+@all -@write", true},
+		{"+@all -@write -@scripting", false},
+		{"+@all -@scripting -@write", false},
+		{"+@all -@write -@scripting +eval", true},
+		{"-@all +@read +@scripting", true},
+		{"+@all -@scripting", true},
+		{"+@all -@write +set", true},
+		{"-@all +@read +@hash", true},
+		{"-@all +info +scan +memory|usage", false},
+		{"allcommands", true},
+		{"nocommands", false},
+	}
+	for _, tt := range tests {
+		if got := models.ACLRulesCanWrite(tt.rules); got != tt.want {
+			t.Errorf("ACLRulesCanWrite(%q) = %v, want %v", tt.rules, got, tt.want)
+		}
+	}
+}
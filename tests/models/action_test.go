@@ -0,0 +1,48 @@
+package models_test
+
+import (
+	"redscout/models"
+	"regexp"
+	"testing"
+)
+
+func TestNamespacePattern(t *testing.T) {
+	tests := []struct {
+		prefix    models.Key
+		delimiter string
+		want      string
+	}{
+		{models.Key{"user"}, ":", "user:*"},
+		{models.Key{"user", "{id}", "cart"}, ":", "user:*:cart:*"},
+		{models.Key{"promo*", "[beta]?"}, "/", `promo\*/\[beta\]\?/*`},
+		{models.Key{`back\slash`}, ":", `back\\slash:*`},
+	}
+	for _, tt := range tests {
+		if got := models.NamespacePattern(tt.prefix, tt.delimiter); got != tt.want {
+			t.Errorf("NamespacePattern(%v, %q) = %q, want %q", tt.prefix, tt.delimiter, got, tt.want)
+		}
+	}
+}
+
+func TestBulkActionCovers(t *testing.T) {
+	kp := models.NewKeyParser(":", []*regexp.Regexp{regexp.MustCompile(`^\d+$`)})
+	action := models.BulkAction{Prefix: models.Key{"user", "{id}"}}
+
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"user:42:cart", true},
+		{"user:42:cart:items", true},
+		// The key naming the namespace itself
+		{"user:42", false},
+		{"user", false},
+		{"user:bob:cart", false},
+		{"users:42:cart", false},
+	}
+	for _, tt := range tests {
+		if got := action.Covers(kp, kp.NewKey(tt.key, false)); got != tt.want {
+			t.Errorf("Covers(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+)
+
+func TestBaselinesScore(t *testing.T) {
+	run := func(memory int64) models.NamespaceMetricList {
+		return models.NamespaceMetricList{{
+			Namespace: "session",
+			EstKeys:   100,
+			EstMemory: memory,
+			Ops:       map[models.OpType]float64{models.TotalOp: 10},
+		}}
+	}
+
+	baselines := make(models.Baselines)
+	for i, memory := range []int64{1000, 1040, 980, 1010} {
+		// Not scored before the baseline is warmed up
+		if i < models.BaselineWarmup && len(baselines.Score(run(50000), true)) != 0 {
+			t.Fatalf("scored after %d runs", i)
+		}
+		baselines.Update(run(memory), true)
+	}
+
+	if got := baselines.Score(run(1030), true); len(got) != 0 {
+		t.Errorf("usual run flagged: %+v", got)
+	}
+
+	got := baselines.Score(run(5000), false)
+	if len(got) != 1 || got[0].Metric != models.MetricMemory || got[0].Score < models.AnomalyThreshold {
+		t.Errorf("anomalies = %+v, want memory only", got)
+	}
+}
@@ -0,0 +1,114 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+)
+
+func TestParseEncodingThresholds(t *testing.T) {
+	thresholds := models.ParseEncodingThresholds(map[string]string{
+		"hash-max-ziplist-entries":  "64",
+		"hash-max-listpack-entries": "256",
+		"hash-max-listpack-value":   "1024",
+		"zset-max-ziplist-entries":  "32",
+		"zset-max-ziplist-value":    "32",
+	})
+
+	tests := []struct {
+		keyType    string
+		wantConfig string
+		wantMax    int64
+		wantValue  string
+		wantMaxVal int64
+	}{
+		{"hash", "hash-max-listpack-entries", 256, "hash-max-listpack-value", 1024},
+		{"zset", "zset-max-ziplist-entries", 32, "zset-max-ziplist-value", 32},
+		{"set", "set-max-listpack-entries", 128, "set-max-listpack-value", 64},
+		{models.IntsetThreshold, "set-max-intset-entries", 512, "", 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyType, func(t *testing.T) {
+			got := thresholds[tt.keyType]
+			if got.Config != tt.wantConfig || got.MaxEntries != tt.wantMax {
+				t.Errorf("threshold = %+v, want %s=%d", got, tt.wantConfig, tt.wantMax)
+			}
+			if got.ValueConfig != tt.wantValue || got.MaxValue != tt.wantMaxVal {
+				t.Errorf("value threshold = %+v, want %s=%d", got, tt.wantValue, tt.wantMaxVal)
+			}
+		})
+	}
+}
+
+func TestEncodingThresholdsNearThreshold(t *testing.T) {
+	thresholds := models.DefaultEncodingThresholds()
+
+	tests := []struct {
+		name       string
+		keyType    string
+		encoding   string
+		elements   int64
+		entryBytes int64
+		want       bool
+	}{
+		{"just above", "hash", "hashtable", 130, 80, true},
+		{"at factor limit", "hash", "hashtable", 256, 80, true},
+		{"far above", "hash", "hashtable", 257, 80, false},
+		{"still compact", "hash", "listpack", 100, 80, false},
+		{"untracked type", "string", "raw", 130, 80, false},
+		// Fields and values of 64 bytes each still fit a listpack
+		{"hash at value threshold", "hash", "hashtable", 200, 48 + 128, true},
+		{"hash with long values", "hash", "hashtable", 200, 500, false},
+		{"zset with long members", "zset", "skiplist", 200, 96 + 65, false},
+		{"set past listpack", "set", "hashtable", 200, 50, true},
+		{"set between thresholds", "set", "hashtable", 300, 50, false},
+		{"set past intset", "set", "hashtable", 600, 50, true},
+		{"set of strings past intset", "set", "hashtable", 600, 80, false},
+		{"set far past intset", "set", "hashtable", 1025, 50, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thresholds.NearThreshold(tt.keyType, tt.encoding, tt.elements, tt.elements*tt.entryBytes, 2); got != tt.want {
+				t.Errorf("NearThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEncodingThresholdsBeforeSetListpack(t *testing.T) {
+	thresholds := models.ParseEncodingThresholds(map[string]string{
+		"set-max-intset-entries": "256",
+	})
+
+	if _, ok := thresholds["set"]; ok {
+		t.Errorf("set listpack threshold kept without set-max-listpack-entries")
+	}
+	if got := thresholds[models.IntsetThreshold]; got.MaxEntries != 256 {
+		t.Errorf("intset threshold = %d, want 256", got.MaxEntries)
+	}
+	if thresholds.NearThreshold("set", "hashtable", 200, 200*50, 2) {
+		t.Errorf("NearThreshold() = true for a set under the intset threshold")
+	}
+}
+
+func TestEncodingThresholdsThreshold(t *testing.T) {
+	thresholds := models.DefaultEncodingThresholds()
+
+	tests := []struct {
+		keyType  string
+		elements int64
+		want     string
+	}{
+		{"set", 200, "set"},
+		{"set", 512, "set"},
+		{"set", 513, models.IntsetThreshold},
+		{"hash", 600, "hash"},
+	}
+
+	for _, tt := range tests {
+		if got, _ := thresholds.Threshold(tt.keyType, tt.elements); got != tt.want {
+			t.Errorf("Threshold(%q, %d) = %q, want %q", tt.keyType, tt.elements, got, tt.want)
+		}
+	}
+}
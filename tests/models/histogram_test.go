@@ -0,0 +1,99 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+)
+
+func TestTTLHistogramBuckets(t *testing.T) {
+	const day = 86400
+	tests := []struct {
+		name   string
+		ttl    int64
+		bucket int
+		counts bool
+	}{
+		{"no ttl", -1, 0, false},
+		{"gone", -2, 0, false},
+		{"already expired", 0, 0, false},
+		{"first second", 1, 0, true},
+		{"first bound is inclusive", 60, 0, true},
+		{"past first bound", 61, 1, true},
+		{"a day", day, 4, true},
+		{"last bound", 30 * day, 6, true},
+		{"past last bound", 30*day + 1, 7, true},
+		{"a year", 365 * day, 7, true},
+	}
+	for _, tt := range tests {
+		snapshot := models.NewNamespaceSnapshot("session", 0)
+		snapshot.AddTTL(tt.ttl, 100)
+		if got := snapshot.TTLHistogram.Total() == 1; got != tt.counts {
+			t.Errorf("%s: counted = %v, want %v", tt.name, got, tt.counts)
+			continue
+		}
+		if !tt.counts {
+			if snapshot.KeysWithTTL != 0 {
+				t.Errorf("%s: KeysWithTTL = %d, want 0", tt.name, snapshot.KeysWithTTL)
+			}
+			continue
+		}
+		if snapshot.TTLHistogram.Memory[tt.bucket] != 100 {
+			t.Errorf("%s: memory by bucket = %v, want 100 in bucket %d", tt.name, snapshot.TTLHistogram.Memory, tt.bucket)
+		}
+	}
+}
+
+func TestMemoryUpTo(t *testing.T) {
+	h := models.NewHistogram(models.DurationBuckets)
+	h.Add(30, 1)          // ≤1m
+	h.Add(300, 10)        // ≤10m
+	h.Add(3600, 100)      // ≤1h
+	h.Add(2*86400, 1000)  // ≤7d
+	h.Add(90*86400, 5000) // beyond 30d
+
+	tests := []struct {
+		bound int64
+		want  int64
+	}{
+		{59, 0},
+		{60, 1},
+		{3600, 111},
+		// Between bounds only the buckets fully below count
+		{5000, 111},
+		{30 * 86400, 1111},
+		// The last bucket is unbounded, so never within a horizon
+		{1 << 62, 1111},
+	}
+	for _, tt := range tests {
+		if got := h.MemoryUpTo(tt.bound); got != tt.want {
+			t.Errorf("MemoryUpTo(%d) = %d, want %d", tt.bound, got, tt.want)
+		}
+	}
+}
+
+func TestExpiryForecast(t *testing.T) {
+	if got := (&models.NamespaceMetrics{}).ExpiryForecast(3600); got != 0 {
+		t.Errorf("ExpiryForecast without TTLs = %d, want 0", got)
+	}
+
+	snapshot := models.NewNamespaceSnapshot("session", 0)
+	for _, ttl := range []int64{-1, 30, 1800, 86400} {
+		snapshot.Keys++
+		snapshot.TotalMemory += 100
+		snapshot.AddTTL(ttl, 100)
+	}
+	// The sample holds half the keys of db0, so the forecast doubles
+	s := models.NewState()
+	s.ScannedKeysByDB[0] = 4
+	s.RedisInfo = &models.RedisInfo{Keyspace: map[string]models.KeyspaceInfo{"db0": {Keys: 8}}}
+	m := snapshot.ToMetric(s)
+
+	for within, want := range map[int64]int64{60: 200, 3600: 400, 86400: 600} {
+		if got := m.ExpiryForecast(within); got != want {
+			t.Errorf("ExpiryForecast(%d) = %d, want %d", within, got, want)
+		}
+	}
+	if m.TTLPercent != 0.75 {
+		t.Errorf("TTLPercent = %v, want 0.75", m.TTLPercent)
+	}
+}
@@ -0,0 +1,90 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+	"time"
+)
+
+func TestTopGrowers(t *testing.T) {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := []models.HistoryRun{
+		{Time: day, Namespaces: []models.HistoryNamespace{
+			{Namespace: "session", EstMemory: 100},
+			{Namespace: "user", EstMemory: 500},
+		}},
+		{Time: day.AddDate(0, 0, 1), Namespaces: []models.HistoryNamespace{
+			{Namespace: "session", EstMemory: 900},
+			{Namespace: "user", EstMemory: 400},
+			// Only seen once, nothing to compare
+			{Namespace: "cart", EstMemory: 10000},
+		}},
+	}
+
+	growers := models.TopGrowers(runs, 10)
+	if len(growers) != 2 {
+		t.Fatalf("got %d growers, want 2", len(growers))
+	}
+	if growers[0].Namespace != "session" || growers[0].MemoryGrowth() != 800 {
+		t.Errorf("top grower = %s %+d, want session +800", growers[0].Namespace, growers[0].MemoryGrowth())
+	}
+	if growers[1].MemoryGrowth() != -100 {
+		t.Errorf("user growth = %d, want -100", growers[1].MemoryGrowth())
+	}
+	if got := models.TopGrowers(runs, 1); len(got) != 1 {
+		t.Errorf("got %d growers with n=1", len(got))
+	}
+
+	trend := models.NamespaceTrend(runs, "cart")
+	if len(trend) != 1 || trend[0].EstMemory != 10000 {
+		t.Errorf("cart trend = %+v", trend)
+	}
+}
+
+func TestHistorySource(t *testing.T) {
+	live := models.DefaultConfig()
+	live.RedisHost, live.RedisPort = "redis.example.com", 6379
+	// A database analyzed with --all-dbs is the same source as analyzed alone
+	allDBs := live
+	allDBs.AllDBs = true
+
+	dump := models.DefaultConfig()
+	dump.RDBFile = "/backups/a/dump.rdb"
+	other := dump
+	other.RDBFile = "/backups/b/dump.rdb"
+
+	tests := []struct {
+		name string
+		cfg  models.Config
+		db   int
+		want string
+	}{
+		{"single db", live, 0, "redis.example.com:6379/0"},
+		{"all dbs", allDBs, 1, "redis.example.com:6379/1"},
+		{"dump", dump, 0, "rdb:/backups/a/dump.rdb/0"},
+		{"dump of another server", other, 0, "rdb:/backups/b/dump.rdb/0"},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.HistorySource(tt.db); got != tt.want {
+			t.Errorf("%s: HistorySource(%d) = %q, want %q", tt.name, tt.db, got, tt.want)
+		}
+	}
+}
+
+func TestNewHistoryRunOfDB(t *testing.T) {
+	s := models.NewState()
+	s.RedisInfo = &models.RedisInfo{Keyspace: map[string]models.KeyspaceInfo{"db0": {Keys: 10}, "db1": {Keys: 20}}}
+	s.BigKeys = models.BigKeyList{{Name: "a", DB: 0}, {Name: "b", DB: 1}}
+	metrics := models.NamespaceMetricList{{Namespace: "session", DB: 1, EstKeys: 20}}
+
+	run := models.NewHistoryRun(s, 1, metrics, "redis:6379/1", time.Now())
+	if run.Keys != 20 {
+		t.Errorf("Keys = %d, want the 20 of db1", run.Keys)
+	}
+	if len(run.BigKeys) != 1 || run.BigKeys[0].Name != "b" {
+		t.Errorf("BigKeys = %+v, want only b of db1", run.BigKeys)
+	}
+	if len(run.Namespaces) != 1 || run.Namespaces[0].Namespace != "session" {
+		t.Errorf("Namespaces = %+v, want session", run.Namespaces)
+	}
+}
@@ -0,0 +1,52 @@
+package models_test
+
+import (
+	"redscout/models"
+	"strings"
+	"testing"
+)
+
+func TestFormatValue(t *testing.T) {
+	got := models.FormatValue(`{"id":1,"tags":["a"]}`)
+	if len(got) < 3 || got[1] != `  "id": 1,` {
+		t.Errorf("JSON not pretty-printed: %q", got)
+	}
+
+	got = models.FormatValue("\x00\x01binary\xff")
+	if len(got) != 1 || !strings.HasPrefix(got[0], "00000000  00 01 62") {
+		t.Errorf("binary not hex dumped: %q", got)
+	}
+
+	if got := models.FormatValue("plain {text"); len(got) != 1 || got[0] != "plain {text" {
+		t.Errorf("plain text changed: %q", got)
+	}
+}
+
+func TestFormatElement(t *testing.T) {
+	tests := map[string]string{
+		"\x00\xff":          "0x00ff",
+		"{ \"a\": [1, 2] }": `{"a":[1,2]}`,
+		"two\nlines":        `two\nlines`,
+	}
+	for value, want := range tests {
+		if got := models.FormatElement(value); got != want {
+			t.Errorf("FormatElement(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestNextStreamID(t *testing.T) {
+	tests := map[string]string{
+		"1700000000000-0":                    "1700000000000-1",
+		"1700000000000-41":                   "1700000000000-42",
+		"1700000000000-18446744073709551615": "1700000000001-0",
+	}
+	for id, want := range tests {
+		if got, err := models.NextStreamID(id); err != nil || got != want {
+			t.Errorf("NextStreamID(%q) = %q, %v, want %q", id, got, err, want)
+		}
+	}
+	if _, err := models.NextStreamID("not-an-id"); err == nil {
+		t.Errorf("NextStreamID accepted an invalid ID")
+	}
+}
@@ -0,0 +1,40 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+	"time"
+)
+
+func TestParseLatencyHistory(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{int64(1700000010), int64(250)},
+		[]interface{}{int64(1700000000), int64(120)},
+		[]interface{}{"malformed"},
+	}
+	samples := models.ParseLatencyHistory(reply)
+	if len(samples) != 2 {
+		t.Fatalf("got %d samples, want 2", len(samples))
+	}
+	if samples[0].Latency != 120*time.Millisecond || samples[1].Time.Unix() != 1700000010 {
+		t.Errorf("samples = %v, want them oldest first", samples)
+	}
+}
+
+func TestLatencyEventKind(t *testing.T) {
+	tests := map[string]string{
+		"command":              "command",
+		"fast-command":         "command",
+		"fork":                 "fork",
+		"expire-cycle":         "expire",
+		"eviction-cycle":       "eviction",
+		"eviction-del":         "eviction",
+		"aof-fsync-always":     "aof",
+		"rdb-unlink-temp-file": "other",
+	}
+	for event, want := range tests {
+		if got := models.LatencyEventKind(event); got != want {
+			t.Errorf("LatencyEventKind(%q) = %q, want %q", event, got, want)
+		}
+	}
+}
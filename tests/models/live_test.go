@@ -0,0 +1,43 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+	"time"
+)
+
+func TestLiveCountersRate(t *testing.T) {
+	c := models.NewLiveCounters()
+	start := time.Unix(1700000000, 0)
+
+	// Streaming for 10 seconds, then paused for 50
+	for sec := 0; sec < 10; sec++ {
+		at := start.Add(time.Duration(sec) * time.Second)
+		c.MarkActive(at)
+		for i := 0; i < 5; i++ {
+			c.Add("user", at)
+		}
+	}
+
+	now := start.Add(10 * time.Second)
+	if got := c.Rate("user", 10*time.Second, now); got != 5 {
+		t.Errorf("Rate(10s) = %v, want 5", got)
+	}
+
+	// Paused seconds are left out, so the minute rate matches the streamed part
+	later := start.Add(60 * time.Second)
+	if got := c.Rate("user", time.Minute, later); got != 5 {
+		t.Errorf("Rate(1m) after pause = %v, want 5", got)
+	}
+	if got := c.Rate("user", 10*time.Second, later); got != 0 {
+		t.Errorf("Rate(10s) after pause = %v, want 0", got)
+	}
+
+	// Slots older than the ring are reused
+	if got := c.Rate("user", 5*time.Minute, start.Add(10*time.Minute)); got != 0 {
+		t.Errorf("Rate(5m) after ring wrap = %v, want 0", got)
+	}
+	if got := c.Rate("order", 10*time.Second, now); got != 0 {
+		t.Errorf("Rate of unseen namespace = %v, want 0", got)
+	}
+}
@@ -0,0 +1,37 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+	"time"
+)
+
+func TestLoadThresholdsExceeded(t *testing.T) {
+	thresholds := models.LoadThresholds{Enabled: true, MaxRTT: 50 * time.Millisecond, MaxCPU: 0.8}
+
+	calm := models.LoadSample{RTT: 2 * time.Millisecond, CPU: 0.3, OpsPerSec: 1e6, BlockedClients: 40}
+	if reasons := thresholds.Exceeded(calm); len(reasons) != 0 {
+		t.Errorf("calm sample exceeded: %v", reasons)
+	}
+
+	busy := models.LoadSample{RTT: 80 * time.Millisecond, CPU: 0.95}
+	if reasons := thresholds.Exceeded(busy); len(reasons) != 2 {
+		t.Errorf("busy sample reasons = %v, want RTT and CPU", reasons)
+	}
+}
+
+func TestParseLatencyLatest(t *testing.T) {
+	reply := []interface{}{
+		[]interface{}{"command", int64(1700000000), int64(120), int64(250)},
+		[]interface{}{"fork", "bad", int64(3), int64(3)},
+		"junk",
+	}
+	events := models.ParseLatencyLatest(reply)
+	if len(events) != 1 {
+		t.Fatalf("events = %+v, want 1", events)
+	}
+	e := events[0]
+	if e.Name != "command" || e.Latency != 120*time.Millisecond || e.Max != 250*time.Millisecond || e.Time.Unix() != 1700000000 {
+		t.Errorf("event = %+v", e)
+	}
+}
@@ -0,0 +1,46 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+	"time"
+)
+
+func TestMetricHistoryRecord(t *testing.T) {
+	h := models.NewMetricHistory()
+	prefix := models.Key{"user"}
+	at := time.Unix(1700000000, 0)
+	metrics := models.NamespaceMetricList{
+		{Namespace: "session", EstKeys: 10, EstMemory: 100, Ops: map[models.OpType]float64{models.TotalOp: 1}},
+	}
+
+	h.Record(prefix, metrics, at)
+	// Unchanged metrics add no sample
+	h.Record(prefix, metrics, at.Add(time.Second))
+	metrics[0].EstKeys = 20
+	h.Record(prefix, metrics, at.Add(2*time.Second))
+
+	series := h.Series(prefix, "session")
+	if len(series) != 2 {
+		t.Fatalf("got %d samples, want 2", len(series))
+	}
+	if series[1].EstKeys != 20 || !series[1].Time.Equal(at.Add(2*time.Second)) {
+		t.Errorf("last sample = %+v", series[1])
+	}
+	if got := h.Series(models.Key{}, "session"); len(got) != 0 {
+		t.Errorf("top level session has %d samples, want 0", len(got))
+	}
+}
+
+func TestNamespaceMetricListRescaled(t *testing.T) {
+	info := &models.RedisInfo{Keyspace: map[string]models.KeyspaceInfo{models.DBName(0): {Keys: 2000}}}
+	list := models.NamespaceMetricList{{Namespace: "user", EstKeys: 500, KeyShare: 0.5, MemPerKey: 10}}
+
+	rescaled := list.Rescaled(info)
+	if rescaled[0].EstKeys != 1000 || rescaled[0].EstMemory != 10000 {
+		t.Errorf("rescaled = %d keys, %d bytes, want 1000 keys, 10000 bytes", rescaled[0].EstKeys, rescaled[0].EstMemory)
+	}
+	if list[0].EstKeys != 500 {
+		t.Errorf("original modified: %d keys", list[0].EstKeys)
+	}
+}
@@ -0,0 +1,124 @@
+package models_test
+
+import (
+	"redscout/models"
+	"strings"
+	"testing"
+)
+
+func TestParseMonitorLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		wantDB  int
+		wantAdr string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "simple command",
+			line:    `1339518083.107412 [0 127.0.0.1:60866] "get" "user:1"`,
+			wantDB:  0,
+			wantAdr: "127.0.0.1:60866",
+			want:    []string{"get", "user:1"},
+		},
+		{
+			name:   "non default db",
+			line:   `1339518083.107412 [3 127.0.0.1:60866] "set" "session:abc" "v"`,
+			wantDB: 3,
+			want:   []string{"set", "session:abc", "v"},
+		},
+		{
+			name:    "lua client",
+			line:    `1339518083.107412 [0 lua] "hget" "cart:1" "total"`,
+			wantDB:  0,
+			wantAdr: "lua",
+			want:    []string{"hget", "cart:1", "total"},
+		},
+		{
+			name:   "escaped quotes and bytes",
+			line:   `1339518083.107412 [0 127.0.0.1:60866] "set" "a\"b" "\x00\n"`,
+			wantDB: 0,
+			want:   []string{"set", `a"b`, "\x00\n"},
+		},
+		{
+			name:    "missing client info",
+			line:    `1339518083.107412 "get" "user:1"`,
+			wantErr: true,
+		},
+		{
+			name:    "OK reply",
+			line:    `OK`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := models.ParseMonitorLine(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMonitorLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.DB != tt.wantDB {
+				t.Errorf("ParseMonitorLine() DB = %v, want %v", got.DB, tt.wantDB)
+			}
+			if tt.wantAdr != "" && got.Addr != tt.wantAdr {
+				t.Errorf("ParseMonitorLine() Addr = %q, want %q", got.Addr, tt.wantAdr)
+			}
+			if got.Time.Unix() != 1339518083 {
+				t.Errorf("ParseMonitorLine() Time = %v, want unix 1339518083", got.Time)
+			}
+			if len(got.Args) != len(tt.want) {
+				t.Fatalf("ParseMonitorLine() Args = %q, want %q", got.Args, tt.want)
+			}
+			for i := range got.Args {
+				if got.Args[i] != tt.want[i] {
+					t.Errorf("ParseMonitorLine() Args[%d] = %q, want %q", i, got.Args[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestScanRecordRoundTrip(t *testing.T) {
+	records := []models.ScanRecord{
+		{Key: "user:1", Memory: 72, TTL: -1, Type: "string", Encoding: "embstr", Idle: 30, Freq: -1, DB: 0, Time: 1700000000},
+		{Key: "key with spaces", Memory: 1024, TTL: 60, Type: "hash", Encoding: "listpack", Elements: 12, Idle: -1, Freq: 4, DB: 5},
+	}
+
+	for _, want := range records {
+		got, err := models.ParseScanRecord(want.String())
+		if err != nil {
+			t.Fatalf("ParseScanRecord(%q) error = %v", want.String(), err)
+		}
+		if got != want {
+			t.Errorf("ParseScanRecord(%q) = %+v, want %+v", want.String(), got, want)
+		}
+	}
+}
+
+func TestMonitorRecordRoundTrip(t *testing.T) {
+	records := []models.MonitorRecord{
+		{Key: "user:1", Command: "get", DB: 0},
+		{Key: "key with spaces", Command: "expired", DB: 3, Time: 1700000000123},
+		// MONITOR shows these escaped, ParseMonitorLine decodes them
+		{Key: "line\nbreak\r", Command: "set", DB: 0},
+		{Key: `back\slash\n`, Command: "set", DB: 0},
+	}
+
+	for _, want := range records {
+		if strings.ContainsAny(want.String(), "\r\n") {
+			t.Errorf("record %q spans lines", want.String())
+		}
+		got, err := models.ParseMonitorRecord(want.String())
+		if err != nil {
+			t.Fatalf("ParseMonitorRecord(%q) error = %v", want.String(), err)
+		}
+		if got != want {
+			t.Errorf("ParseMonitorRecord(%q) = %+v, want %+v", want.String(), got, want)
+		}
+	}
+}
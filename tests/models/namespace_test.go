@@ -0,0 +1,180 @@
+package models_test
+
+import (
+	"redscout/models"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFlagEvictionPressure(t *testing.T) {
+	list := models.NamespaceMetricList{
+		// No TTLs, written to and barely evicted itself
+		{Namespace: "cache", EstKeys: 1000, Ops: map[models.OpType]float64{models.SetOp: 50, models.EvictedOp: 1}},
+		// Keys with TTLs being evicted
+		{Namespace: "session", EstKeys: 500, TTLPercent: 0.9, Ops: map[models.OpType]float64{models.SetOp: 5, models.EvictedOp: 20}},
+		// No TTLs but not written to
+		{Namespace: "config", EstKeys: 10, Ops: map[models.OpType]float64{}},
+	}
+
+	list.FlagEvictionPressure()
+
+	want := map[string]bool{"cache": true, "session": false, "config": false}
+	for _, m := range list {
+		if m.EvictionPressure != want[m.Namespace] {
+			t.Errorf("%s: EvictionPressure = %v, want %v", m.Namespace, m.EvictionPressure, want[m.Namespace])
+		}
+	}
+}
+
+func TestGetOpTypeKeyspaceEvents(t *testing.T) {
+	tests := map[string]models.OpType{
+		"expired":     models.ExpiredOp,
+		"evicted":     models.EvictedOp,
+		"rename_from": models.DelOp,
+		"zincr":       models.SetOp,
+	}
+	for event, want := range tests {
+		if got := models.GetOpType(event); got != want {
+			t.Errorf("GetOpType(%q) = %q, want %q", event, got, want)
+		}
+	}
+}
+
+func TestOpsAreCounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		untimed  bool
+		duration time.Duration
+		want     bool
+	}{
+		{"no ops yet", false, 0, false},
+		{"monitored", false, 10 * time.Second, false},
+		{"AOF without timestamps", true, 0, true},
+		{"AOF without timestamps and a capture", true, time.Minute, false},
+	}
+	for _, tt := range tests {
+		s := models.NewState()
+		s.UntimedOps, s.TotalMonitorDuration = tt.untimed, tt.duration
+		if got := s.OpsAreCounts(); got != tt.want {
+			t.Errorf("%s: OpsAreCounts() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// accessState is a state whose db0 holds twice the keys scanned, so estimates double.
+func accessState(scanned int64) *models.State {
+	s := models.NewState()
+	s.ScannedKeysByDB[0] = scanned
+	s.RedisInfo = &models.RedisInfo{Keyspace: map[string]models.KeyspaceInfo{"db0": {Keys: 2 * scanned}}}
+	return s
+}
+
+func TestColdMemoryUnderLRU(t *testing.T) {
+	snapshot := models.NewNamespaceSnapshot("session", 0)
+	records := []models.ScanRecord{
+		{Memory: 100, Idle: 30, Freq: -1},
+		{Memory: 200, Idle: 2 * 86400, Freq: -1},
+		// Exactly at the cold threshold counts as cold
+		{Memory: 300, Idle: 7 * 86400, Freq: -1},
+		{Memory: 400, Idle: 90 * 86400, Freq: -1},
+	}
+	for _, r := range records {
+		snapshot.Keys++
+		snapshot.TotalMemory += r.Memory
+		snapshot.AddAccessSample(r, 7*24*time.Hour)
+	}
+	if snapshot.AccessSampled != 4 || snapshot.ColdKeys != 2 || snapshot.ColdMemory != 700 {
+		t.Fatalf("sampled %d, cold %d keys of %d bytes, want 4, 2 and 700",
+			snapshot.AccessSampled, snapshot.ColdKeys, snapshot.ColdMemory)
+	}
+	if snapshot.FreqSamples != 0 {
+		t.Errorf("FreqSamples = %d under LRU, want 0", snapshot.FreqSamples)
+	}
+
+	// Buckets bounded above by 1m, 10m, 1h, 6h, 1d, 7d, 30d, then unbounded
+	wantMemory := []int64{100, 0, 0, 0, 0, 500, 0, 400}
+	for i, want := range wantMemory {
+		if got := snapshot.IdleHistogram.Memory[i]; got != want {
+			t.Errorf("idle bucket %d memory = %d, want %d", i, got, want)
+		}
+	}
+
+	m := snapshot.ToMetric(accessState(4))
+	if m.EstColdMemory != 1400 || m.ColdPercent != 0.7 {
+		t.Errorf("estimated cold = %d bytes, %.2f, want 1400 and 0.70", m.EstColdMemory, m.ColdPercent)
+	}
+	if m.IdleHistogram.Memory[5] != 1000 || m.IdleHistogram.Total() != 8 {
+		t.Errorf("scaled idle histogram = %v memory, %d keys, want 1000 in bucket 5 and 8 keys",
+			m.IdleHistogram.Memory, m.IdleHistogram.Total())
+	}
+}
+
+func TestColdMemoryUnderLFU(t *testing.T) {
+	snapshot := models.NewNamespaceSnapshot("session", 0)
+	records := []models.ScanRecord{
+		// A counter decayed to zero is cold, however recently the key was touched
+		{Memory: 100, Idle: -1, Freq: 0},
+		{Memory: 200, Idle: -1, Freq: 3},
+		{Memory: 300, Idle: -1, Freq: 9},
+		// Not sampled at all, as on a server refusing OBJECT
+		{Memory: 400, Idle: -1, Freq: -1},
+	}
+	for _, r := range records {
+		snapshot.Keys++
+		snapshot.TotalMemory += r.Memory
+		snapshot.AddAccessSample(r, 7*24*time.Hour)
+	}
+	if snapshot.AccessSampled != 3 || snapshot.AccessMemory != 600 || snapshot.ColdKeys != 1 || snapshot.ColdMemory != 100 {
+		t.Fatalf("sampled %d keys of %d bytes, cold %d keys of %d bytes, want 3, 600, 1 and 100",
+			snapshot.AccessSampled, snapshot.AccessMemory, snapshot.ColdKeys, snapshot.ColdMemory)
+	}
+	if snapshot.IdleHistogram.Total() != 0 {
+		t.Errorf("idle histogram holds %d keys under LFU, want 0", snapshot.IdleHistogram.Total())
+	}
+
+	m := snapshot.ToMetric(accessState(4))
+	if m.AvgFreq != 4 {
+		t.Errorf("AvgFreq = %v, want 4", m.AvgFreq)
+	}
+	// The unsampled key is left out of the cold percent
+	if m.EstColdMemory != 200 || m.ColdPercent != 100.0/600 {
+		t.Errorf("estimated cold = %d bytes, %.3f, want 200 and 0.167", m.EstColdMemory, m.ColdPercent)
+	}
+}
+
+func TestNamespaceElementsAcrossTypes(t *testing.T) {
+	mixed := models.NewNamespaceSnapshot("user", 0)
+	for _, r := range []models.ScanRecord{
+		{Type: "string", Elements: 10, Memory: 50},
+		{Type: "hash", Elements: 4, Memory: 100},
+		{Type: "list", Elements: 100, Memory: 800},
+		{Type: "hash", Elements: 6, Memory: 120},
+		// No cheap element count for module types
+		{Type: "ReJSON-RL", Elements: 0, Memory: 300},
+	} {
+		mixed.AddKey(r)
+	}
+	// Seen in monitored ops only, without scanned keys
+	opsOnly := models.NewNamespaceSnapshot("cart", 0)
+	opsOnly.OpsFrequency["get"] = 3
+
+	s := models.NewState()
+	s.ScannedKeysByDB[0] = 5
+	s.RedisInfo = &models.RedisInfo{Keyspace: map[string]models.KeyspaceInfo{"db0": {Keys: 50}}}
+
+	m := mixed.ToMetric(s)
+	if m.AvgElems != 24 || m.MaxElems != 100 {
+		t.Errorf("mixed: avg %v, max %d elements, want 24 and 100", m.AvgElems, m.MaxElems)
+	}
+	if want := []string{"string", "hash", "list", "ReJSON-RL"}; !slices.Equal(m.Types, want) {
+		t.Errorf("mixed: types = %v, want %v", m.Types, want)
+	}
+
+	for _, empty := range []*models.NamespaceSnapshot{models.NewNamespaceSnapshot("empty", 0), opsOnly} {
+		m := empty.ToMetric(s)
+		if m.AvgElems != 0 || m.MaxElems != 0 || m.EstKeys != 0 {
+			t.Errorf("%s: avg %v, max %d elements, %d keys, want all 0", empty.Namespace, m.AvgElems, m.MaxElems, m.EstKeys)
+		}
+	}
+}
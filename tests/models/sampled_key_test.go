@@ -0,0 +1,61 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+)
+
+func TestSampledKeyListFilter(t *testing.T) {
+	keys := models.SampledKeyList{
+		{Name: "user:1:Profile", Memory: 10, Type: "hash"},
+		{Name: "user:2:cart", Memory: 30, Type: "list"},
+		{Name: "user:3:profile", Memory: 20, Type: "hash"},
+	}
+
+	profiles := keys.Filter("PROFILE")
+	if len(profiles) != 2 {
+		t.Fatalf("got %d keys, want 2", len(profiles))
+	}
+	profiles.Sort("Memory")
+	if profiles[0].Name != "user:3:profile" {
+		t.Errorf("largest = %s, want user:3:profile", profiles[0].Name)
+	}
+	if got := keys.Filter("list"); len(got) != 1 || got[0].Name != "user:2:cart" {
+		t.Errorf("type filter = %+v", got)
+	}
+}
+
+func TestSampledKeyTopKeepsLatestSample(t *testing.T) {
+	top := models.NewSampledKeyTop(2)
+	// Scanned in two passes, growing in between
+	top.Add(models.SampledKey{Name: "user:1", Memory: 10})
+	top.Add(models.SampledKey{Name: "user:2", Memory: 20})
+	top.Add(models.SampledKey{Name: "user:1", DB: 1, Memory: 5})
+	top.Add(models.SampledKey{Name: "user:1", Memory: 50})
+	top.Add(models.SampledKey{Name: "user:2", Memory: 15})
+
+	keys := top.List()
+	if len(keys) != 2 {
+		t.Fatalf("got %d keys, want 2: %+v", len(keys), keys)
+	}
+	if keys[0].Name != "user:1" || keys[0].DB != 0 || keys[0].Memory != 50 {
+		t.Errorf("largest = %+v, want user:1 of db0 at its latest 50", keys[0])
+	}
+	if keys[1].Name != "user:2" || keys[1].Memory != 15 {
+		t.Errorf("second = %+v, want user:2 at its latest 15", keys[1])
+	}
+}
+
+func TestSampledKeyListFilterIsExactForTypes(t *testing.T) {
+	keys := models.SampledKeyList{
+		{Name: "a", Type: "hash", Encoding: "listpack"},
+		{Name: "b", Type: "zset", Encoding: "skiplist"},
+	}
+	// Type and encoding match whole, the name by substring
+	if got := keys.Filter("list"); len(got) != 0 {
+		t.Errorf("Filter(list) = %+v, want none", got)
+	}
+	if got := keys.Filter("LISTPACK"); len(got) != 1 || got[0].Name != "a" {
+		t.Errorf("Filter(LISTPACK) = %+v, want a", got)
+	}
+}
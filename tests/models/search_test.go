@@ -0,0 +1,29 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+)
+
+func TestCompileSearch(t *testing.T) {
+	tests := []struct {
+		search, text string
+		want         bool
+	}{
+		{"SESSION", "user:session", true},
+		{"^user:\\d+$", "user:42", true},
+		{"^user:\\d+$", "user:42:cart", false},
+		// Not a valid regexp, matched as a substring
+		{"cart[", "user:cart[1]", true},
+	}
+	for _, tt := range tests {
+		if got := models.CompileSearch(tt.search).MatchString(tt.text); got != tt.want {
+			t.Errorf("search %q on %q = %v, want %v", tt.search, tt.text, got, tt.want)
+		}
+	}
+
+	stats := models.NamespaceMetricList{{Namespace: "user"}, {Namespace: "session"}}
+	if got := stats.Search(models.CompileSearch("sess")); len(got) != 1 || got[0].Namespace != "session" {
+		t.Errorf("namespace search = %+v", got)
+	}
+}
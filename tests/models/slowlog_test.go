@@ -0,0 +1,139 @@
+package models_test
+
+import (
+	"redscout/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSlowLogKey(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+		ok   bool
+	}{
+		{[]string{"GET", "user:1"}, "user:1", true},
+		{[]string{"hgetall", "session:abc"}, "session:abc", true},
+		{[]string{"EVALSHA", "abc123", "2", "lock:1", "lock:2", "arg"}, "lock:1", true},
+		{[]string{"EVAL", "return 1", "0"}, "", false},
+		{[]string{"OBJECT", "ENCODING", "user:1"}, "user:1", true},
+		{[]string{"MEMORY", "USAGE", "user:1"}, "user:1", true},
+		{[]string{"MEMORY", "STATS"}, "", false},
+		{[]string{"KEYS", "*"}, "", false},
+		{[]string{"PING"}, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := models.SlowLogKey(tt.args)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("SlowLogKey(%q) = %q, %v, want %q, %v", tt.args, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGroupSlowLog(t *testing.T) {
+	kp := models.NewKeyParser(":", []*regexp.Regexp{regexp.MustCompile(`^\d+$`)})
+	var logs []redis.SlowLog
+	for i := 1; i <= 20; i++ {
+		logs = append(logs, redis.SlowLog{
+			ID:         int64(i),
+			Duration:   time.Duration(i) * time.Millisecond,
+			Args:       []string{"hgetall", "user:" + string(rune('0'+i%10))},
+			ClientAddr: "10.0.0.1:5000",
+		})
+	}
+	logs = append(logs, redis.SlowLog{ID: 21, Duration: time.Millisecond, Args: []string{"keys", "*"}, ClientName: "worker"})
+
+	list := models.NewSlowLogList(logs, kp)
+	if list[0].Namespace != "user" || list[20].Namespace != "" {
+		t.Fatalf("namespaces = %q, %q, want user and none", list[0].Namespace, list[20].Namespace)
+	}
+
+	// Keys without a delimiter share a bucket instead of each being a namespace
+	rootKeys := models.NewSlowLogList([]redis.SlowLog{
+		{ID: 1, Args: []string{"get", "counter"}},
+		{ID: 2, Args: []string{"incr", "visits"}},
+	}, kp)
+	for _, e := range rootKeys {
+		if e.Namespace != models.NoNamespace {
+			t.Errorf("namespace of %q = %q, want %q", e.Args[1], e.Namespace, models.NoNamespace)
+		}
+	}
+	if list[20].Client() != "worker" {
+		t.Errorf("Client() = %q, want the client name", list[20].Client())
+	}
+
+	groups := models.GroupSlowLog(list)
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+	g := groups[0]
+	if g.Command != "HGETALL" || g.Namespace != "user" || g.Count != 20 {
+		t.Errorf("first group = %s %s x%d, want HGETALL user x20", g.Command, g.Namespace, g.Count)
+	}
+	if g.Total != 210*time.Millisecond || g.P95 != 19*time.Millisecond || g.Max != 20*time.Millisecond {
+		t.Errorf("durations = %v total, %v p95, %v max", g.Total, g.P95, g.Max)
+	}
+	if len(g.Clients) != 1 || g.Clients[0] != "10.0.0.1:5000" {
+		t.Errorf("clients = %v", g.Clients)
+	}
+}
+
+func slowLogIDs(ids ...int64) models.SlowLogList {
+	list := models.SlowLogList{}
+	for _, id := range ids {
+		list = append(list, models.SlowLogEntry{SlowLog: redis.SlowLog{ID: id, Args: []string{"get", "k"}}})
+	}
+	return list
+}
+
+func TestSlowLogPollsMerge(t *testing.T) {
+	polls := models.SlowLogPolls{LastID: -1}
+	now := time.Now()
+
+	history, added := polls.Merge(nil, slowLogIDs(3, 4, 5), 3, now, 5)
+	if len(history) != 3 || len(added) != 3 || history[0].ID != 5 || polls.Dropped != 0 {
+		t.Fatalf("first poll kept %d, added %d, newest %d, dropped %d", len(history), len(added), history[0].ID, polls.Dropped)
+	}
+
+	// 6 and 7 rotated out before this poll
+	history, added = polls.Merge(history, slowLogIDs(8, 9, 5, 4), 4, now, 5)
+	if len(added) != 2 || polls.Dropped != 2 || polls.LastID != 9 {
+		t.Errorf("second poll added %d, dropped %d, last ID %d, want 2, 2, 9", len(added), polls.Dropped, polls.LastID)
+	}
+	if len(history) != 5 || history[0].ID != 9 || history[4].ID != 3 {
+		t.Errorf("history = %v, want the newest 5 entries newest first", history)
+	}
+	if n := history.NewSince(5); n != 2 {
+		t.Errorf("NewSince(5) = %d, want 2", n)
+	}
+
+	// A restarted server numbers its entries from 0 again
+	_, added = polls.Merge(history, slowLogIDs(0, 1), 2, now, 5)
+	if len(added) != 2 || polls.LastID != 1 || polls.Dropped != 2 {
+		t.Errorf("after restart added %d, last ID %d, dropped %d, want 2, 1, 2", len(added), polls.LastID, polls.Dropped)
+	}
+}
+
+func TestSlowLogHistoryMerge(t *testing.T) {
+	history := models.NewSlowLogHistory()
+	if history.LastID() != -1 {
+		t.Fatalf("LastID() = %d before any poll, want -1", history.LastID())
+	}
+
+	history.Merge(slowLogIDs(1, 2), 2, time.Now(), 3)
+	before := history.Entries()
+	added := history.Merge(slowLogIDs(3, 4, 2), 3, time.Now(), 3)
+
+	if len(added) != 2 || history.LastID() != 4 || history.Polls().Polls != 2 {
+		t.Errorf("second poll added %d, last ID %d, polls %d, want 2, 4, 2", len(added), history.LastID(), history.Polls().Polls)
+	}
+	if entries := history.Entries(); len(entries) != 3 || entries[0].ID != 4 {
+		t.Errorf("entries = %v, want the newest 3 newest first", entries)
+	}
+	if len(before) != 2 || before[0].ID != 2 {
+		t.Errorf("earlier entries changed to %v by a later merge", before)
+	}
+}
@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"redscout/models"
+	"reflect"
+	"testing"
+)
+
+func TestMergeHotKeys(t *testing.T) {
+	ops := models.HotKeyList{
+		{Key: models.Key{"user", "1"}, Ops: 12, Sources: []string{models.HotKeySourceMonitor}},
+	}
+	lfu := models.HotKeyList{
+		{Key: models.Key{"user", "2"}, Freq: 200, Sources: []string{models.HotKeySourceLFU}},
+		{Key: models.Key{"user", "1"}, Freq: 180, Sources: []string{models.HotKeySourceLFU}},
+	}
+
+	merged := models.MergeHotKeys(10, ops, lfu)
+
+	want := models.HotKeyList{
+		{Key: models.Key{"user", "1"}, Ops: 12, Freq: 180, Sources: []string{models.HotKeySourceMonitor, models.HotKeySourceLFU}},
+		{Key: models.Key{"user", "2"}, Freq: 200, Sources: []string{models.HotKeySourceLFU}},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("MergeHotKeys() = %+v, want %+v", merged, want)
+	}
+
+	// Only the hottest keys are kept past the limit
+	lfu = append(lfu, models.HotKey{Key: models.Key{"user", "3"}, Freq: 100, Sources: []string{models.HotKeySourceLFU}})
+	if merged := models.MergeHotKeys(2, ops, lfu); len(merged) != 2 || merged[1].Key.String() != "user:2" {
+		t.Errorf("MergeHotKeys(2) = %+v, want user:1 and user:2", merged)
+	}
+}
+
+func TestDecayLFU(t *testing.T) {
+	tests := []struct {
+		freq, elapsed, decay, want int64
+	}{
+		{freq: 10, elapsed: 0, decay: 1, want: 10},
+		{freq: 10, elapsed: 180, decay: 1, want: 7},
+		{freq: 10, elapsed: 180, decay: 2, want: 9},
+		{freq: 2, elapsed: 3600, decay: 1, want: 0},
+		{freq: 10, elapsed: 3600, decay: 0, want: 10},
+	}
+	for _, tt := range tests {
+		if got := models.DecayLFU(tt.freq, tt.elapsed, tt.decay); got != tt.want {
+			t.Errorf("DecayLFU(%d, %d, %d) = %d, want %d", tt.freq, tt.elapsed, tt.decay, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package models_test
+
+import (
+	"redscout/models"
+	"testing"
+	"time"
+)
+
+func TestTimeSpansUnion(t *testing.T) {
+	at := func(min int) time.Time { return time.Unix(1700000000, 0).Add(time.Duration(min) * time.Minute) }
+
+	tests := []struct {
+		name  string
+		spans [][2]int
+		want  time.Duration
+		count int
+	}{
+		{"single", [][2]int{{0, 10}}, 10 * time.Minute, 1},
+		{"disjoint", [][2]int{{20, 30}, {0, 10}}, 20 * time.Minute, 2},
+		{"overlapping AOF and capture", [][2]int{{0, 10}, {5, 15}}, 15 * time.Minute, 1},
+		{"same period twice", [][2]int{{0, 10}, {0, 10}}, 10 * time.Minute, 1},
+		{"contained", [][2]int{{0, 30}, {10, 20}}, 30 * time.Minute, 1},
+		{"bridging", [][2]int{{0, 10}, {20, 30}, {5, 25}}, 30 * time.Minute, 1},
+	}
+	for _, tt := range tests {
+		var spans models.TimeSpans
+		for _, span := range tt.spans {
+			spans = spans.Add(at(span[0]), at(span[1]))
+		}
+		if got := spans.Duration(); got != tt.want {
+			t.Errorf("%s: Duration() = %v, want %v", tt.name, got, tt.want)
+		}
+		if len(spans) != tt.count {
+			t.Errorf("%s: %d spans, want %d", tt.name, len(spans), tt.count)
+		}
+	}
+}
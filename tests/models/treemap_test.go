@@ -0,0 +1,29 @@
+package models_test
+
+import (
+	"math"
+	"redscout/models"
+	"testing"
+)
+
+func TestSquarify(t *testing.T) {
+	values := []float64{1, 6, 0, 3, 2, 4, 2, 6}
+	bounds := models.Rect{W: 6, H: 4}
+	rects := models.Squarify(values, bounds)
+
+	if len(rects) != len(values) {
+		t.Fatalf("got %d rects, want %d", len(rects), len(values))
+	}
+	for i, r := range rects {
+		// The values add up to the area of the bounds, so each area equals its value
+		if area := r.W * r.H; math.Abs(area-values[i]) > 1e-9 {
+			t.Errorf("rect %d has area %v, want %v", i, area, values[i])
+		}
+		if values[i] == 0 {
+			continue
+		}
+		if r.X < 0 || r.Y < 0 || r.X+r.W > bounds.W+1e-9 || r.Y+r.H > bounds.H+1e-9 {
+			t.Errorf("rect %d = %+v is outside %+v", i, r, bounds)
+		}
+	}
+}
@@ -0,0 +1,436 @@
+package rdb_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"redscout/lib/rdb"
+	"testing"
+	"time"
+)
+
+// rdbWriter generates RDB fixtures in memory
+type rdbWriter struct {
+	bytes.Buffer
+}
+
+func newRDB(version int) *rdbWriter {
+	w := &rdbWriter{}
+	w.WriteString(fmt.Sprintf("REDIS%04d", version))
+	return w
+}
+
+func (w *rdbWriter) length(n uint64) {
+	switch {
+	case n < 1<<6:
+		w.WriteByte(byte(n))
+	case n < 1<<14:
+		w.WriteByte(byte(n>>8) | 0x40)
+		w.WriteByte(byte(n))
+	default:
+		w.WriteByte(0x80)
+		_ = binary.Write(w, binary.BigEndian, uint32(n))
+	}
+}
+
+func (w *rdbWriter) str(s string) {
+	w.length(uint64(len(s)))
+	w.WriteString(s)
+}
+
+func (w *rdbWriter) aux(key, val string) {
+	w.WriteByte(250)
+	w.str(key)
+	w.str(val)
+}
+
+func (w *rdbWriter) selectDB(db int) {
+	w.WriteByte(254)
+	w.length(uint64(db))
+}
+
+func (w *rdbWriter) expireMS(t time.Time) {
+	w.WriteByte(252)
+	_ = binary.Write(w, binary.LittleEndian, uint64(t.UnixMilli()))
+}
+
+func (w *rdbWriter) end() []byte {
+	w.WriteByte(255)
+	w.Write(make([]byte, 8))
+	return w.Bytes()
+}
+
+// listpack encodes string entries as a listpack
+func listpack(entries ...string) []byte {
+	var body bytes.Buffer
+	for _, e := range entries {
+		if len(e) >= 64 {
+			panic("fixture entries must be short")
+		}
+		body.WriteByte(0x80 | byte(len(e)))
+		body.WriteString(e)
+		body.WriteByte(byte(1 + len(e)))
+	}
+	body.WriteByte(0xff)
+
+	lp := make([]byte, 6, 6+body.Len())
+	binary.LittleEndian.PutUint32(lp[0:4], uint32(6+body.Len()))
+	binary.LittleEndian.PutUint16(lp[4:6], uint16(len(entries)))
+	return append(lp, body.Bytes()...)
+}
+
+// rawListpack encodes entries of the given sizes as 32-bit strings, with the back length sizes
+// of lpEncodeBacklen in Redis and a saturated header count so that parsing walks it.
+func rawListpack(sizes ...int) []byte {
+	lp := make([]byte, 6)
+	for _, size := range sizes {
+		if size < 5 {
+			panic("32-bit string entries take at least 5 bytes")
+		}
+		lp = append(lp, 0xf0)
+		lp = binary.LittleEndian.AppendUint32(lp, uint32(size-5))
+		lp = append(lp, bytes.Repeat([]byte{'x'}, size-5)...)
+
+		backlen := 5
+		switch {
+		case size <= 127:
+			backlen = 1
+		case size < 16383:
+			backlen = 2
+		case size < 2097151:
+			backlen = 3
+		case size < 268435455:
+			backlen = 4
+		}
+		lp = append(lp, make([]byte, backlen)...)
+	}
+	lp = append(lp, 0xff)
+	binary.LittleEndian.PutUint32(lp[0:4], uint32(len(lp)))
+	binary.LittleEndian.PutUint16(lp[4:6], 0xffff)
+	return lp
+}
+
+// lzf writes data as an LZF compressed string made of literal runs only
+func (w *rdbWriter) lzf(data []byte) {
+	rawLen := len(data)
+	var compressed []byte
+	for len(data) > 0 {
+		n := min(len(data), 32)
+		compressed = append(compressed, byte(n-1))
+		compressed = append(compressed, data[:n]...)
+		data = data[n:]
+	}
+	w.WriteByte(0xc3)
+	w.length(uint64(len(compressed)))
+	w.length(uint64(rawLen))
+	w.Write(compressed)
+}
+
+// ziplist encodes string entries as a ziplist
+func ziplist(entries ...string) []byte {
+	var body bytes.Buffer
+	prev := 0
+	for _, e := range entries {
+		body.WriteByte(byte(prev))
+		body.WriteByte(byte(len(e)))
+		body.WriteString(e)
+		prev = 2 + len(e)
+	}
+	body.WriteByte(0xff)
+
+	zl := make([]byte, 10, 10+body.Len())
+	binary.LittleEndian.PutUint32(zl[0:4], uint32(10+body.Len()))
+	binary.LittleEndian.PutUint16(zl[8:10], uint16(len(entries)))
+	return append(zl, body.Bytes()...)
+}
+
+func intset(values ...int16) []byte {
+	is := make([]byte, 8)
+	binary.LittleEndian.PutUint32(is[0:4], 2)
+	binary.LittleEndian.PutUint32(is[4:8], uint32(len(values)))
+	for _, v := range values {
+		is = binary.LittleEndian.AppendUint16(is, uint16(v))
+	}
+	return is
+}
+
+func parseAll(t *testing.T, data []byte) (*rdb.Parser, map[string]rdb.Entry) {
+	t.Helper()
+	p := rdb.NewParser(bytes.NewReader(data))
+	entries := make(map[string]rdb.Entry)
+	if err := p.Parse(func(e rdb.Entry) error {
+		entries[e.Key] = e
+		return nil
+	}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return p, entries
+}
+
+func TestParseModernTypes(t *testing.T) {
+	expireAt := time.UnixMilli(1700000100000)
+
+	w := newRDB(11)
+	w.aux("redis-ver", "7.2.4")
+	w.aux("ctime", "1700000000")
+	w.selectDB(0)
+	w.WriteByte(251)
+	w.length(8)
+	w.length(1)
+
+	// Plain, integer and LZF compressed strings
+	w.WriteByte(0)
+	w.str("str:plain")
+	w.str("hello")
+
+	w.expireMS(expireAt)
+	w.WriteByte(0)
+	w.str("str:int")
+	w.Write([]byte{0xc1, 0x39, 0x30})
+
+	w.WriteByte(0)
+	w.str("str:lzf")
+	w.Write([]byte{0xc3, 5, 10, 0x00, 'a', 0xe0, 0x00, 0x00})
+
+	w.WriteByte(248)
+	w.length(3600)
+	w.WriteByte(16)
+	w.str("hash:lp")
+	w.str(string(listpack("f1", "v1", "f2", "v2")))
+
+	w.WriteByte(20)
+	w.str("set:lp")
+	w.str(string(listpack("a", "b", "c")))
+
+	w.WriteByte(11)
+	w.str("set:int")
+	w.str(string(intset(1, 2, 3, 4)))
+
+	w.WriteByte(17)
+	w.str("zset:lp")
+	w.str(string(listpack("m1", "1", "m2", "2")))
+
+	// Quicklist with two packed nodes and one plain node
+	w.WriteByte(18)
+	w.str("list:ql")
+	w.length(3)
+	w.length(2)
+	w.str(string(listpack("a", "b")))
+	w.length(1)
+	w.str("big element")
+	w.length(2)
+	w.str(string(listpack("c")))
+
+	w.selectDB(3)
+	w.WriteByte(249)
+	w.WriteByte(7)
+	w.WriteByte(4)
+	w.str("hash:ht")
+	w.length(2)
+	w.str("f1")
+	w.str("v1")
+	w.str("f2")
+	w.str("v2")
+
+	w.WriteByte(5)
+	w.str("zset:sl")
+	w.length(1)
+	w.str("member")
+	_ = binary.Write(w, binary.LittleEndian, math.Float64bits(1.5))
+
+	p, entries := parseAll(t, w.end())
+
+	if p.Version != 11 {
+		t.Errorf("Version = %d, want 11", p.Version)
+	}
+	if got := p.CreatedAt().Unix(); got != 1700000000 {
+		t.Errorf("CreatedAt() = %d, want 1700000000", got)
+	}
+
+	tests := []struct {
+		key      string
+		db       int
+		keyType  string
+		encoding string
+		elements int64
+	}{
+		{"str:plain", 0, "string", "embstr", 5},
+		{"str:int", 0, "string", "int", 5},
+		{"str:lzf", 0, "string", "embstr", 10},
+		{"hash:lp", 0, "hash", "listpack", 2},
+		{"set:lp", 0, "set", "listpack", 3},
+		{"set:int", 0, "set", "intset", 4},
+		{"zset:lp", 0, "zset", "listpack", 2},
+		{"list:ql", 0, "list", "quicklist", 4},
+		{"hash:ht", 3, "hash", "hashtable", 2},
+		{"zset:sl", 3, "zset", "skiplist", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			e, ok := entries[tt.key]
+			if !ok {
+				t.Fatalf("key %q not parsed", tt.key)
+			}
+			if e.DB != tt.db || e.Type != tt.keyType || e.Encoding != tt.encoding || e.Elements != tt.elements {
+				t.Errorf("entry = db %d %s/%s %d elements, want db %d %s/%s %d elements",
+					e.DB, e.Type, e.Encoding, e.Elements, tt.db, tt.keyType, tt.encoding, tt.elements)
+			}
+			if e.Memory <= 0 {
+				t.Errorf("Memory = %d, want positive estimate", e.Memory)
+			}
+		})
+	}
+
+	if !entries["str:int"].ExpireAt.Equal(expireAt) {
+		t.Errorf("ExpireAt = %v, want %v", entries["str:int"].ExpireAt, expireAt)
+	}
+	if !entries["str:plain"].ExpireAt.IsZero() {
+		t.Errorf("ExpireAt = %v, want zero", entries["str:plain"].ExpireAt)
+	}
+	if e := entries["hash:lp"]; e.Idle != 3600 || e.Freq != -1 {
+		t.Errorf("hash:lp idle/freq = %d/%d, want 3600/-1", e.Idle, e.Freq)
+	}
+	if e := entries["hash:ht"]; e.Freq != 7 || e.Idle != -1 {
+		t.Errorf("hash:ht idle/freq = %d/%d, want -1/7", e.Idle, e.Freq)
+	}
+	if e := entries["set:lp"]; e.Idle != -1 {
+		t.Errorf("set:lp idle = %d, want -1 as access info applies to one key only", e.Idle)
+	}
+}
+
+func TestParseLegacyEncodings(t *testing.T) {
+	w := newRDB(7)
+	w.aux("redis-ver", "3.2.12")
+
+	w.WriteByte(13)
+	w.str("hash:zl")
+	w.str(string(ziplist("f1", "v1", "f2", "v2", "f3", "v3")))
+
+	w.WriteByte(14)
+	w.str("list:ql")
+	w.length(2)
+	w.str(string(ziplist("a", "b", "c")))
+	w.str(string(ziplist("d")))
+
+	// Saturated listpack header count forces a walk
+	lp := listpack("x", "y")
+	binary.LittleEndian.PutUint16(lp[4:6], 0xffff)
+	w.WriteByte(20)
+	w.str("set:walk")
+	w.str(string(lp))
+
+	w.WriteByte(3)
+	w.str("zset:old")
+	w.length(2)
+	w.str("a")
+	w.WriteByte(3)
+	w.WriteString("1.5")
+	w.str("b")
+	w.WriteByte(254)
+
+	_, entries := parseAll(t, w.end())
+
+	tests := []struct {
+		key      string
+		encoding string
+		elements int64
+	}{
+		{"hash:zl", "ziplist", 3},
+		{"list:ql", "quicklist", 4},
+		{"set:walk", "listpack", 2},
+		{"zset:old", "skiplist", 2},
+	}
+	for _, tt := range tests {
+		e, ok := entries[tt.key]
+		if !ok {
+			t.Fatalf("key %q not parsed", tt.key)
+		}
+		if e.Encoding != tt.encoding || e.Elements != tt.elements {
+			t.Errorf("%s = %s %d elements, want %s %d elements", tt.key, e.Encoding, e.Elements, tt.encoding, tt.elements)
+		}
+	}
+}
+
+func TestParseListpackBacklenBounds(t *testing.T) {
+	// Entry sizes on either side of each back length bound, followed by a short entry that a
+	// wrong back length would misplace
+	sizes := []int{127, 128, 16382, 16383, 16384, 2097150, 2097151, 2097152}
+
+	w := newRDB(11)
+	for _, size := range sizes {
+		w.WriteByte(20)
+		w.str(fmt.Sprintf("set:%d", size))
+		w.str(string(rawListpack(size, 5)))
+	}
+	w.WriteByte(20)
+	w.str("set:lzf")
+	w.lzf(rawListpack(127, 128, 5, 300))
+
+	_, entries := parseAll(t, w.end())
+
+	want := map[string]int64{"set:lzf": 4}
+	for _, size := range sizes {
+		want[fmt.Sprintf("set:%d", size)] = 2
+	}
+	for key, elements := range want {
+		e, ok := entries[key]
+		if !ok {
+			t.Fatalf("key %q not parsed", key)
+		}
+		if e.Encoding != "listpack" || e.Elements != elements {
+			t.Errorf("%s = %s %d elements, want listpack %d elements", key, e.Encoding, e.Elements, elements)
+		}
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	w := newRDB(11)
+	w.WriteByte(19)
+	w.str("events")
+	w.length(1)
+	w.str(string(make([]byte, 16)))
+	w.str(string(listpack("1", "0", "1", "f", "0", "v", "1")))
+	w.length(42)
+	for i := 0; i < 7; i++ {
+		w.length(1)
+	}
+	// One group with one pending entry and one consumer owning it
+	w.length(1)
+	w.str("workers")
+	w.length(1)
+	w.length(1)
+	w.length(1)
+	w.length(1)
+	w.Write(make([]byte, 16+8))
+	w.length(1)
+	w.length(1)
+	w.str("worker-1")
+	w.Write(make([]byte, 8))
+	w.length(1)
+	w.Write(make([]byte, 16))
+
+	w.WriteByte(0)
+	w.str("after")
+	w.str("ok")
+
+	_, entries := parseAll(t, w.end())
+
+	e := entries["events"]
+	if e.Type != "stream" || e.Elements != 42 {
+		t.Errorf("events = %s %d elements, want stream 42 elements", e.Type, e.Elements)
+	}
+	if _, ok := entries["after"]; !ok {
+		t.Errorf("key after the stream not parsed, stream was not fully consumed")
+	}
+}
+
+func TestParseRejectsUnsupportedVersion(t *testing.T) {
+	for _, version := range []int{6, 13} {
+		p := rdb.NewParser(bytes.NewReader(newRDB(version).end()))
+		if err := p.Parse(func(rdb.Entry) error { return nil }); err == nil {
+			t.Errorf("Parse() of version %d succeeded, want error", version)
+		}
+	}
+}